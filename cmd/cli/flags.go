@@ -0,0 +1,25 @@
+package main
+
+import "strings"
+
+// splitList splits a comma separated flag value, returning nil when it is
+// empty.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// stringsFlag is a flag that can be repeated, collecting every value.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
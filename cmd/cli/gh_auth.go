@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const defaultGitHubHost = "github.com"
+
+// ghToken looks up the token the gh CLI has stored for the host serving
+// endpoint. It reads gh's hosts.yml and falls back to running
+// `gh auth token`, which also covers tokens kept in the system keyring.
+func ghToken(endpoint string) string {
+	host := ghHost(endpoint)
+
+	if token := hostsFileToken(filepath.Join(ghConfigDir(), "hosts.yml"), host); token != "" {
+		return token
+	}
+
+	out, err := exec.Command("gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// ghHost returns the host gh knows an API endpoint by. api.github.com is
+// stored as github.com, and Enterprise hosts by their own name.
+func ghHost(endpoint string) string {
+	if endpoint == "" {
+		return defaultGitHubHost
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Hostname() == "" || u.Hostname() == "api.github.com" {
+		return defaultGitHubHost
+	}
+
+	return u.Hostname()
+}
+
+// ghConfigDir returns gh's configuration directory, following the same
+// lookup order as gh itself.
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "gh")
+}
+
+// hostsFileToken returns the oauth_token stored for host in gh's hosts.yml.
+// The file is a flat map of hosts to settings, so it is read line by line
+// rather than with a full YAML parser.
+func hostsFileToken(path, host string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inHost := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inHost = strings.TrimSuffix(trimmed, ":") == host
+			continue
+		}
+
+		if inHost && strings.HasPrefix(trimmed, "oauth_token:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "oauth_token:")), `"'`)
+		}
+	}
+
+	return ""
+}
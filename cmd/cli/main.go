@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"github.com/andrewsomething/github-repo-activity/exporter"
 	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
 )
 
@@ -14,13 +22,108 @@ var (
 	version string
 	commit  string
 
-	repos       = flag.String("repos", "", "A comma seperated list GitHub repositories (required)")
+	repos       = flag.String("repos", "", "A comma seperated list GitHub repositories, GitLab projects prefixed with \"gitlab:\", and Gitea or Forgejo repos prefixed with \"gitea:\" (required unless -org is set)")
+	orgs        = flag.String("org", "", "A comma separated list of GitHub orgs whose repos are all included")
 	days        = flag.Int("days", 14, "The number of days to cover in the report")
+	state       = flag.String("state", ghra.StateAll, "Only include items that are still \"open\", already \"closed\", or \"all\"")
+	milestone   = flag.String("milestone", "", "Only include items in this milestone, \"none\" for items without one, or \"*\" for items with any")
+	extraQuery  = flag.String("query", "", "Extra GitHub search qualifiers to add to every query, such as \"linked:pr\"")
+	mode        = flag.String("mode", ghra.ActivityCreated, "Select items by when they were \"created\" or last \"updated\" in the report window")
+	since       = flag.String("since", "", "Cover items created on or after this date, in YYYY-MM-DD form, instead of the past -days days")
+	until       = flag.String("until", "", "Cover items created on or before this date, in YYYY-MM-DD form (default now)")
+	timezone    = flag.String("timezone", "", "Cover whole calendar days in this timezone, e.g. America/New_York or Local (default exactly -days days up to now)")
 	endpoint    = flag.String("api-endpoint", "", "API endpoint for use with GitHub Enterprise")
+	uploadURL   = flag.String("api-upload-endpoint", "", "API upload endpoint for use with GitHub Enterprise (default -api-endpoint)")
 	token       = flag.String("token", os.Getenv("GITHUB_TOKEN"), "GitHub API token")
+	tokens      = flag.String("tokens", os.Getenv("GITHUB_TOKENS"), "A comma separated list of GitHub API tokens to use in turn")
+	noGHAuth    = flag.Bool("no-gh-auth", false, "Don't use gh CLI credentials when no token is supplied")
+	tokenFile   = flag.String("token-file", "", "Path to a file containing the GitHub API token")
+	appID       = flag.Int64("app-id", 0, "GitHub App ID, to authenticate as an app installation instead of with a token")
+	installID   = flag.Int64("installation-id", 0, "GitHub App installation ID")
+	appKey      = flag.String("app-private-key", "", "Path to the GitHub App private key")
+	batchSize   = flag.Int("batch-size", 0, "The number of repos to combine into each search query (default as many as fit)")
+	concurrency = flag.Int("concurrency", 3, "The number of search queries to run in parallel")
+	retryLimit  = flag.Bool("retry-rate-limit", true, "Wait for the GitHub rate limit to reset instead of failing")
+	maxWait     = flag.Duration("max-rate-limit-wait", 2*time.Minute, "The longest time to wait for the rate limit to reset")
+	maxRetries  = flag.Int("max-retries", 3, "The number of times to retry a request failing with a server error (negative disables)")
+	reqTimeout  = flag.Duration("request-timeout", 30*time.Second, "The longest time to wait for each GitHub API request (negative disables)")
+	proxy       = flag.String("proxy", "", "Send API requests through this proxy, such as http://proxy.example.com:3128 (default from HTTP_PROXY and HTTPS_PROXY)")
+	searchRate  = flag.Int("search-rate", 20, "The maximum number of search requests to make per minute (negative disables)")
+	useGraphQL  = flag.Bool("graphql", false, "Fetch issues and PRs with the GraphQL API")
+	separate    = flag.Bool("separate-searches", false, "Search for issues and PRs separately instead of with one search, doubling the searches made")
+	prDetails   = flag.Bool("pr-details", false, "Look up the size, requested reviewers, branches, and checks of each PR, at the cost of two extra API requests each without -graphql")
+	reviews     = flag.Bool("review-status", false, "Look up the review status of each PR, at the cost of an extra API request each without -graphql")
+	unreviewed  = flag.Bool("unreviewed", false, "Only include PRs without any reviews")
+	response    = flag.Bool("first-response", false, "Look up when each issue was first responded to, at the cost of an extra API request each")
+	unanswered  = flag.Bool("unanswered-only", false, "Only include issues nobody but their author has commented on")
+	responseSLA = flag.Duration("response-sla", 0, "Flag open issues without a response for longer than this, such as 48h")
+	baseBranch  = flag.String("base", "", "Only include PRs targeting this branch")
+	failing     = flag.Bool("failing-checks", false, "Only include PRs with failing checks")
+	closed      = flag.Bool("closed", false, "Include the issues closed during the report window, at the cost of an extra search")
+	notPlanned  = flag.Bool("exclude-not-planned", false, "Leave the issues closed as not planned out of the -closed section")
+	merged      = flag.Bool("merged", false, "Include the PRs merged during the report window, at the cost of an extra search")
+	sortBy      = flag.String("sort", ghra.SortByCreated, "Order each repo's items by \"created\", \"updated\", \"comments\", \"reactions\", or \"number\"")
+	sortOrder   = flag.String("order", ghra.SortDesc, "Sort items in \"desc\" or \"asc\" order")
+	maxPerRepo  = flag.Int("max-items-per-repo", 0, "Stop paging through a repo's issues and PRs after this many of each (0 for no limit)")
+	maxItems    = flag.Int("max-items", 0, "Stop paging through issues and PRs after this many across all repos (0 for no limit)")
+	projOwner   = flag.String("project-owner", "", "The org or user owning the -project board")
+	project     = flag.Int("project", 0, "Show each item's status on this Projects v2 board of -project-owner, with a token that has the read:project scope")
+	projField   = flag.String("project-field", "Status", "The single select field of the -project board holding the status")
+	releases    = flag.Bool("releases", false, "Include the releases published during the report window, at the cost of an extra API request per repo")
+	tags        = flag.Bool("tags", false, "Include tags without a release along with releases, at the cost of an extra API request per tag")
+	repoMeta    = flag.Bool("repo-meta", false, "Print each repo's description, stars, forks, and open issue count, at the cost of an extra API request per repo")
+	alerts      = flag.Bool("security-alerts", false, "Count each repo's open Dependabot alerts by severity, with a token that has the security_events scope")
+	commits     = flag.Bool("commits", false, "Count the commits to each repo's default branch during the report window, at the cost of an extra API request per 100 commits")
+	commitPages = flag.Int("max-commit-pages", 10, "The most pages of 100 commits to count per repo")
+	ciStats     = flag.Bool("ci", false, "Count the workflow runs and failures on each repo's default branch during the report window, at the cost of an extra API request per 100 runs")
+	runPages    = flag.Int("max-run-pages", 5, "The most pages of 100 workflow runs to count per repo")
+	reviewers   = flag.Bool("reviewer-stats", false, "Count the reviews each reviewer submitted during the report window, at the cost of an extra GraphQL search")
+	discussions = flag.Bool("discussions", false, "Include the discussions created during the report window, at the cost of an extra GraphQL request per repo")
+	compare     = flag.Bool("compare", false, "Compare the counts with the previous period of the same length, doubling the searches made")
+	staleDays   = flag.Int("stale-days", 0, "Include the open issues and PRs not updated in this many days, at the cost of an extra search")
+	approved    = flag.Bool("approved-unmerged", false, "Include the open PRs that have been approved, at the cost of an extra search and an extra API request per PR")
+	needsReview = flag.Bool("needs-review", false, "Only print the open, non-draft PRs without any reviews, as a list to paste into chat")
+	reviewQueue = flag.String("review-requested", "", "List the open PRs, however old, whose review is requested from this user or org/team, at the cost of an extra search")
+	triage      = flag.Bool("triage", false, "List the open new issues missing a label or an assignee")
+	excludeBots = flag.Bool("exclude-bots", false, "Leave out items opened by dependabot, renovate, github-actions, and other bots")
+	verifyRepos = flag.Bool("verify-repos", false, "Check that every repo in -repos exists before building the report")
+	archived    = flag.Bool("include-archived", false, "Let repo patterns in -repos match archived repos")
+	outsideOrg  = flag.String("exclude-org-members", "", "Leave out items opened by members of this org, to report only outside contributions")
+	involves    = flag.String("involves", "", "Only include items this user opened, is assigned to, was mentioned in, or commented on, noting how they're involved")
+	summary     = flag.Bool("summary", false, "Print the top and new contributors of the period before the repos")
+	anonymize   = flag.Bool("anonymize", false, "Replace every username with a pseudonym and leave out profile links, to share the report outside the team")
+	anonSalt    = flag.String("anonymize-salt", os.Getenv("ANONYMIZE_SALT"), "Salt for the -anonymize pseudonyms, to keep them the same across reports (default random)")
+	showBody    = flag.Bool("show-body", false, "Include an excerpt of each issue and PR body")
+	verbose     = flag.Bool("verbose", false, "Log the queries and HTTP requests made and their results to stderr")
 	versionFlag = flag.Bool("version", false, "Print version")
+
+	saveSnapshot = flag.String("save-snapshot", "", "Also save the report as a timestamped JSON snapshot in this directory")
+	fromSnapshot = flag.String("from-snapshot", "", "Print the report saved in this snapshot file instead of fetching one")
+	diffFrom     = flag.String("diff", "", "Print only what changed since the report saved in this snapshot file")
+	sqlitePath   = flag.String("sqlite", "", "Also append the report to the SQLite database at this path, creating it if needed")
+
+	gitlabURL   = flag.String("gitlab-api-endpoint", "", "API endpoint for the gitlab: projects in -repos, such as https://gitlab.example.com/api/v4/ (default gitlab.com)")
+	gitlabToken = flag.String("gitlab-token", os.Getenv("GITLAB_TOKEN"), "GitLab API token with the read_api scope")
+	giteaURL    = flag.String("gitea-api-endpoint", "", "API endpoint for the gitea: repos in -repos, such as https://git.example.com/api/v1/")
+	giteaToken  = flag.String("gitea-token", os.Getenv("GITEA_TOKEN"), "Gitea or Forgejo API token")
+
+	labels        stringsFlag
+	excludeLabels stringsFlag
+	authors       stringsFlag
+	excludeAuthor stringsFlag
+	ignoreItems   stringsFlag
+	excludeRepos  stringsFlag
 )
 
+func init() {
+	flag.Var(&labels, "label", "Only include items with this label; repeat to include items with any of several labels")
+	flag.Var(&excludeLabels, "exclude-label", "Leave out items with this label; may be repeated")
+	flag.Var(&authors, "author", "Only include items opened by this user; repeat to include several users")
+	flag.Var(&excludeAuthor, "exclude-author", "Leave out items opened by this user; may be repeated")
+	flag.Var(&ignoreItems, "ignore", "Leave out this item, such as owner/repo#123; may be repeated")
+	flag.Var(&excludeRepos, "exclude-repo", "Leave out repos matching this pattern, such as owner/*-archive; may be repeated")
+}
+
 func main() {
 	flag.Parse()
 
@@ -32,47 +135,677 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *repos == "" {
-		fmt.Println("Must set at least one repo...")
+	if *fromSnapshot != "" {
+		report, err := ghra.LoadSnapshot(*fromSnapshot)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		if *diffFrom != "" {
+			printDiffFrom(*diffFrom, report)
+			return
+		}
+		printReport(report, report.Options.GitHubRepoActivityOptions())
+		return
+	}
+
+	if *repos == "" && *orgs == "" {
+		fmt.Println("Must set at least one repo or org...")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	split := ghra.SplitRepos(splitList(*repos))
+	useGitHub := len(split.GitHub) > 0 || *orgs != ""
+
+	if useGitHub && *token == "" && *tokens == "" && *tokenFile == "" && *appID == 0 && !*noGHAuth {
+		*token = ghToken(*endpoint)
+		if *token == "" {
+			fmt.Fprintln(os.Stderr, "Warning: no GitHub token supplied or found in gh CLI config, making unauthenticated requests.")
+		}
+	}
+
+	var location *time.Location
+	if *timezone != "" {
+		var err error
+		location, err = time.LoadLocation(*timezone)
+		if err != nil {
+			fmt.Printf("Error: invalid -timezone: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *mode != ghra.ActivityCreated && *mode != ghra.ActivityUpdated {
+		fmt.Printf("Error: invalid -mode %q, must be %q or %q\n", *mode, ghra.ActivityCreated, ghra.ActivityUpdated)
+		os.Exit(1)
+	}
+
+	dateLocation := location
+	if dateLocation == nil {
+		dateLocation = time.UTC
+	}
+	sinceDate, err := parseDate(*since, dateLocation, false)
+	if err != nil {
+		fmt.Printf("Error: invalid -since: %s\n", err)
+		os.Exit(1)
+	}
+	untilDate, err := parseDate(*until, dateLocation, true)
+	if err != nil {
+		fmt.Printf("Error: invalid -until: %s\n", err)
+		os.Exit(1)
+	}
+
+	var tokenList []string
+	if *tokens != "" {
+		tokenList = strings.Split(*tokens, ",")
+	}
+
 	options := &ghra.GitHubRepoActivityOptions{
-		Repos:       strings.Split(*repos, ","),
-		DaysOld:     *days,
-		APIEndpoint: *endpoint,
-		Token:       *token,
+		Repos:             split.GitHub,
+		Orgs:              splitList(*orgs),
+		ExcludeRepos:      excludeRepos,
+		IncludeArchived:   *archived,
+		VerifyRepos:       *verifyRepos,
+		DaysOld:           *days,
+		ActivityMode:      *mode,
+		State:             *state,
+		Milestone:         *milestone,
+		ExtraQuery:        *extraQuery,
+		IncludeClosed:     *closed,
+		ExcludeNotPlanned: *notPlanned,
+		IncludeMerged:     *merged,
+		StaleDays:         *staleDays,
+		SortBy:            *sortBy,
+		SortOrder:         *sortOrder,
+		MaxItemsPerRepo:   *maxPerRepo,
+		MaxItems:          *maxItems,
+		Labels:            labels,
+		ExcludeLabels:     excludeLabels,
+		Authors:           authors,
+		ExcludeAuthors:    excludeAuthor,
+		InvolvesUser:      *involves,
+		ExcludeBots:       *excludeBots,
+		ExcludeOrgMembers: *outsideOrg,
+		IgnoreItems:       ignoreItems,
+		TriageUnlabeled:   *triage,
+		TriageUnassigned:  *triage,
+		Since:             sinceDate,
+		Until:             untilDate,
+		Timezone:          location,
+		BatchSize:         *batchSize,
+		Concurrency:       *concurrency,
+		APIEndpoint:       *endpoint,
+		APIUploadEndpoint: *uploadURL,
+		Token:             *token,
+		Tokens:            tokenList,
+		TokenFile:         *tokenFile,
+		AppID:             *appID,
+		InstallationID:    *installID,
+		AppPrivateKeyPath: *appKey,
+
+		RetryOnRateLimit: *retryLimit,
+		MaxRateLimitWait: *maxWait,
+		MaxRetries:       *maxRetries,
+		RequestTimeout:   *reqTimeout,
+		ProxyURL:         *proxy,
+		UseGraphQL:       *useGraphQL,
+		SeparateSearches: *separate,
+		AnonymizeAuthors: *anonymize,
+		AnonymizeSalt:    *anonSalt,
+		IncludePRDetails: *prDetails,
+		BaseBranch:       *baseBranch,
+
+		IncludeReviewStatus: *reviews,
+		OnlyUnreviewed:      *unreviewed,
+		OnlyFailingChecks:   *failing,
+
+		IncludeFirstResponse: *response,
+		IncludeReviewerStats: *reviewers,
+		OnlyUnanswered:       *unanswered,
+		ResponseSLA:          *responseSLA,
+
+		CompareWithPrevious: *compare,
+		IncludeReleases:     *releases,
+		IncludeTags:         *tags,
+		IncludeRepoMeta:     *repoMeta,
+		IncludeCommits:      *commits,
+		MaxCommitPages:      *commitPages,
+		IncludeCIStats:      *ciStats,
+		IncludeDiscussions:  *discussions,
+
+		IncludeSecurityAlerts: *alerts,
+		MaxWorkflowRunPages:   *runPages,
+
+		ProjectOwner:       *projOwner,
+		ProjectNumber:      *project,
+		ProjectStatusField: *projField,
+
+		IncludeApprovedUnmerged: *approved,
+		IncludeNeedsReview:      *needsReview,
+		ReviewRequested:         *reviewQueue,
+
+		SearchRequestsPerMinute: *searchRate,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		cancel()
+	}()
+
+	if *verbose {
+		logger := log.New()
+		logger.SetLevel(log.DebugLevel)
+		options.Log = logger
+		options.DebugHTTP = true
+	}
+
+	// The GitHub, GitLab, and Gitea reports share a salt so that a user
+	// keeps their pseudonym across them.
+	if options.AnonymizeAuthors && options.AnonymizeSalt == "" {
+		salt, err := ghra.NewAnonymizeSalt()
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		options.AnonymizeSalt = salt
+	}
+
+	var reports []*ghra.ActivityReport
+	if useGitHub {
+		service, err := ghra.NewGitHubRepoActivityService(options)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		if err := service.Preflight(ctx); err != nil {
+			fmt.Printf("Error: %s\n", err)
+			printHint(err)
+			os.Exit(1)
+		}
+
+		report, err := service.BuildReportContext(ctx)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			printHint(err)
+			os.Exit(1)
+		}
+		reports = append(reports, report)
 	}
 
-	service := ghra.NewGitHubRepoActivityService(options)
-	report, err := service.BuildReport()
+	if len(split.GitLab) > 0 {
+		gitlabOptions := options.GitLabOptions(split.GitLab)
+		gitlabOptions.APIEndpoint = *gitlabURL
+		gitlabOptions.Token = *gitlabToken
+
+		service, err := ghra.NewGitLabRepoActivityService(gitlabOptions)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		report, err := service.BuildReportContext(ctx)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		reports = append(reports, report)
+	}
+
+	if len(split.Gitea) > 0 {
+		giteaOptions := options.GiteaOptions(split.Gitea)
+		giteaOptions.APIEndpoint = *giteaURL
+		giteaOptions.Token = *giteaToken
+
+		service, err := ghra.NewGiteaRepoActivityService(giteaOptions)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		report, err := service.BuildReportContext(ctx)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		reports = append(reports, report)
+	}
+
+	report := ghra.MergeReports(reports...)
+	if len(reports) == 1 {
+		report = reports[0]
+	}
+
+	if *saveSnapshot != "" {
+		path, err := report.SaveSnapshot(*saveSnapshot)
+		if err != nil {
+			fmt.Printf("Error: saving snapshot: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Saved snapshot to %s\n", path)
+	}
+	if *sqlitePath != "" {
+		if err := exporter.ExportSQLite(report, *sqlitePath); err != nil {
+			fmt.Printf("Error: exporting to SQLite: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *diffFrom != "" {
+		printDiffFrom(*diffFrom, report)
+		return
+	}
+	printReport(report, options)
+}
+
+// printErrors prints the repos that couldn't be fetched to stderr.
+func printErrors(report *ghra.ActivityReport) {
+	failed := make([]string, 0, len(report.Errors))
+	for repo := range report.Errors {
+		failed = append(failed, repo)
+	}
+	sort.Strings(failed)
+	for _, repo := range failed {
+		fmt.Fprintf(os.Stderr, "Error fetching %s: %s\n", repo, report.Errors[repo])
+		printHint(report.Errors[repo])
+	}
+}
+
+// printDiffFrom prints what changed in report since the snapshot at path.
+func printDiffFrom(path string, report *ghra.ActivityReport) {
+	old, err := ghra.LoadSnapshot(path)
 	if err != nil {
 		fmt.Printf("Error: %s\n", err)
 		os.Exit(1)
 	}
+	printErrors(report)
 
 	w := new(tabwriter.Writer)
 	w.Init(os.Stdout, 8, 8, 0, '\t', 0)
+	if !old.GeneratedAt.IsZero() {
+		fmt.Fprintf(w, "Changes since %s\n\n", old.GeneratedAt.Local().Format("2006-01-02 15:04"))
+	}
+	printDiff(w, ghra.DiffReports(old, report))
+	w.Flush()
+}
+
+// printReport prints the report as tables, with the sections enabled in the
+// options it was built with.
+func printReport(report *ghra.ActivityReport, options *ghra.GitHubRepoActivityOptions) {
+	printErrors(report)
+
+	if options.IncludeNeedsReview {
+		printNeedsReview(os.Stdout, report)
+		return
+	}
+
+	if options.ActivityMode == ghra.ActivityUpdated {
+		tableColumns = append([]column{{"New", func(i ghra.IssueInfo) string {
+			if i.New {
+				return "yes"
+			}
+			return ""
+		}}}, tableColumns...)
+	}
+	if options.ProjectOwner != "" && options.ProjectNumber != 0 {
+		tableColumns = append(tableColumns, column{"Project", projectStatus})
+	}
+	if options.InvolvesUser != "" {
+		tableColumns = append(tableColumns, column{"Involvement", involvement})
+	}
+	if *showBody {
+		tableColumns = append(tableColumns, column{"Body", func(i ghra.IssueInfo) string { return i.BodyExcerpt }})
+	}
+	issueColumns := tableColumns
+	if options.IncludeFirstResponse || options.OnlyUnanswered {
+		issueColumns = append(issueColumns[:len(issueColumns):len(issueColumns)], column{"First response", firstResponse})
+	}
+	prColumns := tableColumns
+	if options.IncludePRDetails || options.OnlyFailingChecks {
+		prColumns = append(prColumns[:len(prColumns):len(prColumns)], prDetailColumns...)
+	}
+	if options.IncludeReviewStatus || options.OnlyUnreviewed {
+		prColumns = append(prColumns[:len(prColumns):len(prColumns)], reviewColumn)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 8, 8, 0, '\t', 0)
+
+	if options.InvolvesUser != "" {
+		fmt.Fprintf(w, "Activity involving %s\n", options.InvolvesUser)
+	}
+	if options.Milestone != "" {
+		fmt.Fprintf(w, "Milestone: %s\n", milestoneName(options.Milestone))
+	}
+	if options.ExcludeOrgMembers != "" {
+		fmt.Fprintf(w, "%d items from %s members hidden\n", report.HiddenOrgMembers, options.ExcludeOrgMembers)
+	}
+
+	if report.PreviousTotals != nil {
+		fmt.Fprintf(w, "%s\n", comparison(report.TotalIssues, report.TotalPullRequests, *report.PreviousTotals))
+	}
+
+	if *summary {
+		fmt.Fprintf(w, "\n## Top contributors %s\n\n", period(options))
+		printAuthorStats(w, report.AuthorStats)
+
+		if options.IncludeReviewerStats {
+			fmt.Fprintf(w, "\n## Top reviewers %s\n\n", period(options))
+			printReviewerStats(w, report.ReviewerStats)
+		}
+
+		if len(report.NewContributors) > 0 {
+			fmt.Fprintf(w, "\n## New contributors\n\n")
+			printColumns(w, newContributorColumns, report.NewContributors)
+		}
+	}
 
-	for repo, activity := range report.RepoActivityReports {
+	if options.ReviewRequested != "" {
+		fmt.Fprintf(w, "\n## PRs awaiting review from %s, oldest first\n\n", options.ReviewRequested)
+		printColumns(w, reviewQueueColumns, report.ReviewQueue)
+	}
+
+	for _, repo := range report.Repos() {
+		activity := report.RepoActivityReports[repo]
 		fmt.Fprintf(w, "\n## Repo: %s\n\n", repo)
-		fmt.Fprintf(w, "### New issues opened in the past %d days\n\n", options.DaysOld)
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", "Number", "Status", "Age", "Author", "Title", "URL")
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", "----", "----", "----", "----", "----", "----")
-		for _, i := range activity.Issues {
-			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", *i.Number, *i.Status, i.Age, *i.Author.DisplayName, *i.Title, *i.URL)
+		if activity.Meta != nil {
+			fmt.Fprintf(w, "%s\n\n", metaSummary(*activity.Meta))
+		}
+		if a := activity.SecurityAlerts; a != nil {
+			fmt.Fprintf(w, "Open Dependabot alerts: %d critical, %d high, %d medium, %d low\n\n", a.Critical, a.High, a.Medium, a.Low)
+		}
+		if activity.Truncated {
+			fmt.Fprintf(w, "Warning: GitHub's search result limit was reached, some items are missing.\n\n")
+		}
+		if activity.PreviousTotals != nil {
+			fmt.Fprintf(w, "%s\n\n", comparison(len(activity.Issues), len(activity.PullRequests), *activity.PreviousTotals))
+		}
+		if activity.CommitStats != nil {
+			fmt.Fprintf(w, "%s\n\n", commitSummary(*activity.CommitStats))
+		}
+		if activity.CIStats != nil {
+			fmt.Fprintf(w, "%s\n\n", ciSummary(*activity.CIStats))
+		}
+		if quiet(activity) {
+			fmt.Fprintf(w, "No %s %s\n", noActivity(options), period(options))
+			continue
+		}
+		if line := statsLine(activity.Stats, options.IncludeClosed, options.IncludeMerged); line != "" {
+			fmt.Fprintf(w, "%s\n\n", line)
 		}
+		fmt.Fprintf(w, "### %s %s\n\n", heading("issues", "Issues", options), period(options))
+		if activity.IssuesLimit != nil {
+			fmt.Fprintf(w, "%s\n\n", limitSummary(*activity.IssuesLimit, "issues"))
+		}
+		printColumns(w, issueColumns, activity.Issues)
 		fmt.Fprintf(w, "\n")
 
-		fmt.Fprintf(w, "### New PRs opened in the past %d days\n\n", options.DaysOld)
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", "Number", "Status", "Age", "Author", "Title", "URL")
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", "----", "----", "----", "----", "----", "----")
-		for _, p := range activity.PullRequests {
-			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", *p.Number, *p.Status, p.Age, *p.Author.DisplayName, *p.Title, *p.URL)
+		if options.TriageUnlabeled || options.TriageUnassigned {
+			fmt.Fprintf(w, "### Triage gaps\n\n")
+			printTriageGaps(w, activity.TriageGaps)
+			fmt.Fprintf(w, "\n")
+		}
+
+		fmt.Fprintf(w, "### %s %s\n\n", heading("PRs", "PRs", options), period(options))
+		if activity.PullRequestsLimit != nil {
+			fmt.Fprintf(w, "%s\n\n", limitSummary(*activity.PullRequestsLimit, "PRs"))
 		}
+		printColumns(w, prColumns, activity.PullRequests)
 		fmt.Fprintf(w, "\n")
+
+		if options.IncludeClosed {
+			fmt.Fprintf(w, "### Issues closed %s, with the time each was open\n\n", period(options))
+			printTable(w, activity.ClosedIssues)
+			fmt.Fprintf(w, "\n")
+		}
+
+		if options.IncludeMerged {
+			fmt.Fprintf(w, "### PRs merged %s, with the time each was open\n\n", period(options))
+			printColumns(w, append(prColumns[:len(prColumns):len(prColumns)], column{"Merged by", mergedBy}), activity.MergedPullRequests)
+			fmt.Fprintf(w, "\n")
+		}
+
+		if options.IncludeDiscussions {
+			fmt.Fprintf(w, "### Discussions created %s\n\n", period(options))
+			printColumns(w, discussionColumns, activity.Discussions)
+			fmt.Fprintf(w, "\n")
+		}
+
+		if len(activity.Releases) > 0 {
+			fmt.Fprintf(w, "### Releases published %s\n\n", period(options))
+			printReleases(w, activity.Releases)
+			fmt.Fprintf(w, "\n")
+		}
+
+		if options.IncludeApprovedUnmerged {
+			fmt.Fprintf(w, "### Approved PRs not yet merged, with the time since their approval\n\n")
+			printColumns(w, prColumns, activity.ApprovedUnmerged)
+			fmt.Fprintf(w, "\n")
+		}
+
+		if options.StaleDays > 0 {
+			fmt.Fprintf(w, "### Stale issues and PRs not updated in %d days, with the time since their last update\n\n", options.StaleDays)
+			printTable(w, activity.Stale)
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	if *verbose && report.RateLimit != nil {
+		fmt.Fprintf(w, "Rate limit: %d of %d remaining, resets at %s\n", report.RateLimit.Remaining, report.RateLimit.Limit, report.RateLimit.Reset.Format(time.Kitchen))
 	}
 
 	w.Flush()
 }
+
+// printHint suggests a fix for errors with a known cause.
+func printHint(err error) {
+	var (
+		notFound *ghra.ErrRepoNotFound
+		rateErr  *ghra.ErrRateLimited
+	)
+	switch {
+	case errors.Is(err, ghra.ErrUnauthorized):
+		fmt.Fprintln(os.Stderr, "Hint: check that -token or GITHUB_TOKEN holds a valid, unexpired token.")
+	case errors.As(err, &notFound):
+		fmt.Fprintf(os.Stderr, "Hint: check the spelling of %s and that the token can access it.\n", notFound.Repo)
+	case errors.As(err, &rateErr):
+		fmt.Fprintf(os.Stderr, "Hint: the rate limit resets at %s; try again then or use a token.\n", rateErr.ResetAt.Format(time.Kitchen))
+	case errors.Is(err, ghra.ErrInvalidWindow):
+		fmt.Fprintln(os.Stderr, "Hint: -until must not be before -since.")
+	}
+}
+
+// parseDate parses a YYYY-MM-DD date in loc. With endOfDay it returns the
+// last second of the day, so the whole day is included in the window. An
+// empty value gives the zero time.
+func parseDate(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	return t, nil
+}
+
+// heading names a section of items according to the activity mode.
+func heading(items, capitalized string, options *ghra.GitHubRepoActivityOptions) string {
+	h := "New " + items + " opened"
+	if options.ActivityMode == ghra.ActivityUpdated {
+		h = capitalized + " updated"
+	}
+
+	return h + stateNote(options.State)
+}
+
+// noActivity describes what a repo without activity is missing, for a
+// one-line summary in place of its tables.
+func noActivity(options *ghra.GitHubRepoActivityOptions) string {
+	if options.ActivityMode == ghra.ActivityUpdated {
+		return "issues or PRs updated" + stateNote(options.State)
+	}
+
+	return "new issues or PRs" + stateNote(options.State)
+}
+
+// comparison describes the issue and PR counts against the previous
+// period, such as "12 issues (up from 7 last period)".
+func comparison(issues, prs int, previous ghra.Totals) string {
+	return fmt.Sprintf("%d issues (%s), %d PRs (%s)", issues, change(issues, previous.Issues), prs, change(prs, previous.PullRequests))
+}
+
+// ciSummary describes a repo's workflow runs, naming the workflows that
+// failed.
+func ciSummary(s ghra.CIStats) string {
+	runs := fmt.Sprintf("%d", s.Runs)
+	if s.Truncated {
+		runs = "at least " + runs
+	}
+	line := fmt.Sprintf("CI: %d%% green (%d failures / %s runs)", s.GreenPercent(), s.Failures, runs)
+
+	var failing []string
+	for _, wf := range s.Workflows {
+		if wf.Failures > 0 {
+			failing = append(failing, fmt.Sprintf("%s (%d/%d)", wf.Name, wf.Failures, wf.Runs))
+		}
+	}
+	if len(failing) > 0 {
+		line += ": " + strings.Join(failing, ", ")
+	}
+
+	return line
+}
+
+// metaSummary describes a repo as a whole in one line.
+func metaSummary(m ghra.RepoMeta) string {
+	line := fmt.Sprintf("%d stars, %d forks, %d open issues and PRs", m.Stars, m.Forks, m.OpenIssues)
+	if m.Archived {
+		line += ", archived"
+	}
+	if m.Description != "" {
+		line = m.Description + " (" + line + ")"
+	}
+
+	return line
+}
+
+// limitSummary describes how many of a repo's items -max-items-per-repo or
+// -max-items left in the report.
+func limitSummary(l ghra.ItemLimit, items string) string {
+	total := fmt.Sprintf("%d", l.Total)
+	if l.AtLeast {
+		total = "at least " + total
+	}
+
+	return fmt.Sprintf("Showing the first %d of %s %s", l.Shown, total, items)
+}
+
+// change describes how current differs from previous.
+func change(current, previous int) string {
+	switch {
+	case current > previous:
+		return fmt.Sprintf("up from %d last period", previous)
+	case current < previous:
+		return fmt.Sprintf("down from %d last period", previous)
+	}
+
+	return "same as last period"
+}
+
+// statsLine summarizes a repo's time to close and time to merge, for the
+// sections that are enabled.
+func statsLine(stats ghra.Stats, closed, merged bool) string {
+	var parts []string
+	if closed {
+		parts = append(parts, "Time to close: "+timeStats(stats.TimeToClose, "issues closed"))
+	}
+	if merged {
+		parts = append(parts, "Time to merge: "+timeStats(stats.TimeToMerge, "PRs merged"))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// commitSummary describes a repo's commit count and top committers.
+func commitSummary(s ghra.CommitStats) string {
+	count := fmt.Sprintf("%d", s.Commits)
+	if s.Truncated {
+		count = "At least " + count
+	}
+	line := fmt.Sprintf("%s commits by %d authors", count, s.Authors)
+
+	top := make([]string, 0, len(s.TopCommitters))
+	for _, c := range s.TopCommitters {
+		top = append(top, fmt.Sprintf("%s (%d)", c.Author.DisplayName, c.Commits))
+	}
+	if len(top) > 0 {
+		line += ": " + strings.Join(top, ", ")
+	}
+
+	return line
+}
+
+// timeStats describes the median and p90 of s, or that there were no items.
+func timeStats(s ghra.TimeStats, items string) string {
+	if s.Count == 0 {
+		return "no " + items
+	}
+
+	return fmt.Sprintf("median %s, p90 %s across %d %s", s.MedianAge, s.P90Age, s.Count, items)
+}
+
+// quiet reports whether a repo has nothing to list in any section.
+func quiet(activity *ghra.RepoActivityReport) bool {
+	return len(activity.Issues) == 0 && len(activity.PullRequests) == 0 &&
+		len(activity.ClosedIssues) == 0 && len(activity.MergedPullRequests) == 0 &&
+		len(activity.Stale) == 0 && len(activity.Releases) == 0 &&
+		len(activity.Discussions) == 0 && len(activity.ApprovedUnmerged) == 0
+}
+
+// milestoneName describes the Milestone filter.
+func milestoneName(milestone string) string {
+	switch milestone {
+	case ghra.MilestoneNone:
+		return "no milestone"
+	case ghra.MilestoneAny:
+		return "any milestone"
+	}
+
+	return milestone
+}
+
+// stateNote describes the State filter for headings.
+func stateNote(state string) string {
+	switch state {
+	case ghra.StateOpen:
+		return " and still open"
+	case ghra.StateClosed:
+		return " and since closed"
+	}
+
+	return ""
+}
+
+// period describes the report window for section headings.
+func period(options *ghra.GitHubRepoActivityOptions) string {
+	switch {
+	case !options.Since.IsZero() && !options.Until.IsZero():
+		return fmt.Sprintf("between %s and %s", options.Since.Format("2006-01-02"), options.Until.Format("2006-01-02"))
+	case !options.Since.IsZero():
+		return fmt.Sprintf("since %s", options.Since.Format("2006-01-02"))
+	case !options.Until.IsZero():
+		return fmt.Sprintf("in the %d days up to %s", options.DaysOld, options.Until.Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("in the past %d days", options.DaysOld)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hako/durafmt"
+
+	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
+)
+
+// column is a single column of the issue and PR tables.
+type column struct {
+	header string
+	value  func(i ghra.IssueInfo) string
+}
+
+// tableColumns are the columns printed for every issue and PR.
+var tableColumns = []column{
+	{"Number", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Number) }},
+	{"Status", func(i ghra.IssueInfo) string { return i.Status }},
+	{"Age", func(i ghra.IssueInfo) string { return i.Age }},
+	{"Author", func(i ghra.IssueInfo) string { return i.Author.DisplayName }},
+	{"Association", func(i ghra.IssueInfo) string { return i.AuthorAssociation }},
+	{"Title", func(i ghra.IssueInfo) string { return i.Title }},
+	{"Labels", labelNames},
+	{"Assignees", func(i ghra.IssueInfo) string { return authorNames(i.Assignees) }},
+	{"Milestone", milestoneTitle},
+	{"Comments", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Comments) }},
+	{"+1", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Reactions.PlusOne) }},
+	{"Reactions", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Reactions.TotalCount) }},
+	{"Links", links},
+	{"URL", func(i ghra.IssueInfo) string { return i.URL }},
+}
+
+// prDetailColumns are the columns added to the PR tables with -pr-details
+// or -failing-checks.
+var prDetailColumns = []column{
+	{"Base", func(i ghra.IssueInfo) string { return i.BaseRef }},
+	{"Size", sizeSummary},
+	{"CI", func(i ghra.IssueInfo) string { return i.ChecksStatus }},
+	{"Reviewers", func(i ghra.IssueInfo) string { return authorNames(i.RequestedReviewers) }},
+}
+
+// reviewColumn is the column added to the PR tables with -review-status or
+// -unreviewed.
+var reviewColumn = column{"Review", func(i ghra.IssueInfo) string { return i.ReviewStatus }}
+
+// printTable writes items as a tab separated table for a tabwriter.
+func printTable(w io.Writer, items []ghra.IssueInfo) {
+	printColumns(w, tableColumns, items)
+}
+
+// printColumns writes items as a table of the given columns.
+func printColumns(w io.Writer, columns []column, items []ghra.IssueInfo) {
+	headers := make([]string, len(columns))
+	rules := make([]string, len(columns))
+	for c, col := range columns {
+		headers[c] = col.header
+		rules[c] = "----"
+	}
+	fmt.Fprintf(w, "%s\t\n", strings.Join(headers, "\t"))
+	fmt.Fprintf(w, "%s\t\n", strings.Join(rules, "\t"))
+
+	for _, i := range items {
+		cells := make([]string, len(columns))
+		for c, col := range columns {
+			cells[c] = col.value(i)
+		}
+		fmt.Fprintf(w, "%s\t\n", strings.Join(cells, "\t"))
+	}
+}
+
+// printTriageGaps writes issues missing a label or an assignee as a table
+// with a column naming what is missing.
+func printTriageGaps(w io.Writer, gaps []ghra.TriageGap) {
+	missing := make(map[int64]string, len(gaps))
+	items := make([]ghra.IssueInfo, 0, len(gaps))
+	for _, g := range gaps {
+		var m []string
+		if g.Unlabeled {
+			m = append(m, "label")
+		}
+		if g.Unassigned {
+			m = append(m, "assignee")
+		}
+		missing[g.ID] = strings.Join(m, ", ")
+		items = append(items, g.IssueInfo)
+	}
+
+	columns := append(tableColumns[:len(tableColumns):len(tableColumns)], column{"Missing", func(i ghra.IssueInfo) string {
+		return missing[i.ID]
+	}})
+	printColumns(w, columns, items)
+}
+
+// mergedBy names who merged a pull request, or "-" when it isn't known.
+func mergedBy(i ghra.IssueInfo) string {
+	if i.MergedBy == nil {
+		return "-"
+	}
+
+	return i.MergedBy.DisplayName
+}
+
+// labelNames joins an item's label names with commas.
+func labelNames(i ghra.IssueInfo) string {
+	names := make([]string, 0, len(i.Labels))
+	for _, l := range i.Labels {
+		names = append(names, l.Name)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// authorNames joins the logins of users with commas.
+func authorNames(users []ghra.IssueAuthor) string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.DisplayName)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// milestoneTitle returns the title of an item's milestone, or a dash when it
+// has none.
+func milestoneTitle(i ghra.IssueInfo) string {
+	if i.Milestone == nil {
+		return "-"
+	}
+
+	return i.Milestone.Title
+}
+
+// sizeSummary describes the size of a pull request, such as "+120 −45 (M)".
+func sizeSummary(i ghra.IssueInfo) string {
+	if i.Size == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("+%d −%d (%s)", i.Size.Additions, i.Size.Deletions, i.Size.Bucket)
+}
+
+// links summarizes the issues a pull request closes, or flags an issue that a
+// pull request in the report closes.
+func links(i ghra.IssueInfo) string {
+	if i.HasLinkedPR {
+		return "has PR"
+	}
+	if len(i.ClosesIssues) == 0 {
+		return ""
+	}
+
+	refs := make([]string, 0, len(i.ClosesIssues))
+	for _, ref := range i.ClosesIssues {
+		if ref.Repo == i.Repo {
+			refs = append(refs, fmt.Sprintf("#%d", ref.Number))
+		} else {
+			refs = append(refs, fmt.Sprintf("%s#%d", ref.Repo, ref.Number))
+		}
+	}
+
+	return "fixes " + strings.Join(refs, ",")
+}
+
+// projectStatus is the item's status on the -project board, or a dash for
+// items not on it.
+func projectStatus(i ghra.IssueInfo) string {
+	if i.ProjectStatus == "" {
+		return "—"
+	}
+
+	return i.ProjectStatus
+}
+
+// involvement is how the -involves user relates to the item, or a dash when
+// they're involved only in a way the item doesn't show, such as commenting.
+func involvement(i ghra.IssueInfo) string {
+	if len(i.Involvement) == 0 {
+		return "—"
+	}
+
+	return strings.Join(i.Involvement, ", ")
+}
+
+// firstResponse describes how long an issue waited for its first response.
+func firstResponse(i ghra.IssueInfo) string {
+	switch {
+	case i.FirstResponseLatency != nil:
+		return durafmt.Parse(*i.FirstResponseLatency).LimitFirstN(1).String()
+	case i.ResponseOverdue:
+		return "none, overdue"
+	}
+
+	return "none"
+}
+
+// newContributorColumns are the columns printed for the first issues and
+// PRs of new contributors.
+var newContributorColumns = []column{
+	{"Author", func(i ghra.IssueInfo) string { return i.Author.DisplayName }},
+	{"Repo", func(i ghra.IssueInfo) string { return i.Repo }},
+	{"Number", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Number) }},
+	{"Title", func(i ghra.IssueInfo) string { return i.Title }},
+	{"URL", func(i ghra.IssueInfo) string { return i.URL }},
+}
+
+// reviewQueueColumns are the columns printed for the PRs awaiting review
+// from the -review-requested user or team.
+var reviewQueueColumns = []column{
+	{"Repo", func(i ghra.IssueInfo) string { return i.Repo }},
+	{"Number", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Number) }},
+	{"Age", func(i ghra.IssueInfo) string { return i.Age }},
+	{"Author", func(i ghra.IssueInfo) string { return i.Author.DisplayName }},
+	{"Title", func(i ghra.IssueInfo) string { return i.Title }},
+	{"URL", func(i ghra.IssueInfo) string { return i.URL }},
+}
+
+// discussionColumns are the columns printed for discussions, whose only
+// label is their category.
+var discussionColumns = []column{
+	{"Number", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Number) }},
+	{"Status", func(i ghra.IssueInfo) string { return i.Status }},
+	{"Age", func(i ghra.IssueInfo) string { return i.Age }},
+	{"Author", func(i ghra.IssueInfo) string { return i.Author.DisplayName }},
+	{"Category", labelNames},
+	{"Title", func(i ghra.IssueInfo) string { return i.Title }},
+	{"Comments", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Comments) }},
+	{"+1", func(i ghra.IssueInfo) string { return strconv.Itoa(i.Reactions.PlusOne) }},
+	{"URL", func(i ghra.IssueInfo) string { return i.URL }},
+}
+
+// printNeedsReview writes the PRs waiting for a first review as a plain list
+// grouped by repo, for pasting into chat.
+func printNeedsReview(w io.Writer, report *ghra.ActivityReport) {
+	if report.TotalNeedsReview == 0 {
+		fmt.Fprintf(w, "No PRs waiting for review\n")
+		return
+	}
+
+	for _, repo := range report.Repos() {
+		prs := report.RepoActivityReports[repo].NeedsReview
+		if len(prs) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s\n", repo)
+		for _, pr := range prs {
+			fmt.Fprintf(w, "• #%d %s by %s, opened %s ago: %s\n", pr.Number, pr.Title, pr.Author.DisplayName, pr.Age, pr.URL)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
+
+// printReleases writes releases as a table.
+func printReleases(w io.Writer, releases []ghra.ReleaseInfo) {
+	fmt.Fprintf(w, "Tag\tName\tPublished\tAuthor\tPre-release\tURL\t\n")
+	fmt.Fprintf(w, "----\t----\t----\t----\t----\t----\t\n")
+	for _, r := range releases {
+		name := r.Name
+		if r.TagOnly {
+			name = "(tag only)"
+		}
+		pre := ""
+		if r.Prerelease {
+			pre = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", r.TagName, name, r.PublishedAt.Format("2006-01-02"), r.Author.DisplayName, pre, r.URL)
+	}
+}
+
+// maxContributors is how many authors the contributor summary lists.
+const maxContributors = 10
+
+// printReviewerStats writes the reviewers with the most reviews as a table.
+func printReviewerStats(w io.Writer, stats map[string]int) {
+	top := ghra.TopReviewers(stats)
+	if len(top) > maxContributors {
+		top = top[:maxContributors]
+	}
+
+	fmt.Fprintf(w, "Reviewer\tReviews\t\n")
+	fmt.Fprintf(w, "----\t----\t\n")
+	for _, r := range top {
+		fmt.Fprintf(w, "%s\t%d\t\n", r.Login, r.Reviews)
+	}
+}
+
+// printAuthorStats writes the most active authors as a table.
+func printAuthorStats(w io.Writer, stats []ghra.AuthorStats) {
+	if len(stats) > maxContributors {
+		stats = stats[:maxContributors]
+	}
+
+	fmt.Fprintf(w, "Author\tIssues\tPRs\t\n")
+	fmt.Fprintf(w, "----\t----\t----\t\n")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t\n", s.Author.DisplayName, s.Issues, s.PullRequests)
+	}
+}
+
+// printDiff writes the items added, removed, and changed since an earlier
+// report as a table per repo, marked with +, −, and ~. Status changes, such
+// as an issue being closed or a PR merged, are called out in the Change
+// column.
+func printDiff(w io.Writer, diff *ghra.ReportDiff) {
+	repos := diff.Repos()
+	if len(repos) == 0 {
+		fmt.Fprintf(w, "No changes\n")
+		return
+	}
+
+	for _, repo := range repos {
+		fmt.Fprintf(w, "## Repo: %s\n\n", repo)
+		fmt.Fprintf(w, "\tNumber\tStatus\tChange\tTitle\tURL\t\n")
+		fmt.Fprintf(w, "\t----\t----\t----\t----\t----\t\n")
+		for _, i := range diff.Added[repo] {
+			fmt.Fprintf(w, "+\t%d\t%s\tadded\t%s\t%s\t\n", i.Number, i.Status, i.Title, i.URL)
+		}
+		for _, i := range diff.Removed[repo] {
+			fmt.Fprintf(w, "−\t%d\t%s\tremoved\t%s\t%s\t\n", i.Number, i.Status, i.Title, i.URL)
+		}
+		for _, c := range diff.Changed[repo] {
+			var change []string
+			if c.StatusChanged() {
+				change = append(change, c.Transition())
+			}
+			for _, f := range c.Fields {
+				if f != "status" {
+					change = append(change, f)
+				}
+			}
+			fmt.Fprintf(w, "~\t%d\t%s\t%s\t%s\t%s\t\n", c.New.Number, c.New.Status, strings.Join(change, ","), c.New.Title, c.New.URL)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+}
@@ -14,25 +14,58 @@ import (
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
+	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
 	"github.com/andrewsomething/github-repo-activity/server"
 )
 
 func main() {
 	endpoint := os.Getenv("GITHUB_ENDPOINT")
 	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
+	tokenFile := os.Getenv("GITHUB_TOKEN_FILE")
+
+	var tokens []string
+	if v := os.Getenv("GITHUB_TOKENS"); v != "" {
+		tokens = strings.Split(v, ",")
+	}
+
+	appID, err := int64Env("GITHUB_APP_ID")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse GITHUB_APP_ID")
+	}
+
+	installationID, err := int64Env("GITHUB_APP_INSTALLATION_ID")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse GITHUB_APP_INSTALLATION_ID")
+	}
+
+	var repos, orgs []string
+	if v := os.Getenv("REPORT_REPOS"); v != "" {
+		repos = strings.Split(v, ",")
+	}
+	if v := os.Getenv("ORGS"); v != "" {
+		orgs = strings.Split(v, ",")
+	}
+	if len(repos) == 0 && len(orgs) == 0 {
+		log.Fatal("Must set at least one repo or org...")
+	}
+
+	// Only GitLab and Gitea repos can be reported on without a GitHub token.
+	useGitHub := len(ghra.SplitRepos(repos).GitHub) > 0 || len(orgs) > 0
+	if useGitHub && token == "" && len(tokens) == 0 && tokenFile == "" && appID == 0 {
 		log.Fatal("GitHub API token not configured")
 	}
 
-	repos := os.Getenv("REPORT_REPOS")
-	if repos == "" {
-		log.Fatal("Must set at least one repo...")
+	var excludeRepos []string
+	if v := os.Getenv("EXCLUDE_REPOS"); v != "" {
+		excludeRepos = strings.Split(v, ",")
+	}
+
+	var ignoreItems []string
+	if v := os.Getenv("IGNORE_ITEMS"); v != "" {
+		ignoreItems = strings.Split(v, ",")
 	}
 
-	var (
-		err     error
-		daysOld int
-	)
+	var daysOld int
 	days := os.Getenv("REPORT_DAYS")
 	if days != "" {
 		daysOld, err = strconv.Atoi(days)
@@ -41,17 +74,178 @@ func main() {
 		}
 	}
 
+	batchSize, err := intEnv("REPORT_BATCH_SIZE")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse REPORT_BATCH_SIZE")
+	}
+
+	concurrency, err := intEnv("REPORT_CONCURRENCY")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse REPORT_CONCURRENCY")
+	}
+
+	var cacheTTL time.Duration
+	if ttl := os.Getenv("REPORT_CACHE_TTL"); ttl != "" {
+		cacheTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			log.WithError(err).Fatal("can not parse REPORT_CACHE_TTL")
+		}
+	}
+
+	var cache ghra.Cache
+	switch backend := os.Getenv("CACHE_BACKEND"); backend {
+	case "", "memory":
+		cache = ghra.NewMemoryCache(0)
+	case "redis":
+		redis := ghra.NewRedisCache(ghra.RedisOptions{
+			Addr:      os.Getenv("REDIS_ADDR"),
+			Password:  os.Getenv("REDIS_PASSWORD"),
+			KeyPrefix: os.Getenv("REDIS_KEY_PREFIX"),
+			Log:       log.StandardLogger(),
+		})
+		if err := redis.Ping(); err != nil {
+			log.WithError(err).Fatal("can not connect to Redis")
+		}
+		cache = redis
+	default:
+		log.Fatalf("CACHE_BACKEND %q must be memory or redis", backend)
+	}
+
+	var responseSLA time.Duration
+	if sla := os.Getenv("RESPONSE_SLA"); sla != "" {
+		responseSLA, err = time.ParseDuration(sla)
+		if err != nil {
+			log.WithError(err).Fatal("can not parse RESPONSE_SLA")
+		}
+	}
+
+	var requestTimeout time.Duration
+	if timeout := os.Getenv("REQUEST_TIMEOUT"); timeout != "" {
+		requestTimeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			log.WithError(err).Fatal("can not parse REQUEST_TIMEOUT")
+		}
+	}
+
+	searchRate, err := intEnv("SEARCH_REQUESTS_PER_MINUTE")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse SEARCH_REQUESTS_PER_MINUTE")
+	}
+
+	var timezone *time.Location
+	if tz := os.Getenv("REPORT_TIMEZONE"); tz != "" {
+		timezone, err = time.LoadLocation(tz)
+		if err != nil {
+			log.WithError(err).Fatal("can not parse REPORT_TIMEZONE")
+		}
+	}
+
+	staleDays, err := intEnv("REPORT_STALE_DAYS")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse REPORT_STALE_DAYS")
+	}
+
+	maxCommitPages, err := intEnv("MAX_COMMIT_PAGES")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse MAX_COMMIT_PAGES")
+	}
+
+	maxWorkflowRunPages, err := intEnv("MAX_WORKFLOW_RUN_PAGES")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse MAX_WORKFLOW_RUN_PAGES")
+	}
+
+	projectNumber, err := intEnv("PROJECT_NUMBER")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse PROJECT_NUMBER")
+	}
+
+	maxItemsPerRepo, err := intEnv("MAX_ITEMS_PER_REPO")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse MAX_ITEMS_PER_REPO")
+	}
+
+	maxItems, err := intEnv("MAX_ITEMS")
+	if err != nil {
+		log.WithError(err).Fatal("can not parse MAX_ITEMS")
+	}
+
 	port := os.Getenv("PORT")
 
 	ll := log.New()
 
 	options := server.Options{
-		Repos:       strings.Split(repos, ","),
-		DaysOld:     daysOld,
-		APIEndpoint: endpoint,
-		Token:       token,
-		Port:        port,
-		Log:         ll,
+		Repos:             repos,
+		Orgs:              orgs,
+		ExcludeRepos:      excludeRepos,
+		IgnoreItems:       ignoreItems,
+		IncludeArchived:   os.Getenv("INCLUDE_ARCHIVED") != "",
+		VerifyRepos:       os.Getenv("VERIFY_REPOS") != "",
+		DaysOld:           daysOld,
+		BatchSize:         batchSize,
+		Concurrency:       concurrency,
+		APIEndpoint:       endpoint,
+		APIUploadEndpoint: os.Getenv("GITHUB_UPLOAD_ENDPOINT"),
+		Tokens:            tokens,
+		TokenFile:         tokenFile,
+		AppID:             appID,
+		InstallationID:    installationID,
+		AppPrivateKeyPath: os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH"),
+		Token:             token,
+		Port:              port,
+		Log:               ll,
+
+		RetryOnRateLimit: true,
+		RequestTimeout:   requestTimeout,
+		ProxyURL:         os.Getenv("PROXY_URL"),
+		CacheTTL:         cacheTTL,
+		Cache:            cache,
+		CacheResponses:   os.Getenv("CACHE_RESPONSES") != "",
+		UseGraphQL:       os.Getenv("USE_GRAPHQL") != "",
+		SeparateSearches: os.Getenv("SEPARATE_SEARCHES") != "",
+		AnonymizeAuthors: os.Getenv("ANONYMIZE_AUTHORS") != "",
+		AnonymizeSalt:    os.Getenv("ANONYMIZE_SALT"),
+
+		SearchRequestsPerMinute: searchRate,
+
+		IncludePRDetails:    os.Getenv("INCLUDE_PR_DETAILS") != "",
+		IncludeReviewStatus: os.Getenv("INCLUDE_REVIEW_STATUS") != "",
+		Timezone:            timezone,
+		IncludeClosed:       os.Getenv("INCLUDE_CLOSED") != "",
+		ExcludeNotPlanned:   os.Getenv("EXCLUDE_NOT_PLANNED") != "",
+		IncludeMerged:       os.Getenv("INCLUDE_MERGED") != "",
+		IncludeReleases:     os.Getenv("INCLUDE_RELEASES") != "",
+		IncludeTags:         os.Getenv("INCLUDE_TAGS") != "",
+		IncludeRepoMeta:     os.Getenv("INCLUDE_REPO_META") != "",
+		IncludeCommits:      os.Getenv("INCLUDE_COMMITS") != "",
+		MaxCommitPages:      maxCommitPages,
+		IncludeCIStats:      os.Getenv("INCLUDE_CI_STATS") != "",
+		IncludeDiscussions:  os.Getenv("INCLUDE_DISCUSSIONS") != "",
+		StaleDays:           staleDays,
+		MaxItemsPerRepo:     maxItemsPerRepo,
+		MaxItems:            maxItems,
+		Triage:              os.Getenv("REPORT_TRIAGE") != "",
+		ExcludeBots:         os.Getenv("REPORT_EXCLUDE_BOTS") != "",
+
+		IncludeSecurityAlerts: os.Getenv("INCLUDE_SECURITY_ALERTS") != "",
+		MaxWorkflowRunPages:   maxWorkflowRunPages,
+
+		ProjectOwner:       os.Getenv("PROJECT_OWNER"),
+		ProjectNumber:      projectNumber,
+		ProjectStatusField: os.Getenv("PROJECT_STATUS_FIELD"),
+
+		IncludeFirstResponse: os.Getenv("INCLUDE_FIRST_RESPONSE") != "",
+		IncludeReviewerStats: os.Getenv("INCLUDE_REVIEWER_STATS") != "",
+		ResponseSLA:          responseSLA,
+		CompareWithPrevious:  os.Getenv("COMPARE_WITH_PREVIOUS") != "",
+
+		IncludeApprovedUnmerged: os.Getenv("INCLUDE_APPROVED_UNMERGED") != "",
+		IncludeNeedsReview:      os.Getenv("INCLUDE_NEEDS_REVIEW") != "",
+
+		GitLabEndpoint: os.Getenv("GITLAB_ENDPOINT"),
+		GitLabToken:    os.Getenv("GITLAB_TOKEN"),
+		GiteaEndpoint:  os.Getenv("GITEA_ENDPOINT"),
+		GiteaToken:     os.Getenv("GITEA_TOKEN"),
 	}
 
 	srv, err := server.NewServer(options)
@@ -59,6 +253,13 @@ func main() {
 		log.WithError(err).Fatal("failed build server")
 	}
 
+	preflightCtx, preflightCancel := context.WithTimeout(context.Background(), time.Minute)
+	err = srv.Preflight(preflightCtx)
+	preflightCancel()
+	if err != nil {
+		log.WithError(err).Fatal("GitHub credentials check failed")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	c := make(chan os.Signal, 1)
@@ -97,3 +298,25 @@ func main() {
 	}
 	log.Info("shutdown completed")
 }
+
+// intEnv parses an optional integer environment variable, returning zero when
+// it is unset.
+func intEnv(name string) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(v)
+}
+
+// int64Env parses an optional 64-bit integer environment variable, returning
+// zero when it is unset.
+func int64Env(name string) (int64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(v, 10, 64)
+}
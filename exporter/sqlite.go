@@ -0,0 +1,312 @@
+// Package exporter writes activity reports to other formats for querying
+// outside of the report itself.
+package exporter
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	// Registers the pure Go "sqlite" driver, so exports don't need cgo.
+	_ "modernc.org/sqlite"
+
+	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
+)
+
+// SQLiteDriver is the database/sql driver ExportSQLite opens databases
+// with. It defaults to the modernc.org/sqlite driver this package
+// registers; programs can register another and name it here.
+var SQLiteDriver = "sqlite"
+
+// sqliteSchema creates the tables ExportSQLite writes to, unless they
+// already exist. Each export adds a run, and the repos and items in it.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	generated_at        TEXT NOT NULL,
+	since               TEXT,
+	until               TEXT,
+	days_old            INTEGER,
+	activity_mode       TEXT,
+	options             TEXT,
+	total_issues        INTEGER NOT NULL,
+	total_pull_requests INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS repos (
+	run_id    INTEGER NOT NULL REFERENCES runs(id),
+	repo      TEXT NOT NULL,
+	truncated INTEGER NOT NULL,
+	error     TEXT,
+	PRIMARY KEY (run_id, repo)
+);
+
+CREATE TABLE IF NOT EXISTS items (
+	run_id                 INTEGER NOT NULL REFERENCES runs(id),
+	repo                   TEXT NOT NULL,
+	section                TEXT NOT NULL,
+	id                     INTEGER,
+	number                 INTEGER,
+	title                  TEXT,
+	author                 TEXT,
+	author_url             TEXT,
+	url                    TEXT,
+	status                 TEXT,
+	age                    TEXT,
+	age_seconds            INTEGER,
+	new                    INTEGER,
+	author_association     TEXT,
+	first_time             INTEGER,
+	body_excerpt           TEXT,
+	labels                 TEXT,
+	assignees              TEXT,
+	milestone              TEXT,
+	comments               INTEGER,
+	reactions              INTEGER,
+	plus_one               INTEGER,
+	draft                  INTEGER,
+	size                   TEXT,
+	requested_reviewers    TEXT,
+	base_ref               TEXT,
+	head_ref               TEXT,
+	checks_status          TEXT,
+	closes_issues          TEXT,
+	has_linked_pr          INTEGER,
+	review_status          TEXT,
+	approved_at            TEXT,
+	created_at             TEXT,
+	updated_at             TEXT,
+	closed_at              TEXT,
+	merged_at              TEXT,
+	merged_by              TEXT,
+	first_response_at      TEXT,
+	first_response_seconds INTEGER,
+	response_overdue       INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS items_run_repo ON items (run_id, repo);
+CREATE INDEX IF NOT EXISTS items_author ON items (author);
+`
+
+// Sections of a repo's report, as recorded in the items table.
+const (
+	SectionIssues           = "issues"
+	SectionPullRequests     = "pull_requests"
+	SectionClosedIssues     = "closed_issues"
+	SectionMerged           = "merged_pull_requests"
+	SectionStale            = "stale"
+	SectionApprovedUnmerged = "approved_unmerged"
+	SectionNeedsReview      = "needs_review"
+	SectionDiscussions      = "discussions"
+)
+
+const insertItem = `INSERT INTO items (
+	run_id, repo, section, id, number, title, author, author_url, url, status,
+	age, age_seconds, new, author_association, first_time, body_excerpt,
+	labels, assignees, milestone, comments, reactions, plus_one, draft, size,
+	requested_reviewers, base_ref, head_ref, checks_status, closes_issues,
+	has_linked_pr, review_status, approved_at, created_at, updated_at,
+	closed_at, merged_at, merged_by, first_response_at,
+	first_response_seconds, response_overdue
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// ExportSQLite appends the report to the SQLite database at path as a new
+// run, creating the database and its tables if needed, so that the history
+// of reports accumulates across exports. Every list of items in each repo's
+// report is written to the items table, with the section it came from.
+// Lists such as labels and assignees are stored as JSON, and times in
+// RFC 3339 form.
+func ExportSQLite(report *ghra.ActivityReport, path string) error {
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("creating tables: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := exportRun(tx, report); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// exportRun inserts the report's run, repos, and items.
+func exportRun(tx *sql.Tx, report *ghra.ActivityReport) error {
+	generatedAt := report.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	var since, until, daysOld, mode, options interface{}
+	if o := report.Options; o != nil {
+		since, until = timeValue(o.Since), timeValue(o.Until)
+		daysOld, mode = o.DaysOld, o.ActivityMode
+		options, _ = jsonValue(o)
+	}
+
+	res, err := tx.Exec(`INSERT INTO runs (generated_at, since, until, days_old, activity_mode, options, total_issues, total_pull_requests) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		generatedAt.UTC().Format(time.RFC3339), since, until, daysOld, mode, options, report.TotalIssues, report.TotalPullRequests)
+	if err != nil {
+		return fmt.Errorf("inserting run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	items, err := tx.Prepare(insertItem)
+	if err != nil {
+		return err
+	}
+	defer items.Close()
+
+	for _, repo := range reportRepos(report) {
+		var (
+			truncated bool
+			errMsg    interface{}
+		)
+		if err := report.Errors[repo]; err != nil {
+			errMsg = err.Error()
+		}
+		activity := report.RepoActivityReports[repo]
+		if activity != nil {
+			truncated = activity.Truncated
+		}
+		if _, err := tx.Exec(`INSERT INTO repos (run_id, repo, truncated, error) VALUES (?, ?, ?, ?)`, runID, repo, truncated, errMsg); err != nil {
+			return fmt.Errorf("inserting repo %s: %w", repo, err)
+		}
+		if activity == nil {
+			continue
+		}
+
+		sections := []struct {
+			name  string
+			items []ghra.IssueInfo
+		}{
+			{SectionIssues, activity.Issues},
+			{SectionPullRequests, activity.PullRequests},
+			{SectionClosedIssues, activity.ClosedIssues},
+			{SectionMerged, activity.MergedPullRequests},
+			{SectionStale, activity.Stale},
+			{SectionApprovedUnmerged, activity.ApprovedUnmerged},
+			{SectionNeedsReview, activity.NeedsReview},
+			{SectionDiscussions, activity.Discussions},
+		}
+		for _, section := range sections {
+			for _, item := range section.items {
+				args, err := itemValues(item)
+				if err != nil {
+					return err
+				}
+				if _, err := items.Exec(append([]interface{}{runID, repo, section.name}, args...)...); err != nil {
+					return fmt.Errorf("inserting %s item %s: %w", section.name, item.URL, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// reportRepos returns the report's repos in order, followed by those that
+// couldn't be fetched.
+func reportRepos(report *ghra.ActivityReport) []string {
+	repos := report.Repos()
+
+	var failed []string
+	for repo := range report.Errors {
+		if report.RepoActivityReports[repo] == nil {
+			failed = append(failed, repo)
+		}
+	}
+	sort.Strings(failed)
+
+	return append(repos, failed...)
+}
+
+// itemValues returns the columns of the items table for item, after the
+// run, repo, and section.
+func itemValues(item ghra.IssueInfo) ([]interface{}, error) {
+	labels, err := jsonValue(item.Labels)
+	if err != nil {
+		return nil, err
+	}
+	assignees, err := jsonValue(item.Assignees)
+	if err != nil {
+		return nil, err
+	}
+	size, err := jsonValue(item.Size)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := jsonValue(item.RequestedReviewers)
+	if err != nil {
+		return nil, err
+	}
+	closes, err := jsonValue(item.ClosesIssues)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone, mergedBy, approvedAt, firstResponseAt, firstResponseSeconds interface{}
+	if item.Milestone != nil {
+		milestone = item.Milestone.Title
+	}
+	if item.MergedBy != nil {
+		mergedBy = item.MergedBy.DisplayName
+	}
+	if item.ApprovedAt != nil {
+		approvedAt = timeValue(*item.ApprovedAt)
+	}
+	if item.FirstResponseAt != nil {
+		firstResponseAt = timeValue(*item.FirstResponseAt)
+	}
+	if item.FirstResponseLatency != nil {
+		firstResponseSeconds = int64(item.FirstResponseLatency.Seconds())
+	}
+
+	return []interface{}{
+		item.ID, item.Number, item.Title, item.Author.DisplayName, item.Author.ProfileURL, item.URL, item.Status,
+		item.Age, item.AgeSeconds, item.New, item.AuthorAssociation, item.FirstTime, item.BodyExcerpt,
+		labels, assignees, milestone, item.Comments, item.Reactions.TotalCount, item.Reactions.PlusOne, item.Draft, size,
+		reviewers, item.BaseRef, item.HeadRef, item.ChecksStatus, closes,
+		item.HasLinkedPR, item.ReviewStatus, approvedAt, timeValue(item.CreatedAt), timeValue(item.UpdatedAt),
+		timeValue(item.ClosedAt), timeValue(item.MergedAt), mergedBy, firstResponseAt,
+		firstResponseSeconds, item.ResponseOverdue,
+	}, nil
+}
+
+// timeValue formats t in RFC 3339 form in UTC, or returns nil for the zero
+// time so it is stored as NULL.
+func timeValue(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// jsonValue marshals v as JSON, or returns nil for empty values so they are
+// stored as NULL.
+func jsonValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if s := string(b); s == "null" || s == "[]" {
+		return nil, nil
+	}
+
+	return string(b), nil
+}
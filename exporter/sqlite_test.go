@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
+)
+
+func TestExportSQLite(t *testing.T) {
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	report := &ghra.ActivityReport{
+		RepoActivityReports: map[string]*ghra.RepoActivityReport{
+			"acme/core": {
+				Issues: []ghra.IssueInfo{
+					{Number: 1, Title: "Crash on start", Repo: "acme/core", CreatedAt: created, Labels: []ghra.Label{{Name: "bug"}}},
+					{Number: 2, Title: "Typo in docs", Repo: "acme/core", CreatedAt: created},
+				},
+				PullRequests: []ghra.IssueInfo{
+					{Number: 3, Title: "Fix crash", Repo: "acme/core", CreatedAt: created},
+				},
+				MergedPullRequests: []ghra.IssueInfo{
+					{Number: 4, Title: "Bump deps", Repo: "acme/core", CreatedAt: created, MergedAt: created},
+				},
+			},
+			"acme/docs": {},
+		},
+		Errors: map[string]error{
+			"acme/gone": errors.New("not found"),
+		},
+		TotalIssues:       2,
+		TotalPullRequests: 1,
+		GeneratedAt:       created,
+	}
+
+	path := filepath.Join(t.TempDir(), "activity.db")
+	for i := 0; i < 2; i++ {
+		if err := ExportSQLite(report, path); err != nil {
+			t.Fatalf("export %d: %v", i+1, err)
+		}
+	}
+
+	db, err := sql.Open(SQLiteDriver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"SELECT COUNT(*) FROM runs", 2},
+		{"SELECT COUNT(*) FROM repos", 6},
+		{"SELECT COUNT(*) FROM repos WHERE error IS NOT NULL", 2},
+		{"SELECT COUNT(*) FROM items", 8},
+		{"SELECT COUNT(*) FROM items WHERE section = 'issues'", 4},
+		{"SELECT COUNT(*) FROM items WHERE section = 'pull_requests'", 2},
+		{"SELECT COUNT(*) FROM items WHERE section = 'merged_pull_requests'", 2},
+		{"SELECT COUNT(*) FROM items WHERE labels LIKE '%\"bug\"%'", 2},
+		{"SELECT SUM(total_issues) FROM runs", 4},
+	}
+	for _, tt := range tests {
+		var got int
+		if err := db.QueryRow(tt.query).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package ghra
+
+import "time"
+
+// Modes for GitHubRepoActivityOptions.ActivityMode.
+const (
+	ActivityCreated = "created"
+	ActivityUpdated = "updated"
+)
+
+// activityMode returns the search qualifier the report window applies to.
+func (ghra *GitHubRepoActivityService) activityMode() string {
+	if ghra.options.ActivityMode == ActivityUpdated {
+		return ActivityUpdated
+	}
+
+	return ActivityCreated
+}
+
+// createdInWindow reports whether an item created at created falls within
+// the report window, as opposed to only having been updated in it.
+func (ghra *GitHubRepoActivityService) createdInWindow(created time.Time) bool {
+	since, until := ghra.window()
+	return !created.Before(since) && (until.IsZero() || !created.After(until))
+}
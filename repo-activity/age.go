@@ -0,0 +1,38 @@
+package ghra
+
+import (
+	"time"
+
+	"github.com/hako/durafmt"
+)
+
+// Formats for GitHubRepoActivityOptions.AgeFormat.
+const (
+	AgeFormatShort = "short"
+	AgeFormatFull  = "full"
+)
+
+// age returns the time since created. Items can't be created in the future,
+// so clock skew doesn't produce a negative age.
+func (ghra *GitHubRepoActivityService) age(created time.Time) time.Duration {
+	d := ghra.now().Sub(created)
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// formatAge renders the time since created according to AgeFormat.
+func (ghra *GitHubRepoActivityService) formatAge(created time.Time) string {
+	return ghra.formatDuration(ghra.age(created))
+}
+
+// formatDuration renders d according to AgeFormat.
+func (ghra *GitHubRepoActivityService) formatDuration(d time.Duration) string {
+	if ghra.options.AgeFormat == AgeFormatFull {
+		return durafmt.Parse(d.Round(time.Hour * 24)).String()
+	}
+
+	return durafmt.Parse(d).LimitFirstN(1).String()
+}
@@ -0,0 +1,113 @@
+package ghra
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// pseudonymPrefix starts every pseudonym given by AnonymizeAuthors.
+const pseudonymPrefix = "contributor-"
+
+// NewAnonymizeSalt returns a random AnonymizeSalt, for callers that build
+// several services whose pseudonyms should match.
+func NewAnonymizeSalt() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating anonymize salt: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// anonymizeSalt returns AnonymizeSalt, or a random salt generated on first
+// use and shared with the services made from ghra with WithOptions.
+func (ghra *GitHubRepoActivityService) anonymizeSalt() (string, error) {
+	if ghra.options.AnonymizeSalt != "" {
+		return ghra.options.AnonymizeSalt, nil
+	}
+
+	l := ghra.lookups
+	l.saltMu.Lock()
+	defer l.saltMu.Unlock()
+	if l.salt == "" {
+		salt, err := NewAnonymizeSalt()
+		if err != nil {
+			return "", err
+		}
+		l.salt = salt
+	}
+
+	return l.salt, nil
+}
+
+// pseudonym returns the stable pseudonym for login with salt. Logins are
+// case-insensitive, so they're lowercased before hashing.
+func pseudonym(salt, login string) string {
+	sum := sha256.Sum256([]byte(salt + "\x00" + strings.ToLower(login)))
+	return pseudonymPrefix + hex.EncodeToString(sum[:3])
+}
+
+// anonymizeAuthors replaces every user in the report with their pseudonym.
+// It runs before the author stats and new contributors are worked out, so
+// those use the pseudonyms too.
+func (ghra *GitHubRepoActivityService) anonymizeAuthors(report *ActivityReport) error {
+	salt, err := ghra.anonymizeSalt()
+	if err != nil {
+		return err
+	}
+
+	anonymize := func(a *IssueAuthor) {
+		a.DisplayName = pseudonym(salt, a.DisplayName)
+		a.ProfileURL = ""
+	}
+	anonymizeItems := func(items []IssueInfo) {
+		for i := range items {
+			item := &items[i]
+			anonymize(&item.Author)
+			for j := range item.Assignees {
+				anonymize(&item.Assignees[j])
+			}
+			for j := range item.RequestedReviewers {
+				anonymize(&item.RequestedReviewers[j])
+			}
+			if item.MergedBy != nil {
+				by := *item.MergedBy
+				anonymize(&by)
+				item.MergedBy = &by
+			}
+		}
+	}
+
+	for _, r := range report.RepoActivityReports {
+		anonymizeItems(r.Issues)
+		anonymizeItems(r.PullRequests)
+		anonymizeItems(r.ClosedIssues)
+		anonymizeItems(r.MergedPullRequests)
+		anonymizeItems(r.Stale)
+		anonymizeItems(r.ApprovedUnmerged)
+		anonymizeItems(r.NeedsReview)
+		anonymizeItems(r.Discussions)
+		for i := range r.Releases {
+			anonymize(&r.Releases[i].Author)
+		}
+		if r.CommitStats != nil {
+			for i := range r.CommitStats.TopCommitters {
+				anonymize(&r.CommitStats.TopCommitters[i].Author)
+			}
+		}
+	}
+	anonymizeItems(report.ReviewQueue)
+
+	if report.ReviewerStats != nil {
+		stats := make(map[string]int, len(report.ReviewerStats))
+		for login, n := range report.ReviewerStats {
+			stats[pseudonym(salt, login)] += n
+		}
+		report.ReviewerStats = stats
+	}
+
+	return nil
+}
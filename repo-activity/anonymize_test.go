@@ -0,0 +1,73 @@
+package ghra
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeSaltSharedByDerivedServices(t *testing.T) {
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false)},
+	}, nil)
+
+	options := GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		DaysOld:                 7,
+		AnonymizeAuthors:        true,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	}
+	author := func(s *GitHubRepoActivityService) string {
+		t.Helper()
+		r, err := s.BuildReportContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r.RepoActivityReports["acme/core"].Issues[0].Author.DisplayName
+	}
+	derive := func(s *GitHubRepoActivityService, daysOld int) *GitHubRepoActivityService {
+		t.Helper()
+		o := options
+		o.DaysOld = daysOld
+		derived, err := s.WithOptions(&o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return derived
+	}
+
+	s, err := NewGitHubRepoActivityService(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := author(derive(s, 7))
+	if !strings.HasPrefix(got, pseudonymPrefix) {
+		t.Fatalf("author = %q, want a pseudonym", got)
+	}
+	if again := author(derive(s, 30)); again != got {
+		t.Errorf("derived services gave pseudonyms %q and %q, want the same", got, again)
+	}
+
+	other, err := NewGitHubRepoActivityService(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if author(other) == got {
+		t.Errorf("separate services both gave pseudonym %q, want random salts", got)
+	}
+
+	salt, err := NewAnonymizeSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	salted := options
+	salted.AnonymizeSalt = salt
+	a, err := NewGitHubRepoActivityService(&salted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := author(a), pseudonym(salt, "octocat"); got != want {
+		t.Errorf("author = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package ghra
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const defaultAPIEndpoint = "https://api.github.com/"
+
+// appJWTLifetime is how long the JWT used to request installation tokens is
+// valid for. GitHub allows at most ten minutes.
+const appJWTLifetime = 9 * time.Minute
+
+// appTokenSource mints installation access tokens for a GitHub App. Wrapped in
+// oauth2.ReuseTokenSource, a new token is requested whenever the previous one
+// has expired.
+type appTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	endpoint       string
+	client         *http.Client
+}
+
+var _ oauth2.TokenSource = &appTokenSource{}
+
+// newAppTokenSource builds a token source from the GitHub App options.
+func newAppTokenSource(options *GitHubRepoActivityOptions, transport http.RoundTripper) (oauth2.TokenSource, error) {
+	if options.InstallationID == 0 {
+		return nil, errors.New("a GitHub App installation ID is required with an app ID")
+	}
+
+	pemBytes := options.AppPrivateKey
+	if len(pemBytes) == 0 {
+		if options.AppPrivateKeyPath == "" {
+			return nil, errors.New("a GitHub App private key is required with an app ID")
+		}
+
+		var err error
+		pemBytes, err = os.ReadFile(options.AppPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading GitHub App private key: %w", err)
+		}
+	}
+
+	key, err := parsePrivateKey(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GitHub App private key: %w", err)
+	}
+
+	endpoint := defaultAPIEndpoint
+	if options.APIEndpoint != "" {
+		u, err := parseEndpoint(options.APIEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		endpoint = u.String()
+	}
+
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{
+		appID:          options.AppID,
+		installationID: options.InstallationID,
+		key:            key,
+		endpoint:       endpoint,
+		client:         &http.Client{Transport: transport},
+	}), nil
+}
+
+// parsePrivateKey decodes a PEM encoded PKCS #1 or PKCS #8 RSA key, as
+// downloaded from the GitHub App settings.
+func parsePrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return key, nil
+}
+
+// Token requests a new installation access token.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.endpoint, s.installationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.machine-man-preview+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: requesting installation token: %s %s", ErrUnauthorized, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		TokenType:   "token",
+		Expiry:      token.ExpiresAt,
+	}, nil
+}
+
+// jwt returns a JWT signed with the app's private key, which authenticates
+// as the app itself.
+func (s *appTokenSource) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		// Backdate the token to allow for clock drift.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": s.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,146 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/sync/errgroup"
+)
+
+// buildApprovedQuery builds a query for the open, approved pull requests in
+// repoNames.
+func (ghra *GitHubRepoActivityService) buildApprovedQuery(repoNames []string) string {
+	repos := scopeQualifiers(repoNames)
+
+	query := fmt.Sprintf("is:pr is:open review:approved %s", strings.Join(repos, " "))
+	if q := ghra.qualifiers("pr"); len(q) > 0 {
+		query += " " + strings.Join(q, " ")
+	}
+
+	return query
+}
+
+// addApprovedUnmerged searches for the open pull requests that have been
+// approved and adds them to each repo's ApprovedUnmerged, those waiting
+// longest first. Their Age is the time since their latest approval, which
+// takes an extra API request per pull request to look up.
+func (ghra *GitHubRepoActivityService) addApprovedUnmerged(ctx context.Context, report *ActivityReport) error {
+	var search searchFunc = ghra.searchIssues
+	if ghra.options.UseGraphQL {
+		search = ghra.searchGraphQL
+	}
+
+	approved, err := ghra.fetchBatches(ctx, ghra.batches("pr"), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		var items []IssueInfo
+		truncated := false
+		seen := make(map[string]bool)
+		for _, q := range ghra.labelQualifiers() {
+			found, total, err := withQualifier(search, q)(ctx, ghra.buildApprovedQuery(repos))
+			if err != nil {
+				return nil, false, err
+			}
+			truncated = truncated || total > len(found)
+			items = mergeItems(items, seen, found)
+		}
+		return items, truncated, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := ghra.setApprovedAt(ctx, approved.items); err != nil {
+		return err
+	}
+
+	prs := approved.items
+	ghra.canonicalizeRepos(prs)
+	sort.SliceStable(prs, func(i, j int) bool {
+		return prs[i].AgeDuration > prs[j].AgeDuration
+	})
+	for _, pr := range prs {
+		if report.RepoActivityReports[pr.Repo] == nil {
+			report.RepoActivityReports[pr.Repo] = &RepoActivityReport{}
+		}
+		report.RepoActivityReports[pr.Repo].ApprovedUnmerged = append(report.RepoActivityReports[pr.Repo].ApprovedUnmerged, pr)
+	}
+	report.TotalApprovedUnmerged = len(prs)
+	report.markTruncated(approved.truncated)
+	report.addErrors(approved.errors)
+
+	return nil
+}
+
+// setApprovedAt looks up when each pull request was last approved, and sets
+// its Age to the time since then. Lookups run Concurrency at a time.
+func (ghra *GitHubRepoActivityService) setApprovedAt(ctx context.Context, prs []IssueInfo) error {
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(ghra.concurrency())
+
+	for i := range prs {
+		pr := &prs[i]
+		owner, name, ok := splitRepo(pr.Repo)
+		if !ok {
+			continue
+		}
+
+		group.Go(func() error {
+			at, err := ghra.fetchApprovedAt(ctx, owner, name, pr.Number)
+			if err != nil {
+				return err
+			}
+			if at == nil {
+				return nil
+			}
+
+			waiting := ghra.age(*at)
+			pr.ApprovedAt = at
+			pr.Age = ghra.formatDuration(waiting)
+			pr.AgeDuration = waiting
+			pr.AgeSeconds = int64(waiting.Seconds())
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// fetchApprovedAt returns when a pull request was last approved, or nil if
+// its approvals have all been dismissed.
+func (ghra *GitHubRepoActivityService) fetchApprovedAt(ctx context.Context, owner, name string, number int) (*time.Time, error) {
+	opt := &github.ListOptions{PerPage: maxPerPage}
+
+	var latest *time.Time
+	for {
+		var reviews []*github.PullRequestReview
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			reviews, resp, err = ghra.client.PullRequests.ListReviews(ctx, owner, name, number, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range reviews {
+			if r.GetState() != ReviewApproved || r.SubmittedAt == nil {
+				continue
+			}
+			if latest == nil || r.SubmittedAt.After(*latest) {
+				at := *r.SubmittedAt
+				latest = &at
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return latest, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,68 @@
+package ghra
+
+import (
+	"sort"
+	"strings"
+)
+
+// AuthorStats counts the issues and pull requests an author opened during
+// the report window.
+type AuthorStats struct {
+	Author       IssueAuthor `json:"author"`
+	Issues       int         `json:"issues"`
+	PullRequests int         `json:"pull_requests"`
+}
+
+// Total is the number of issues and pull requests the author opened.
+func (s AuthorStats) Total() int {
+	return s.Issues + s.PullRequests
+}
+
+// authorStats counts what each author opened among issues and prs,
+// skipping items that were only updated during the window. Authors are
+// ordered by their total, most first, and then by login.
+func authorStats(issues, prs []IssueInfo) []AuthorStats {
+	byLogin := make(map[string]*AuthorStats)
+	count := func(i IssueInfo) *AuthorStats {
+		key := strings.ToLower(i.Author.DisplayName)
+		if byLogin[key] == nil {
+			byLogin[key] = &AuthorStats{Author: i.Author}
+		}
+		return byLogin[key]
+	}
+	for _, i := range issues {
+		if i.New {
+			count(i).Issues++
+		}
+	}
+	for _, pr := range prs {
+		if pr.New {
+			count(pr).PullRequests++
+		}
+	}
+
+	stats := make([]AuthorStats, 0, len(byLogin))
+	for _, s := range byLogin {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total() != stats[j].Total() {
+			return stats[i].Total() > stats[j].Total()
+		}
+		return strings.ToLower(stats[i].Author.DisplayName) < strings.ToLower(stats[j].Author.DisplayName)
+	})
+
+	return stats
+}
+
+// authorStats fills in the report's AuthorStats, across all repos and for
+// each repo.
+func (report *ActivityReport) authorStats() {
+	var issues, prs []IssueInfo
+	for _, r := range report.RepoActivityReports {
+		r.AuthorStats = authorStats(r.Issues, r.PullRequests)
+		issues = append(issues, r.Issues...)
+		prs = append(prs, r.PullRequests...)
+	}
+	report.AuthorStats = authorStats(issues, prs)
+}
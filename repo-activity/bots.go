@@ -0,0 +1,89 @@
+package ghra
+
+import "strings"
+
+// DefaultBots are the well-known bot accounts left out by ExcludeBots.
+var DefaultBots = []string{
+	"app/dependabot",
+	"app/renovate",
+	"app/github-actions",
+}
+
+// bots returns the accounts left out by ExcludeBots.
+func (ghra *GitHubRepoActivityService) bots() []string {
+	return append(DefaultBots[:len(DefaultBots):len(DefaultBots)], ghra.options.Bots...)
+}
+
+// botQualifiers returns a qualifier leaving out each of bots when
+// ExcludeBots is set.
+func (ghra *GitHubRepoActivityService) botQualifiers() []string {
+	if !ghra.options.ExcludeBots {
+		return nil
+	}
+
+	var q []string
+	for _, b := range ghra.bots() {
+		q = append(q, "-author:"+quoteQualifier(b))
+	}
+
+	return q
+}
+
+// botsFit reports whether bots can be added to the qualifiers q and still
+// leave room for a single repo's query, with the longest of its scopes and
+// labels, within GitHub's maximum query length. Otherwise the bots are left
+// out of the searches and filterBots drops their items instead.
+func (ghra *GitHubRepoActivityService) botsFit(issueType string, q, bots []string) bool {
+	var scope []string
+	for _, s := range ghra.scopes() {
+		if len(scope) == 0 || len(scopeQualifiers([]string{s})[0]) > len(scopeQualifiers(scope)[0]) {
+			scope = []string{s}
+		}
+	}
+
+	since, until := ghra.window()
+	query := ghra.windowQuery(issueType, ghra.activityMode(), scope, since, until, append(q[:len(q):len(q)], bots...))
+
+	return len(query)+longest(ghra.labelQualifiers())+1 <= maxQueryLength
+}
+
+// isBot reports whether login belongs to a GitHub App, which the API names
+// with a [bot] suffix.
+func isBot(login string) bool {
+	return strings.HasSuffix(login, "[bot]")
+}
+
+// isExcludedBot reports whether login is one of bots or belongs to any other
+// GitHub App.
+func (ghra *GitHubRepoActivityService) isExcludedBot(login string) bool {
+	if isBot(login) {
+		return true
+	}
+	for _, b := range ghra.bots() {
+		if app, ok := strings.CutPrefix(b, "app/"); ok {
+			b = app + "[bot]"
+		}
+		if strings.EqualFold(login, b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterBots drops the items opened by any bot when ExcludeBots is set,
+// including the bots search qualifiers didn't already leave out.
+func (ghra *GitHubRepoActivityService) filterBots(items []IssueInfo) []IssueInfo {
+	if !ghra.options.ExcludeBots {
+		return items
+	}
+
+	filtered := items[:0]
+	for _, i := range items {
+		if !ghra.isExcludedBot(i.Author.DisplayName) {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
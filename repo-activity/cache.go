@@ -0,0 +1,240 @@
+package ghra
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores values under string keys for a TTL. The response and report
+// caches are both stored in one: a MemoryCache keeps them in the process,
+// and a RedisCache keeps them outside it so they survive restarts and are
+// shared between replicas. Implementations must be safe for concurrent use
+// and treat their own failures as misses.
+type Cache interface {
+	// Get returns the value stored under key if it hasn't expired.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl, or until it's evicted if ttl is
+	// zero.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// MemoryCache is a Cache held in memory. It keeps at most a fixed number of
+// values, evicting the least recently used, and drops values once their TTL
+// has passed. It is safe for concurrent use.
+type MemoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+var _ Cache = &MemoryCache{}
+
+// DefaultMemoryCacheEntries is the number of values a MemoryCache holds when
+// NewMemoryCache is given zero.
+const DefaultMemoryCacheEntries = 1000
+
+// NewMemoryCache returns an empty MemoryCache holding up to maxEntries
+// values, or DefaultMemoryCacheEntries if maxEntries is zero.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key if it hasn't expired.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+
+	return entry.value, true
+}
+
+// Set stores value under key for ttl, or until it's evicted if ttl is zero,
+// evicting the least recently used value if the cache is full.
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Len returns the number of values held, including expired ones that
+// haven't been looked up since.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// CachedResponse is a successful API response stored for reuse by a
+// conditional request.
+type CachedResponse struct {
+	ETag   string
+	Header http.Header
+	Body   []byte
+}
+
+// ResponseCache stores API responses keyed by request URL so that repeated
+// requests can be made conditional with If-None-Match. A 304 Not Modified
+// answer doesn't count against the rate limit. NewResponseCache returns one
+// stored in a Cache.
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse)
+}
+
+// responseCacheKeyPrefix keeps the responses apart from the reports when a
+// Cache backs both.
+const responseCacheKeyPrefix = "response:"
+
+// NewResponseCache returns a ResponseCache that stores the responses in c as
+// JSON for ttl, or until c evicts them if ttl is zero. Pass a MemoryCache to
+// keep them in memory.
+func NewResponseCache(c Cache, ttl time.Duration) ResponseCache {
+	return &backedResponseCache{cache: c, ttl: ttl}
+}
+
+// backedResponseCache is a ResponseCache stored in a Cache.
+type backedResponseCache struct {
+	cache Cache
+	ttl   time.Duration
+}
+
+func (c *backedResponseCache) Get(key string) (*CachedResponse, bool) {
+	b, ok := c.cache.Get(responseCacheKeyPrefix + key)
+	if !ok {
+		return nil, false
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, false
+	}
+
+	return &resp, true
+}
+
+func (c *backedResponseCache) Set(key string, resp *CachedResponse) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+
+	c.cache.Set(responseCacheKeyPrefix+key, b, c.ttl)
+}
+
+// etagTransport makes GET requests conditional on a previously cached ETag
+// and replays the cached body when the API answers 304 Not Modified.
+type etagTransport struct {
+	cache ResponseCache
+	base  http.RoundTripper
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	cached, ok := t.cache.Get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		header := cached.Header.Clone()
+		// Keep the fresh rate limit information from the 304.
+		for k, v := range resp.Header {
+			if strings.HasPrefix(k, "X-Ratelimit-") {
+				header[k] = v
+			}
+		}
+
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+			ContentLength: int64(len(cached.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.cache.Set(key, &CachedResponse{
+		ETag:   etag,
+		Header: resp.Header.Clone(),
+		Body:   body,
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
@@ -0,0 +1,185 @@
+package ghra
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestETagTransportRevalidates(t *testing.T) {
+	var ifNoneMatch []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		w.Header().Set("X-RateLimit-Limit", "30")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("X-RateLimit-Remaining", "28")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-RateLimit-Remaining", "29")
+		w.Write([]byte(`{"total_count": 1, "items": [{"number": 7, "title": "Cached"}]}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		APIEndpoint:   srv.URL + "/",
+		ResponseCache: NewResponseCache(NewMemoryCache(0), 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		result, resp, err := s.client.Search.Issues(context.Background(), "repo:acme/core", nil)
+		if err != nil {
+			t.Fatalf("search %d: %v", i+1, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("search %d: status = %d, want %d", i+1, resp.StatusCode, http.StatusOK)
+		}
+		if len(result.Issues) != 1 || result.Issues[0].GetTitle() != "Cached" {
+			t.Errorf("search %d: issues = %v, want the cached issue", i+1, result.Issues)
+		}
+		if want := 29 - i; resp.Rate.Remaining != want {
+			t.Errorf("search %d: remaining = %d, want %d", i+1, resp.Rate.Remaining, want)
+		}
+	}
+
+	want := []string{"", `"v1"`}
+	if len(ifNoneMatch) != len(want) || ifNoneMatch[0] != want[0] || ifNoneMatch[1] != want[1] {
+		t.Errorf("If-None-Match = %q, want %q", ifNoneMatch, want)
+	}
+}
+
+func TestMemoryCacheEvicts(t *testing.T) {
+	c := NewMemoryCache(2)
+	c.Set("a", []byte("a"), time.Hour)
+	c.Set("b", []byte("b"), time.Hour)
+	c.Get("a")
+	c.Set("c", []byte("c"), 0)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used value wasn't evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if value, ok := c.Get(key); !ok || string(value) != key {
+			t.Errorf("Get(%q) = %q, %v, want %q, true", key, value, ok, key)
+		}
+	}
+	if n := c.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+
+	c = NewMemoryCache(0)
+	c.Set("a", []byte("a"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expired value was returned")
+	}
+	if n := c.Len(); n != 0 {
+		t.Errorf("Len() = %d after expiry, want 0", n)
+	}
+}
+
+func TestReportCacheInMemory(t *testing.T) {
+	c := NewReportCache(NewMemoryCache(0), time.Hour)
+	c.Set("key", &ActivityReport{TotalIssues: 3})
+
+	report, ok := c.Get("key")
+	if !ok || report.TotalIssues != 3 {
+		t.Fatalf("Get() = %+v, %v, want the cached report", report, ok)
+	}
+
+	c.Invalidate()
+	if _, ok := c.Get("key"); ok {
+		t.Error("report was returned after Invalidate")
+	}
+}
+
+func TestReportCacheKeyCoversOptions(t *testing.T) {
+	key := func(modify func(*GitHubRepoActivityOptions)) string {
+		options := &GitHubRepoActivityOptions{Repos: []string{"acme/core"}, DaysOld: 7}
+		modify(options)
+		s, err := NewGitHubRepoActivityService(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, ok := s.reportCacheKey()
+		if !ok {
+			t.Fatal("reportCacheKey() failed")
+		}
+		return k
+	}
+	base := key(func(*GitHubRepoActivityOptions) {})
+
+	tests := map[string]func(*GitHubRepoActivityOptions){
+		"UseGraphQL":       func(o *GitHubRepoActivityOptions) { o.UseGraphQL = true },
+		"SeparateSearches": func(o *GitHubRepoActivityOptions) { o.SeparateSearches = true },
+		"AgeFormat":        func(o *GitHubRepoActivityOptions) { o.AgeFormat = AgeFormatFull },
+		"ExcerptLength":    func(o *GitHubRepoActivityOptions) { o.ExcerptLength = 80 },
+		"PerPage":          func(o *GitHubRepoActivityOptions) { o.PerPage = 10 },
+		"PRSizeThresholds": func(o *GitHubRepoActivityOptions) { o.PRSizeThresholds = []int{5, 50, 500, 5000} },
+		"VerifyRepos":      func(o *GitHubRepoActivityOptions) { o.VerifyRepos = true },
+		"APIEndpoint":      func(o *GitHubRepoActivityOptions) { o.APIEndpoint = "https://github.example.com/api/v3/" },
+		"ExcludeBots":      func(o *GitHubRepoActivityOptions) { o.ExcludeBots = true },
+		"DaysOld":          func(o *GitHubRepoActivityOptions) { o.DaysOld = 14 },
+		"Bots":             func(o *GitHubRepoActivityOptions) { o.ExcludeBots, o.Bots = true, []string{"hubot"} },
+		"AnonymizeSalt":    func(o *GitHubRepoActivityOptions) { o.AnonymizeSalt = "pepper" },
+	}
+	for name, modify := range tests {
+		if key(modify) == base {
+			t.Errorf("changing %s doesn't change the key", name)
+		}
+	}
+}
+
+func TestReportCacheKeyCoversReportOptions(t *testing.T) {
+	// Values for the fields that ignore anything else.
+	values := map[string]interface{}{
+		"Timezone":     "Europe/Berlin",
+		"ActivityMode": ActivityUpdated,
+	}
+
+	key := func(recorded *ReportOptions) string {
+		s := NewGitHubRepoActivityServiceWithClient(github.NewClient(nil), recorded.GitHubRepoActivityOptions())
+		k, ok := s.reportCacheKey()
+		if !ok {
+			t.Fatal("reportCacheKey() failed")
+		}
+		return k
+	}
+	base := key(&ReportOptions{})
+
+	typ := reflect.TypeOf(ReportOptions{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		recorded := &ReportOptions{}
+		v := reflect.ValueOf(recorded).Elem().Field(i)
+		switch value, ok := values[field.Name]; {
+		case ok:
+			v.Set(reflect.ValueOf(value))
+		case field.Type == reflect.TypeOf(time.Time{}):
+			v.Set(reflect.ValueOf(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+		case v.Kind() == reflect.String:
+			v.SetString("acme")
+		case v.Kind() == reflect.Bool:
+			v.SetBool(true)
+		case v.Kind() == reflect.Int, v.Kind() == reflect.Int64:
+			v.SetInt(3)
+		case v.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String:
+			v.Set(reflect.ValueOf([]string{"acme/core#1"}))
+		default:
+			t.Fatalf("no test value for %s of type %s", field.Name, field.Type)
+		}
+
+		if key(recorded) == base {
+			t.Errorf("changing %s doesn't change the key", field.Name)
+		}
+	}
+}
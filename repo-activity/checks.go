@@ -0,0 +1,74 @@
+package ghra
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+// Checks statuses for IssueInfo.ChecksStatus.
+const (
+	ChecksSuccess = "success"
+	ChecksFailure = "failure"
+	ChecksPending = "pending"
+	ChecksNone    = "none"
+)
+
+func (ghra *GitHubRepoActivityService) includePRDetails() bool {
+	return ghra.options.IncludePRDetails || ghra.options.OnlyFailingChecks
+}
+
+// fetchChecksStatus rolls up the check runs on a pull request's head commit.
+// Only the first page of check runs is read so each pull request costs a
+// single request.
+func (ghra *GitHubRepoActivityService) fetchChecksStatus(ctx context.Context, owner, name, sha string) (string, error) {
+	opt := &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: maxPerPage},
+	}
+
+	var result *github.ListCheckRunsResults
+	_, err := ghra.call(ctx, func() (*github.Response, error) {
+		var (
+			resp *github.Response
+			err  error
+		)
+		result, resp, err = ghra.client.Checks.ListCheckRunsForRef(ctx, owner, name, sha, opt)
+		return resp, err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.CheckRuns) == 0 {
+		return ChecksNone, nil
+	}
+
+	status := ChecksSuccess
+	for _, run := range result.CheckRuns {
+		if run.GetStatus() != "completed" {
+			status = ChecksPending
+			continue
+		}
+		switch run.GetConclusion() {
+		case "failure", "cancelled", "timed_out", "action_required":
+			return ChecksFailure, nil
+		}
+	}
+
+	return status, nil
+}
+
+// graphQLChecksStatus maps a GraphQL status check rollup state to a checks
+// status.
+func graphQLChecksStatus(state string) string {
+	switch state {
+	case "":
+		return ChecksNone
+	case "SUCCESS":
+		return ChecksSuccess
+	case "FAILURE", "ERROR":
+		return ChecksFailure
+	default:
+		return ChecksPending
+	}
+}
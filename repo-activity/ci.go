@@ -0,0 +1,175 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// defaultMaxWorkflowRunPages caps the workflow runs counted per repo at 500.
+const defaultMaxWorkflowRunPages = 5
+
+// CIStats counts the completed GitHub Actions workflow runs on a repo's
+// default branch during the period. Cancelled and skipped runs aren't
+// counted.
+type CIStats struct {
+	Runs int `json:"runs"`
+	// Failures counts the runs that failed, timed out, or failed to start.
+	Failures int `json:"failures"`
+	// Workflows breaks the counts down by workflow, those with the most
+	// failures first.
+	Workflows []WorkflowStats `json:"workflows,omitempty"`
+	// Truncated is set when MaxWorkflowRunPages was reached and some runs
+	// weren't counted.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// WorkflowStats counts the runs of a single workflow.
+type WorkflowStats struct {
+	Name     string `json:"name"`
+	Runs     int    `json:"runs"`
+	Failures int    `json:"failures"`
+}
+
+// GreenPercent is the share of runs that didn't fail, rounded to a whole
+// percentage. It is 100 when there were no runs.
+func (s CIStats) GreenPercent() int {
+	if s.Runs == 0 {
+		return 100
+	}
+
+	return int(math.Round(100 * float64(s.Runs-s.Failures) / float64(s.Runs)))
+}
+
+// workflowRuns is a page of the workflow runs API, which go-github doesn't
+// have a type for.
+type workflowRuns struct {
+	TotalCount   int `json:"total_count"`
+	WorkflowRuns []struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"workflow_runs"`
+}
+
+// addCIStats fills in each repo's CIStats from the workflow runs created on
+// its default branch within the report window. Lookups run Concurrency
+// repos at a time, and a repo that fails is recorded in the report's Errors.
+func (ghra *GitHubRepoActivityService) addCIStats(ctx context.Context, report *ActivityReport) error {
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+
+	errs, err := ghra.eachRepo(ctx, report.Repos(), func(ctx context.Context, repo string) error {
+		owner, name, ok := splitRepo(repo)
+		if !ok {
+			return nil
+		}
+		r := report.RepoActivityReports[repo]
+
+		meta, err := ghra.repoMeta(ctx, owner, name)
+		var stats *CIStats
+		if err == nil {
+			stats, err = ghra.fetchCIStats(ctx, owner, name, meta.DefaultBranch, since, until)
+		}
+		if err != nil {
+			return fmt.Errorf("listing workflow runs: %w", err)
+		}
+
+		r.CIStats = stats
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.addErrors(errs)
+
+	return nil
+}
+
+// fetchCIStats counts a repo's workflow runs on branch created between since
+// and until, walking at most MaxWorkflowRunPages pages of them.
+func (ghra *GitHubRepoActivityService) fetchCIStats(ctx context.Context, owner, name, branch string, since, until time.Time) (*CIStats, error) {
+	params := url.Values{}
+	params.Set("branch", branch)
+	params.Set("created", since.UTC().Format(time.RFC3339)+".."+until.UTC().Format(time.RFC3339))
+	params.Set("per_page", strconv.Itoa(maxPerPage))
+
+	stats := &CIStats{}
+	byWorkflow := make(map[string]*WorkflowStats)
+	for pages, page := 0, 1; ; pages++ {
+		if pages == ghra.maxWorkflowRunPages() {
+			stats.Truncated = true
+			break
+		}
+
+		params.Set("page", strconv.Itoa(page))
+		req, err := ghra.client.NewRequest("GET", fmt.Sprintf("repos/%v/%v/actions/runs?%s", owner, name, params.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		runs := &workflowRuns{}
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			return ghra.client.Do(ctx, req, runs)
+		})
+		// Repos with Actions disabled have no runs to list.
+		if statusCode(err) == http.StatusNotFound {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, run := range runs.WorkflowRuns {
+			if run.Status != "completed" || run.Conclusion == "cancelled" || run.Conclusion == "skipped" {
+				continue
+			}
+			w := byWorkflow[run.Name]
+			if w == nil {
+				w = &WorkflowStats{Name: run.Name}
+				byWorkflow[run.Name] = w
+			}
+			w.Runs++
+			stats.Runs++
+			switch run.Conclusion {
+			case "failure", "timed_out", "startup_failure":
+				w.Failures++
+				stats.Failures++
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	for _, w := range byWorkflow {
+		stats.Workflows = append(stats.Workflows, *w)
+	}
+	sort.Slice(stats.Workflows, func(i, j int) bool {
+		a, b := stats.Workflows[i], stats.Workflows[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Name < b.Name
+	})
+
+	return stats, nil
+}
+
+func (ghra *GitHubRepoActivityService) maxWorkflowRunPages() int {
+	if ghra.options.MaxWorkflowRunPages <= 0 {
+		return defaultMaxWorkflowRunPages
+	}
+
+	return ghra.options.MaxWorkflowRunPages
+}
@@ -0,0 +1,49 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	// The handler never responds; it only returns once the client gives up
+	// or the test ends.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	timeout := 50 * time.Millisecond
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		RequestTimeout:          timeout,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, _, err = s.searchIssues(context.Background(), "repo:acme/core")
+	elapsed := time.Since(start)
+
+	var timeoutErr *ErrRequestTimeout
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("searchIssues() = %v, want an *ErrRequestTimeout", err)
+	}
+	if timeoutErr.Query != "repo:acme/core" || timeoutErr.Page != 1 {
+		t.Errorf("timed out on page %d of %q, want page 1 of %q", timeoutErr.Page, timeoutErr.Query, "repo:acme/core")
+	}
+	if elapsed < timeout || elapsed > 5*time.Second {
+		t.Errorf("request failed after %v, want soon after %v", elapsed, timeout)
+	}
+}
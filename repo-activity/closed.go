@@ -0,0 +1,46 @@
+package ghra
+
+import (
+	"context"
+	"time"
+)
+
+// addClosedIssues searches for the issues closed within the report window
+// and adds them to each repo's ClosedIssues, leaving out those closed as not
+// planned with ExcludeNotPlanned. Their Age is how long they were open before
+// being closed.
+func (ghra *GitHubRepoActivityService) addClosedIssues(ctx context.Context, report *ActivityReport) error {
+	closed, err := ghra.fetchWindow(ctx, "issue", "closed")
+	if err != nil {
+		return err
+	}
+
+	ghra.canonicalizeRepos(closed.items)
+	for _, i := range closed.items {
+		if ghra.options.ExcludeNotPlanned && i.StateReason == StateReasonNotPlanned {
+			continue
+		}
+		ghra.setTimeOpen(&i, i.ClosedAt)
+		if report.RepoActivityReports[i.Repo] == nil {
+			report.RepoActivityReports[i.Repo] = &RepoActivityReport{}
+		}
+		report.RepoActivityReports[i.Repo].ClosedIssues = append(report.RepoActivityReports[i.Repo].ClosedIssues, i)
+		report.TotalClosedIssues++
+	}
+	report.markTruncated(closed.truncated)
+	report.addErrors(closed.errors)
+
+	return nil
+}
+
+// setTimeOpen replaces the item's age with the time from its creation until
+// it was closed or merged at end.
+func (ghra *GitHubRepoActivityService) setTimeOpen(i *IssueInfo, end time.Time) {
+	open := end.Sub(i.CreatedAt)
+	if open < 0 {
+		open = 0
+	}
+	i.Age = ghra.formatDuration(open)
+	i.AgeDuration = open
+	i.AgeSeconds = int64(open.Seconds())
+}
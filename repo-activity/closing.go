@@ -0,0 +1,72 @@
+package ghra
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// closingPattern matches GitHub's closing keywords followed by an issue
+// reference, either #N or owner/repo#N.
+var closingPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+([\w.-]+/[\w.-]+)?#(\d+)\b`)
+
+// IssueRef refers to an issue, possibly in another repo.
+type IssueRef struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// closingReferences finds the issues a pull request says it closes. repo and
+// prURL are those of the pull request, and are used to resolve references
+// without an explicit repo and to build links on the same host.
+func closingReferences(text, repo, prURL string) []IssueRef {
+	host := prURL
+	if i := strings.Index(strings.ToLower(prURL), "/"+strings.ToLower(repo)+"/"); i >= 0 {
+		host = prURL[:i]
+	}
+
+	var refs []IssueRef
+	seen := make(map[string]bool)
+	for _, m := range closingPattern.FindAllStringSubmatch(text, -1) {
+		ref := IssueRef{Repo: repo}
+		if m[1] != "" {
+			ref.Repo = m[1]
+		}
+		ref.Number, _ = strconv.Atoi(m[2])
+		ref.URL = fmt.Sprintf("%s/%s/issues/%d", host, ref.Repo, ref.Number)
+
+		key := ref.key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// key identifies the issue regardless of the case of the repo name.
+func (ref IssueRef) key() string {
+	return fmt.Sprintf("%s#%d", strings.ToLower(ref.Repo), ref.Number)
+}
+
+// linkPullRequests flags the issues that a pull request in prs closes.
+func linkPullRequests(issues, prs []IssueInfo) {
+	fixed := make(map[string]bool)
+	for _, pr := range prs {
+		for _, ref := range pr.ClosesIssues {
+			fixed[ref.key()] = true
+		}
+	}
+	if len(fixed) == 0 {
+		return
+	}
+
+	for i := range issues {
+		ref := IssueRef{Repo: issues[i].Repo, Number: issues[i].Number}
+		issues[i].HasLinkedPR = fixed[ref.key()]
+	}
+}
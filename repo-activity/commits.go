@@ -0,0 +1,162 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	// defaultMaxCommitPages caps the commits counted per repo at 1,000.
+	defaultMaxCommitPages = 10
+
+	// maxTopCommitters is how many committers CommitStats lists.
+	maxTopCommitters = 5
+)
+
+// CommitStats counts the commits made to a repo's default branch during the
+// period.
+type CommitStats struct {
+	Commits int `json:"commits"`
+	// Authors is the number of distinct commit authors.
+	Authors int `json:"authors"`
+	// TopCommitters are the authors with the most commits, most first.
+	// Authors without a GitHub account are listed by their git name, without
+	// a ProfileURL.
+	TopCommitters []CommitterStats `json:"top_committers"`
+	// Truncated is set when MaxCommitPages was reached and some commits
+	// weren't counted.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CommitterStats counts the commits an author made during the period.
+type CommitterStats struct {
+	Author  IssueAuthor `json:"author"`
+	Commits int         `json:"commits"`
+}
+
+// addCommitStats fills in each repo's CommitStats from the commits made to
+// its default branch within the report window. Lookups run Concurrency
+// repos at a time, and a repo that fails is recorded in the report's Errors.
+func (ghra *GitHubRepoActivityService) addCommitStats(ctx context.Context, report *ActivityReport) error {
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+
+	errs, err := ghra.eachRepo(ctx, report.Repos(), func(ctx context.Context, repo string) error {
+		owner, name, ok := splitRepo(repo)
+		if !ok {
+			return nil
+		}
+		r := report.RepoActivityReports[repo]
+
+		stats, err := ghra.fetchCommitStats(ctx, owner, name, since, until)
+		if err != nil {
+			return fmt.Errorf("listing commits: %w", err)
+		}
+
+		r.CommitStats = stats
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.addErrors(errs)
+
+	return nil
+}
+
+// fetchCommitStats counts a repo's commits between since and until, walking
+// at most MaxCommitPages pages of them.
+func (ghra *GitHubRepoActivityService) fetchCommitStats(ctx context.Context, owner, name string, since, until time.Time) (*CommitStats, error) {
+	opt := &github.CommitsListOptions{
+		Since:       since,
+		Until:       until,
+		ListOptions: github.ListOptions{PerPage: maxPerPage},
+	}
+
+	stats := &CommitStats{}
+	byAuthor := make(map[string]*CommitterStats)
+	for pages := 0; ; pages++ {
+		if pages == ghra.maxCommitPages() {
+			stats.Truncated = true
+			break
+		}
+
+		var page []*github.RepositoryCommit
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			page, resp, err = ghra.client.Repositories.ListCommits(ctx, owner, name, opt)
+			return resp, err
+		})
+		// Empty repos have no branch to list commits from.
+		if statusCode(err) == http.StatusConflict {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, c := range page {
+			a := committer(c)
+			key := strings.ToLower(a.DisplayName)
+			if byAuthor[key] == nil {
+				byAuthor[key] = &CommitterStats{Author: a}
+			}
+			byAuthor[key].Commits++
+			stats.Commits++
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	committers := make([]CommitterStats, 0, len(byAuthor))
+	for _, c := range byAuthor {
+		committers = append(committers, *c)
+	}
+	sort.Slice(committers, func(i, j int) bool {
+		if committers[i].Commits != committers[j].Commits {
+			return committers[i].Commits > committers[j].Commits
+		}
+		return strings.ToLower(committers[i].Author.DisplayName) < strings.ToLower(committers[j].Author.DisplayName)
+	})
+	stats.Authors = len(committers)
+	if len(committers) > maxTopCommitters {
+		committers = committers[:maxTopCommitters]
+	}
+	stats.TopCommitters = committers
+
+	return stats, nil
+}
+
+// committer returns a commit's author, falling back to the name in the git
+// commit when it isn't linked to a GitHub account.
+func committer(c *github.RepositoryCommit) IssueAuthor {
+	if c.GetAuthor().GetLogin() != "" {
+		return author(c.Author)
+	}
+	if name := c.GetCommit().GetAuthor().GetName(); name != "" {
+		return IssueAuthor{DisplayName: name}
+	}
+
+	return ghost
+}
+
+func (ghra *GitHubRepoActivityService) maxCommitPages() int {
+	if ghra.options.MaxCommitPages <= 0 {
+		return defaultMaxCommitPages
+	}
+
+	return ghra.options.MaxCommitPages
+}
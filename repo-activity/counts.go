@@ -0,0 +1,60 @@
+package ghra
+
+// Counts is the number of items in each section of a repo's report.
+type Counts struct {
+	// NewIssues and NewPRs count Issues and PullRequests, which include
+	// the items only updated during the period with ActivityUpdated.
+	NewIssues        int `json:"new_issues"`
+	NewPRs           int `json:"new_prs"`
+	Closed           int `json:"closed"`
+	Merged           int `json:"merged"`
+	Stale            int `json:"stale"`
+	ApprovedUnmerged int `json:"approved_unmerged"`
+	NeedsReview      int `json:"needs_review"`
+	Discussions      int `json:"discussions"`
+	Releases         int `json:"releases"`
+}
+
+// count fills in each repo's Counts and sets the report's totals to their
+// sums, so the two always agree.
+func (report *ActivityReport) count() {
+	var total Counts
+	for _, r := range report.RepoActivityReports {
+		r.Counts = Counts{
+			NewIssues:        len(r.Issues),
+			NewPRs:           len(r.PullRequests),
+			Closed:           len(r.ClosedIssues),
+			Merged:           len(r.MergedPullRequests),
+			Stale:            len(r.Stale),
+			ApprovedUnmerged: len(r.ApprovedUnmerged),
+			NeedsReview:      len(r.NeedsReview),
+			Discussions:      len(r.Discussions),
+			Releases:         len(r.Releases),
+		}
+		// Filtering after the limits were applied can leave fewer items.
+		if r.IssuesLimit != nil {
+			r.IssuesLimit.Shown = r.Counts.NewIssues
+		}
+		if r.PullRequestsLimit != nil {
+			r.PullRequestsLimit.Shown = r.Counts.NewPRs
+		}
+
+		total.NewIssues += r.Counts.NewIssues
+		total.NewPRs += r.Counts.NewPRs
+		total.Closed += r.Counts.Closed
+		total.Merged += r.Counts.Merged
+		total.Stale += r.Counts.Stale
+		total.ApprovedUnmerged += r.Counts.ApprovedUnmerged
+		total.NeedsReview += r.Counts.NeedsReview
+		total.Discussions += r.Counts.Discussions
+	}
+
+	report.TotalIssues = total.NewIssues
+	report.TotalPullRequests = total.NewPRs
+	report.TotalClosedIssues = total.Closed
+	report.TotalMerged = total.Merged
+	report.TotalStale = total.Stale
+	report.TotalApprovedUnmerged = total.ApprovedUnmerged
+	report.TotalNeedsReview = total.NeedsReview
+	report.TotalDiscussions = total.Discussions
+}
@@ -0,0 +1,215 @@
+package ghra
+
+import (
+	"sort"
+	"strings"
+)
+
+// ItemKey identifies an issue or pull request across reports.
+type ItemKey struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+}
+
+// ItemChange is an issue or pull request found in both reports that differs
+// between them.
+type ItemChange struct {
+	Old IssueInfo `json:"old"`
+	New IssueInfo `json:"new"`
+	// Fields names what changed, such as "status", "title", or "labels".
+	Fields []string `json:"fields"`
+}
+
+// StatusChanged reports whether the item's Status changed, such as when an
+// issue was closed or a pull request merged.
+func (c ItemChange) StatusChanged() bool {
+	return c.Old.Status != c.New.Status
+}
+
+// Transition describes a change of Status, such as "open -> merged", or is
+// empty when the Status didn't change.
+func (c ItemChange) Transition() string {
+	if !c.StatusChanged() {
+		return ""
+	}
+
+	return c.Old.Status + " -> " + c.New.Status
+}
+
+// ReportDiff holds the issues and pull requests that were added, removed, or
+// changed between two reports, keyed by repo and ordered by number.
+type ReportDiff struct {
+	Added   map[string][]IssueInfo  `json:"added"`
+	Removed map[string][]IssueInfo  `json:"removed"`
+	Changed map[string][]ItemChange `json:"changed"`
+}
+
+// Repos returns the repos with any differences, in alphabetical order.
+func (d *ReportDiff) Repos() []string {
+	seen := make(map[string]bool)
+	for repo := range d.Added {
+		seen[repo] = true
+	}
+	for repo := range d.Removed {
+		seen[repo] = true
+	}
+	for repo := range d.Changed {
+		seen[repo] = true
+	}
+
+	repos := make([]string, 0, len(seen))
+	for repo := range seen {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	return repos
+}
+
+// DiffReports compares the issues and pull requests in two reports, such as
+// the snapshot of an earlier run and a new report. Items only in new are
+// added, those only in old removed, and those in both changed when their
+// status, title, draft state, labels, assignees, milestone, review status,
+// or checks differ. Items listed in several sections of a report, such as an
+// issue that is both new and closed, are compared once.
+func DiffReports(old, new *ActivityReport) *ReportDiff {
+	before, after := reportItems(old), reportItems(new)
+	diff := &ReportDiff{
+		Added:   make(map[string][]IssueInfo),
+		Removed: make(map[string][]IssueInfo),
+		Changed: make(map[string][]ItemChange),
+	}
+
+	for key, item := range after {
+		prev, ok := before[key]
+		if !ok {
+			diff.Added[key.Repo] = append(diff.Added[key.Repo], item)
+			continue
+		}
+		if fields := changedFields(prev, item); len(fields) > 0 {
+			diff.Changed[key.Repo] = append(diff.Changed[key.Repo], ItemChange{Old: prev, New: item, Fields: fields})
+		}
+	}
+	for key, item := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed[key.Repo] = append(diff.Removed[key.Repo], item)
+		}
+	}
+
+	for _, items := range diff.Added {
+		sortByNumber(items)
+	}
+	for _, items := range diff.Removed {
+		sortByNumber(items)
+	}
+	for _, changes := range diff.Changed {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].New.Number < changes[j].New.Number
+		})
+	}
+
+	return diff
+}
+
+// reportItems collects the issues and pull requests in every section of
+// report, keeping the first copy of each.
+func reportItems(report *ActivityReport) map[ItemKey]IssueInfo {
+	items := make(map[ItemKey]IssueInfo)
+	if report == nil {
+		return items
+	}
+
+	for repo, activity := range report.RepoActivityReports {
+		if activity == nil {
+			continue
+		}
+		sections := [][]IssueInfo{
+			activity.Issues,
+			activity.PullRequests,
+			activity.ClosedIssues,
+			activity.MergedPullRequests,
+			activity.Stale,
+			activity.ApprovedUnmerged,
+			activity.NeedsReview,
+		}
+		for _, section := range sections {
+			for _, item := range section {
+				key := ItemKey{Repo: repo, Number: item.Number}
+				if _, ok := items[key]; !ok {
+					items[key] = item
+				}
+			}
+		}
+	}
+
+	return items
+}
+
+// changedFields names the fields that differ between two copies of an item.
+func changedFields(old, new IssueInfo) []string {
+	var fields []string
+	if old.Status != new.Status {
+		fields = append(fields, "status")
+	}
+	if old.Title != new.Title {
+		fields = append(fields, "title")
+	}
+	if old.Draft != new.Draft {
+		fields = append(fields, "draft")
+	}
+	if labelList(old.Labels) != labelList(new.Labels) {
+		fields = append(fields, "labels")
+	}
+	if authorList(old.Assignees) != authorList(new.Assignees) {
+		fields = append(fields, "assignees")
+	}
+	if milestoneTitle(old.Milestone) != milestoneTitle(new.Milestone) {
+		fields = append(fields, "milestone")
+	}
+	if old.ReviewStatus != new.ReviewStatus {
+		fields = append(fields, "review")
+	}
+	if old.ChecksStatus != new.ChecksStatus {
+		fields = append(fields, "checks")
+	}
+
+	return fields
+}
+
+// labelList joins the sorted names of labels, for comparison.
+func labelList(labels []Label) string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+// authorList joins the sorted logins of users, for comparison.
+func authorList(users []IssueAuthor) string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.DisplayName
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
+
+// milestoneTitle is the title of m, or empty for no milestone.
+func milestoneTitle(m *Milestone) string {
+	if m == nil {
+		return ""
+	}
+
+	return m.Title
+}
+
+// sortByNumber orders items by number.
+func sortByNumber(items []IssueInfo) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Number < items[j].Number
+	})
+}
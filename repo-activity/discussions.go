@@ -0,0 +1,210 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// discussionsGraphQLQuery lists a repo's discussions, newest first. The REST
+// API doesn't list discussions, so they're only available through GraphQL.
+const discussionsGraphQLQuery = `query($owner: String!, $name: String!, $first: Int!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: $first, after: $after, orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+      nodes {
+        databaseId
+        number
+        title
+        url
+        closed
+        createdAt
+        updatedAt
+        closedAt
+        author { __typename login url }
+        authorAssociation
+        body
+        category { name }
+        comments { totalCount }
+        reactions { totalCount }
+        thumbsUp: reactions(content: THUMBS_UP) { totalCount }
+      }
+    }
+  }
+}`
+
+type graphQLDiscussionsResponse struct {
+	Data struct {
+		Repository struct {
+			Discussions struct {
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []graphQLDiscussion `json:"nodes"`
+			} `json:"discussions"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+func (r *graphQLDiscussionsResponse) graphQLErrors() []graphQLError {
+	return r.Errors
+}
+
+type graphQLDiscussion struct {
+	DatabaseID        int64         `json:"databaseId"`
+	Number            int           `json:"number"`
+	Title             string        `json:"title"`
+	URL               string        `json:"url"`
+	Closed            bool          `json:"closed"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	UpdatedAt         time.Time     `json:"updatedAt"`
+	ClosedAt          time.Time     `json:"closedAt"`
+	Author            *graphQLActor `json:"author"`
+	AuthorAssociation string        `json:"authorAssociation"`
+	Body              string        `json:"body"`
+	Category          struct {
+		Name string `json:"name"`
+	} `json:"category"`
+	Comments struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"comments"`
+	Reactions struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"reactions"`
+	ThumbsUp struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"thumbsUp"`
+}
+
+// addDiscussions fills in each repo's Discussions with the discussions
+// created within the report window, newest first, and counts them in
+// TotalDiscussions. Lookups run Concurrency repos at a time, and a repo that
+// fails is recorded in the report's Errors.
+func (ghra *GitHubRepoActivityService) addDiscussions(ctx context.Context, report *ActivityReport) error {
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+
+	errs, err := ghra.eachRepo(ctx, report.Repos(), func(ctx context.Context, repo string) error {
+		owner, name, ok := splitRepo(repo)
+		if !ok {
+			return nil
+		}
+		r := report.RepoActivityReports[repo]
+
+		discussions, err := ghra.fetchDiscussions(ctx, owner, name, since, until)
+		if err != nil {
+			return fmt.Errorf("listing discussions: %w", err)
+		}
+
+		for i := range discussions {
+			discussions[i].Repo = repo
+		}
+		r.Discussions = ghra.filterBots(discussions)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.addErrors(errs)
+
+	for _, r := range report.RepoActivityReports {
+		report.TotalDiscussions += len(r.Discussions)
+	}
+
+	return nil
+}
+
+// fetchDiscussions lists a repo's discussions created between since and
+// until. Their category is given as their only label.
+func (ghra *GitHubRepoActivityService) fetchDiscussions(ctx context.Context, owner, name string, since, until time.Time) ([]IssueInfo, error) {
+	variables := map[string]interface{}{
+		"owner": owner,
+		"name":  name,
+		"first": ghra.perPage(),
+	}
+
+	discussions := []IssueInfo{}
+	for {
+		var result graphQLDiscussionsResponse
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			return ghra.doGraphQL(ctx, discussionsGraphQLQuery, variables, &result)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Discussions are listed newest first, so once a page ends before
+		// the window there's nothing more to find.
+		page := result.Data.Repository.Discussions
+		older := false
+		for _, node := range page.Nodes {
+			if node.CreatedAt.Before(since) {
+				older = true
+				continue
+			}
+			if node.CreatedAt.After(until) {
+				continue
+			}
+			discussions = append(discussions, ghra.discussionInfo(node))
+		}
+
+		if older || !page.PageInfo.HasNextPage {
+			return discussions, nil
+		}
+		variables["after"] = page.PageInfo.EndCursor
+	}
+}
+
+// discussionInfo converts a GraphQL discussion to an IssueInfo, with its
+// category in place of labels.
+func (ghra *GitHubRepoActivityService) discussionInfo(node graphQLDiscussion) IssueInfo {
+	status := StateOpen
+	if node.Closed {
+		status = StateClosed
+	}
+
+	var labels []Label
+	if node.Category.Name != "" {
+		labels = []Label{{Name: node.Category.Name}}
+	}
+
+	age := ghra.age(node.CreatedAt)
+
+	return IssueInfo{
+		ID:     node.DatabaseID,
+		Number: node.Number,
+		Title:  node.Title,
+		Author: graphQLAuthor(node.Author),
+		URL:    node.URL,
+		Status: status,
+		Age:    ghra.formatAge(node.CreatedAt),
+
+		AgeDuration: age,
+		AgeSeconds:  int64(age.Seconds()),
+		New:         true,
+
+		AuthorAssociation: node.AuthorAssociation,
+		FirstTime:         firstTime(node.AuthorAssociation),
+		BodyExcerpt:       excerpt(node.Body, ghra.excerptLength()),
+
+		Labels:   labels,
+		Comments: node.Comments.TotalCount,
+		Reactions: Reactions{
+			TotalCount: node.Reactions.TotalCount,
+			PlusOne:    node.ThumbsUp.TotalCount,
+		},
+
+		CreatedAt: node.CreatedAt,
+		UpdatedAt: node.UpdatedAt,
+		ClosedAt:  node.ClosedAt,
+	}
+}
@@ -0,0 +1,196 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// enterpriseItem returns searchItem as GitHub Enterprise Server at
+// github.example.com reports it.
+func enterpriseItem(repo string, number int, pr bool) string {
+	return strings.ReplaceAll(searchItem(repo, number, pr), "https://api.github.com/", "https://github.example.com/api/v3/")
+}
+
+// enterpriseServer serves githubHandler under /api/v3, like GitHub
+// Enterprise Server, and records the path of every request.
+func enterpriseServer(t *testing.T, items map[string][]string) (*httptest.Server, func() []string) {
+	h := http.StripPrefix("/api/v3", githubHandler(t, items, nil))
+
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestEnterpriseEndpoint(t *testing.T) {
+	srv, paths := enterpriseServer(t, map[string][]string{
+		"acme/core": {enterpriseItem("acme/core", 1, false), enterpriseItem("acme/core", 2, true)},
+	})
+
+	tests := []struct {
+		name       string
+		endpoint   string
+		upload     string
+		wantUpload string
+	}{
+		{
+			name:       "upload endpoint defaults to the API endpoint",
+			endpoint:   srv.URL + "/api/v3/",
+			wantUpload: srv.URL + "/api/v3/",
+		},
+		{
+			name:       "no trailing slash",
+			endpoint:   srv.URL + "/api/v3",
+			wantUpload: srv.URL + "/api/v3/",
+		},
+		{
+			name:       "upload endpoint",
+			endpoint:   srv.URL + "/api/v3/",
+			upload:     srv.URL + "/api/uploads",
+			wantUpload: srv.URL + "/api/uploads/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Repos:                   []string{"acme/core"},
+				DaysOld:                 7,
+				SearchRequestsPerMinute: -1,
+				APIEndpoint:             tt.endpoint,
+				APIUploadEndpoint:       tt.upload,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := s.client.BaseURL.String(), srv.URL+"/api/v3/"; got != want {
+				t.Errorf("BaseURL = %s, want %s", got, want)
+			}
+			if got := s.client.UploadURL.String(); got != tt.wantUpload {
+				t.Errorf("UploadURL = %s, want %s", got, tt.wantUpload)
+			}
+
+			report, err := s.BuildReportContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := report.RepoActivityReports["acme/core"]
+			if r == nil {
+				t.Fatalf("report has repos %v, want acme/core", report.RepoActivityReports)
+			}
+			for _, item := range append(r.Issues, r.PullRequests...) {
+				if item.Repo != "acme/core" {
+					t.Errorf("#%d Repo = %q, want acme/core", item.Number, item.Repo)
+				}
+			}
+			if got := fmt.Sprint(numbers(r.Issues), numbers(r.PullRequests)); got != "[1] [2]" {
+				t.Errorf("issues and pull requests = %s, want [1] [2]", got)
+			}
+		})
+	}
+
+	for _, p := range paths() {
+		if p != "/api/v3/search/issues" {
+			t.Errorf("requested %s, want /api/v3/search/issues", p)
+		}
+	}
+}
+
+func TestRepoName(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue github.Issue
+		want  string
+	}{
+		{
+			name:  "github.com",
+			issue: github.Issue{RepositoryURL: github.String("https://api.github.com/repos/acme/core")},
+			want:  "acme/core",
+		},
+		{
+			name:  "GitHub Enterprise",
+			issue: github.Issue{RepositoryURL: github.String("https://github.example.com/api/v3/repos/acme/core")},
+			want:  "acme/core",
+		},
+		{
+			name: "embedded repository",
+			issue: github.Issue{
+				RepositoryURL: github.String("https://github.example.com/api/v3/repos/acme/core"),
+				Repository:    &github.Repository{FullName: github.String("acme/renamed")},
+			},
+			want: "acme/renamed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repoName(tt.issue); got != tt.want {
+				t.Errorf("repoName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnterpriseReportUsesConfiguredRepoNames(t *testing.T) {
+	// The API reports repos in their canonical case, whatever case they
+	// were searched for in.
+	srv, _ := enterpriseServer(t, map[string][]string{
+		"Acme/Core": {enterpriseItem("acme/core", 1, false), enterpriseItem("acme/core", 2, true)},
+		"acme/docs": {enterpriseItem("acme/docs", 3, false)},
+	})
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"Acme/Core", "acme/docs"},
+		DaysOld:                 7,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/api/v3/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.BuildReportContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"Acme/Core": "[1] [2]",
+		"acme/docs": "[3] []",
+	}
+	if len(report.RepoActivityReports) != len(want) {
+		t.Errorf("report has %d repos, want %d", len(report.RepoActivityReports), len(want))
+	}
+	for repo, w := range want {
+		r := report.RepoActivityReports[repo]
+		if r == nil {
+			t.Errorf("report is missing %s", repo)
+			continue
+		}
+		if got := fmt.Sprint(numbers(r.Issues), numbers(r.PullRequests)); got != w {
+			t.Errorf("%s issues and pull requests = %s, want %s", repo, got, w)
+		}
+		for _, item := range append(r.Issues, r.PullRequests...) {
+			if item.Repo != repo {
+				t.Errorf("#%d Repo = %q, want %q", item.Number, item.Repo, repo)
+			}
+		}
+	}
+}
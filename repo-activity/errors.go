@@ -0,0 +1,108 @@
+package ghra
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// ErrUnauthorized is returned when GitHub rejects the API token.
+var ErrUnauthorized = errors.New("GitHub rejected the API token")
+
+// ErrInvalidWindow is returned when the report window ends before it starts.
+var ErrInvalidWindow = errors.New("invalid report window")
+
+// ErrInvalidOption is returned when an option has a value that can't be
+// used, such as an unknown State.
+var ErrInvalidOption = errors.New("invalid option")
+
+// ErrRepoNotFound is returned when a repo doesn't exist or isn't visible to
+// the API token.
+type ErrRepoNotFound struct {
+	Repo string
+	Err  error
+}
+
+func (e *ErrRepoNotFound) Error() string {
+	return fmt.Sprintf("repo %s not found or not visible to the token", e.Repo)
+}
+
+func (e *ErrRepoNotFound) Unwrap() error {
+	return e.Err
+}
+
+// ErrRateLimited is returned when the API rate limit was exceeded and
+// requests can't be made again until ResetAt.
+type ErrRateLimited struct {
+	ResetAt time.Time
+	Err     error
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("GitHub rate limit exceeded until %s", e.ResetAt.Format(time.RFC3339))
+}
+
+func (e *ErrRateLimited) Unwrap() error {
+	return e.Err
+}
+
+// ErrRequestTimeout is returned when a search request took longer than
+// RequestTimeout. Query and Page are the search and the page of its results
+// that were being fetched.
+type ErrRequestTimeout struct {
+	Query string
+	Page  int
+	Err   error
+}
+
+func (e *ErrRequestTimeout) Error() string {
+	return fmt.Sprintf("GitHub request timed out fetching page %d of search %q: %v", e.Page, e.Query, e.Err)
+}
+
+func (e *ErrRequestTimeout) Unwrap() error {
+	return e.Err
+}
+
+// isTimeout reports whether err is a request timing out.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// classifyError wraps API errors in the package's typed errors so callers
+// can tell them apart with errors.Is and errors.As.
+func classifyError(err error) error {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return &ErrRateLimited{ResetAt: rateErr.Rate.Reset.Time, Err: err}
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		wait := defaultAbuseRetryAfter
+		if abuseErr.RetryAfter != nil {
+			wait = *abuseErr.RetryAfter
+		}
+		return &ErrRateLimited{ResetAt: time.Now().Add(wait), Err: err}
+	}
+
+	if statusCode(err) == http.StatusUnauthorized {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	return err
+}
+
+// statusCode returns the HTTP status of an API error response, or zero.
+func statusCode(err error) int {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return 0
+	}
+
+	return errResp.Response.StatusCode
+}
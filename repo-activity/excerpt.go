@@ -0,0 +1,51 @@
+package ghra
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultExcerptLength is the number of runes kept from an issue body when
+// ExcerptLength isn't set.
+const defaultExcerptLength = 200
+
+var (
+	codeFencePattern     = regexp.MustCompile("(?s)```.*?(```|$)")
+	markdownImagePattern = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	htmlCommentPattern   = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+func (ghra *GitHubRepoActivityService) excerptLength() int {
+	if ghra.options.ExcerptLength == 0 {
+		return defaultExcerptLength
+	}
+
+	return ghra.options.ExcerptLength
+}
+
+// excerpt returns the start of body as a single line of at most n runes,
+// without code blocks, images, or HTML comments such as those left behind by
+// issue templates. Longer bodies are cut at a word boundary and end with an
+// ellipsis. A negative n disables excerpts.
+func excerpt(body string, n int) string {
+	if n < 0 {
+		return ""
+	}
+
+	body = codeFencePattern.ReplaceAllString(body, " ")
+	body = markdownImagePattern.ReplaceAllString(body, " ")
+	body = htmlCommentPattern.ReplaceAllString(body, " ")
+	body = strings.Join(strings.Fields(body), " ")
+
+	if utf8.RuneCountInString(body) <= n {
+		return body
+	}
+
+	cut := string([]rune(body)[:n])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
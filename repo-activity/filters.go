@@ -0,0 +1,112 @@
+package ghra
+
+import "strings"
+
+// qualifiers returns the search qualifiers narrowing a query for issueType
+// beyond the repos and dates.
+func (ghra *GitHubRepoActivityService) qualifiers(issueType string) []string {
+	q := ghra.baseQualifiers(issueType)
+	if bots := ghra.botQualifiers(); len(bots) > 0 && ghra.botsFit(issueType, q, bots) {
+		q = append(q, bots...)
+	}
+
+	return q
+}
+
+// baseQualifiers returns the qualifiers from qualifiers that are always part
+// of the query.
+func (ghra *GitHubRepoActivityService) baseQualifiers(issueType string) []string {
+	var q []string
+	if ghra.options.BaseBranch != "" && issueType == "pr" {
+		q = append(q, "base:"+ghra.options.BaseBranch)
+	}
+	for _, l := range ghra.options.ExcludeLabels {
+		q = append(q, "-label:"+quoteQualifier(l))
+	}
+	for _, a := range ghra.options.Authors {
+		q = append(q, "author:"+quoteQualifier(a))
+	}
+	for _, a := range ghra.options.ExcludeAuthors {
+		q = append(q, "-author:"+quoteQualifier(a))
+	}
+	if u := ghra.options.InvolvesUser; u != "" {
+		q = append(q, "involves:"+quoteQualifier(u))
+	}
+	switch m := ghra.options.Milestone; m {
+	case "":
+	case MilestoneNone:
+		q = append(q, "no:milestone")
+	case MilestoneAny:
+		q = append(q, "-no:milestone")
+	default:
+		q = append(q, "milestone:"+quoteQualifier(m))
+	}
+	if extra := strings.Join(strings.Fields(ghra.options.ExtraQuery), " "); extra != "" {
+		q = append(q, extra)
+	}
+
+	return q
+}
+
+// filterPullRequests drops the pull requests excluded by the options that
+// can't be expressed as search qualifiers. A combined issue and pull request
+// search can't use base: either, so BaseBranch is checked here as well.
+func (ghra *GitHubRepoActivityService) filterPullRequests(prs []IssueInfo) []IssueInfo {
+	base := ghra.options.BaseBranch
+	if !ghra.options.OnlyUnreviewed && !ghra.options.OnlyFailingChecks && base == "" {
+		return prs
+	}
+
+	filtered := prs[:0]
+	for _, pr := range prs {
+		if ghra.options.OnlyUnreviewed && pr.ReviewStatus != ReviewNone {
+			continue
+		}
+		if ghra.options.OnlyFailingChecks && pr.ChecksStatus != ChecksFailure {
+			continue
+		}
+		if base != "" && pr.BaseRef != "" && pr.BaseRef != base {
+			continue
+		}
+		filtered = append(filtered, pr)
+	}
+
+	return filtered
+}
+
+// Special values of GitHubRepoActivityOptions.Milestone.
+const (
+	// MilestoneNone matches items without a milestone.
+	MilestoneNone = "none"
+	// MilestoneAny matches items with any milestone.
+	MilestoneAny = "*"
+)
+
+// States for GitHubRepoActivityOptions.State.
+const (
+	StateAll    = "all"
+	StateOpen   = "open"
+	StateClosed = "closed"
+)
+
+// validState reports whether state is one of the State values, where empty
+// means StateAll.
+func validState(state string) bool {
+	switch state {
+	case "", StateAll, StateOpen, StateClosed:
+		return true
+	}
+
+	return false
+}
+
+// stateQualifier returns the is: qualifier for State, or an empty string for
+// StateAll.
+func (ghra *GitHubRepoActivityService) stateQualifier() string {
+	switch ghra.options.State {
+	case StateOpen, StateClosed:
+		return "is:" + ghra.options.State
+	}
+
+	return ""
+}
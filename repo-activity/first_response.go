@@ -0,0 +1,107 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func (ghra *GitHubRepoActivityService) includeFirstResponse() bool {
+	return ghra.options.IncludeFirstResponse || ghra.options.OnlyUnanswered
+}
+
+// enrichIssues looks up when each issue was first responded to, as enabled
+// by IncludeFirstResponse, at the cost of at least one extra API request per
+// issue. Lookups run Concurrency at a time. An issue that can't be looked up
+// is left without a first response, and the error is returned keyed by its
+// repo for the report's Errors.
+func (ghra *GitHubRepoActivityService) enrichIssues(ctx context.Context, issues []IssueInfo) (map[string]error, error) {
+	if !ghra.includeFirstResponse() {
+		return nil, nil
+	}
+
+	return ghra.eachItem(ctx, issues, func(ctx context.Context, issue *IssueInfo, owner, name string) error {
+		at, err := ghra.fetchFirstResponse(ctx, owner, name, issue.Number, issue.Author.DisplayName)
+		if err != nil {
+			return fmt.Errorf("listing comments on #%d: %w", issue.Number, err)
+		}
+		ghra.setFirstResponse(issue, at)
+
+		return nil
+	})
+}
+
+// fetchFirstResponse returns when the issue was first commented on by
+// someone other than its author, ignoring bots, or nil if nobody has.
+func (ghra *GitHubRepoActivityService) fetchFirstResponse(ctx context.Context, owner, name string, number int, author string) (*time.Time, error) {
+	opt := &github.IssueListCommentsOptions{
+		Sort:        "created",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{PerPage: maxPerPage},
+	}
+
+	for {
+		var comments []*github.IssueComment
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			comments, resp, err = ghra.client.Issues.ListComments(ctx, owner, name, number, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range comments {
+			login := c.GetUser().GetLogin()
+			if strings.EqualFold(login, author) || isBot(login) {
+				continue
+			}
+			at := c.GetCreatedAt()
+			return &at, nil
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// setFirstResponse records when the issue was first responded to, and
+// whether it has gone unanswered for longer than ResponseSLA.
+func (ghra *GitHubRepoActivityService) setFirstResponse(i *IssueInfo, at *time.Time) {
+	i.FirstResponseAt = at
+	if at != nil {
+		latency := at.Sub(i.CreatedAt)
+		if latency < 0 {
+			latency = 0
+		}
+		i.FirstResponseLatency = &latency
+		return
+	}
+
+	sla := ghra.options.ResponseSLA
+	i.ResponseOverdue = sla > 0 && i.Status == "open" && ghra.age(i.CreatedAt) > sla
+}
+
+// filterIssues drops the issues excluded by OnlyUnanswered.
+func (ghra *GitHubRepoActivityService) filterIssues(issues []IssueInfo) []IssueInfo {
+	if !ghra.options.OnlyUnanswered {
+		return issues
+	}
+
+	filtered := issues[:0]
+	for _, i := range issues {
+		if i.FirstResponseAt == nil {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,26 @@
+package ghra
+
+// firstTime reports whether an author association marks the author's first
+// contribution to the repo, or to GitHub at all.
+func firstTime(association string) bool {
+	return association == "FIRST_TIME_CONTRIBUTOR" || association == "FIRST_TIMER"
+}
+
+// newContributors fills in the report's NewContributors from each repo's
+// issues and pull requests opened during the period by first-time
+// contributors.
+func (report *ActivityReport) newContributors() {
+	report.NewContributors = nil
+	for _, repo := range report.Repos() {
+		r := report.RepoActivityReports[repo]
+		r.NewContributors = nil
+		for _, items := range [][]IssueInfo{r.Issues, r.PullRequests} {
+			for _, i := range items {
+				if i.FirstTime && i.New {
+					r.NewContributors = append(r.NewContributors, i)
+				}
+			}
+		}
+		report.NewContributors = append(report.NewContributors, r.NewContributors...)
+	}
+}
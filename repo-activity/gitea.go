@@ -0,0 +1,582 @@
+package ghra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GiteaPrefix marks the entries of a repo list that name a repo on a Gitea
+// or Forgejo instance, such as "gitea:owner/repo". Gitea repos are keyed by
+// their prefixed name in reports, so they can't clash with GitHub repos.
+const GiteaPrefix = "gitea:"
+
+// giteaPageSize is the most items Gitea returns per page with its default
+// configuration.
+const giteaPageSize = 50
+
+type GiteaRepoActivityOptions struct {
+	// Repos are the "owner/repo" names of the repos to report on, without
+	// GiteaPrefix.
+	Repos []string
+
+	// DaysOld, Since, Until, Timezone, ActivityMode, State, IncludeClosed,
+	// IncludeMerged, SortBy, SortOrder, AgeFormat, AnonymizeAuthors,
+	// AnonymizeSalt, IgnoreItems, and Now work as in
+	// GitHubRepoActivityOptions.
+	DaysOld          int
+	Since            time.Time
+	Until            time.Time
+	Timezone         *time.Location
+	ActivityMode     string
+	State            string
+	IncludeClosed    bool
+	IncludeMerged    bool
+	SortBy           string
+	SortOrder        string
+	AgeFormat        string
+	AnonymizeAuthors bool
+	AnonymizeSalt    string
+	IgnoreItems      []string
+	Now              func() time.Time
+
+	// APIEndpoint is the URL of the Gitea or Forgejo API, such as
+	// "https://git.example.com/api/v1/". It is required.
+	APIEndpoint string
+	// Token is an access token with read access to issues and repos.
+	// Public repos can be read without one.
+	Token string
+	// Concurrency is the number of repos fetched in parallel. It defaults
+	// to 3.
+	Concurrency int
+
+	// Log receives debug output about the requests made. It defaults to
+	// discarding everything.
+	Log log.FieldLogger
+	// HTTPClient is the client used to make API requests. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// ProxyURL works as in GitHubRepoActivityOptions.
+	ProxyURL string
+}
+
+// GiteaOptions returns options for reporting on the Gitea repos with the
+// same window, filters, and sorting as o. The API endpoint and token are
+// left for the caller to set.
+func (o *GitHubRepoActivityOptions) GiteaOptions(repos []string) *GiteaRepoActivityOptions {
+	return &GiteaRepoActivityOptions{
+		Repos:            repos,
+		DaysOld:          o.DaysOld,
+		Since:            o.Since,
+		Until:            o.Until,
+		Timezone:         o.Timezone,
+		ActivityMode:     o.ActivityMode,
+		State:            o.State,
+		IncludeClosed:    o.IncludeClosed,
+		IncludeMerged:    o.IncludeMerged,
+		SortBy:           o.SortBy,
+		SortOrder:        o.SortOrder,
+		AgeFormat:        o.AgeFormat,
+		Now:              o.Now,
+		AnonymizeAuthors: o.AnonymizeAuthors,
+		AnonymizeSalt:    o.AnonymizeSalt,
+		IgnoreItems:      o.IgnoreItems,
+		Concurrency:      o.Concurrency,
+		Log:              o.Log,
+		HTTPClient:       o.HTTPClient,
+		ProxyURL:         o.ProxyURL,
+	}
+}
+
+// GiteaRepoActivityService builds activity reports for repos on a Gitea or
+// Forgejo instance in the same shape as GitHubRepoActivityService.
+type GiteaRepoActivityService struct {
+	options  *GiteaRepoActivityOptions
+	endpoint *url.URL
+	client   *http.Client
+
+	// shared computes the report window, item ages, and per-repo summaries
+	// the same way as for GitHub reports.
+	shared *GitHubRepoActivityService
+}
+
+var _ RepoActivityService = &GiteaRepoActivityService{}
+
+// NewGiteaRepoActivityService initializes a service from options. It
+// returns an error wrapping ErrInvalidOption if options.APIEndpoint isn't
+// an absolute URL, options.State or the sort order isn't known, or
+// options.ProxyURL can't be used.
+func NewGiteaRepoActivityService(options *GiteaRepoActivityOptions) (*GiteaRepoActivityService, error) {
+	if !validState(options.State) {
+		return nil, fmt.Errorf("%w: state %q must be %q, %q, or %q", ErrInvalidOption, options.State, StateAll, StateOpen, StateClosed)
+	}
+	if !validSort(options.SortBy, options.SortOrder) {
+		return nil, fmt.Errorf("%w: can't sort by %q in %q order", ErrInvalidOption, options.SortBy, options.SortOrder)
+	}
+	if err := validateItemRefs(options.IgnoreItems); err != nil {
+		return nil, err
+	}
+	for _, repo := range options.Repos {
+		if err := ValidateRepo(repo); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := options.APIEndpoint
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("%w: Gitea API endpoint %q must be an absolute URL", ErrInvalidOption, options.APIEndpoint)
+	}
+
+	client, err := proxyClient(options.HTTPClient, options.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GiteaRepoActivityService{
+		options:  options,
+		endpoint: u,
+		client:   client,
+		shared: &GitHubRepoActivityService{
+			options: &GitHubRepoActivityOptions{
+				DaysOld:       options.DaysOld,
+				Since:         options.Since,
+				Until:         options.Until,
+				Timezone:      options.Timezone,
+				ActivityMode:  options.ActivityMode,
+				AgeFormat:     options.AgeFormat,
+				Concurrency:   options.Concurrency,
+				Log:           options.Log,
+				Now:           options.Now,
+				AnonymizeSalt: options.AnonymizeSalt,
+				IgnoreItems:   options.IgnoreItems,
+			},
+			lookups: &lookups{},
+		},
+	}, nil
+}
+
+// giteaUser is the author or assignee of a Gitea issue or pull request.
+type giteaUser struct {
+	Login   string `json:"login"`
+	HTMLURL string `json:"html_url"`
+}
+
+// giteaItem is the part of a Gitea issue or pull request the report needs.
+// Pull requests are listed as issues with PullRequest set.
+type giteaItem struct {
+	ID        int64       `json:"id"`
+	Number    int         `json:"number"`
+	Title     string      `json:"title"`
+	Body      string      `json:"body"`
+	State     string      `json:"state"`
+	HTMLURL   string      `json:"html_url"`
+	User      *giteaUser  `json:"user"`
+	Assignees []giteaUser `json:"assignees"`
+	Labels    []struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	Comments    int `json:"comments"`
+	PullRequest *struct {
+		Merged   bool       `json:"merged"`
+		MergedAt *time.Time `json:"merged_at"`
+		Draft    bool       `json:"draft"`
+	} `json:"pull_request"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+// BuildQuery returns the query parameters used to list the issues, for
+// issueType "issue", or the pull requests, for "pr", of every repo. Both
+// are listed the same way.
+func (gt *GiteaRepoActivityService) BuildQuery(issueType string) string {
+	return gt.windowParams().Encode()
+}
+
+// FetchIssues is a wrapper around FetchIssuesContext using context.Background.
+//
+// Deprecated: use ListIssues.
+func (gt *GiteaRepoActivityService) FetchIssues(issueType string) (*[]IssueInfo, error) {
+	return gt.FetchIssuesContext(context.Background(), issueType)
+}
+
+// FetchIssuesContext is like ListIssues, but returns a pointer to the items.
+//
+// Deprecated: use ListIssues.
+func (gt *GiteaRepoActivityService) FetchIssuesContext(ctx context.Context, issueType string) (*[]IssueInfo, error) {
+	items, err := gt.ListIssues(ctx, issueType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &items, nil
+}
+
+// ListIssues returns the issues, for issueType "issue", or the pull
+// requests, for "pr", created or updated during the report window across
+// every repo. It returns a FetchError if any repo couldn't be fetched.
+func (gt *GiteaRepoActivityService) ListIssues(ctx context.Context, issueType string) ([]IssueInfo, error) {
+	var (
+		mu    sync.Mutex
+		items []IssueInfo
+	)
+	errs, err := gt.shared.eachRepo(ctx, gt.options.Repos, func(ctx context.Context, repo string) error {
+		found, err := gt.fetchWindow(ctx, repo, issueType)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		items = append(items, found...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, &FetchError{Errors: prefixRepos(GiteaPrefix, errs)}
+	}
+
+	return items, nil
+}
+
+// BuildReport is a wrapper around BuildReportContext using context.Background.
+//
+// Deprecated: use BuildReportContext.
+func (gt *GiteaRepoActivityService) BuildReport() (*ActivityReport, error) {
+	return gt.BuildReportContext(context.Background())
+}
+
+// BuildReportContext builds a report of the issues and pull requests
+// created or updated during the report window in each repo, and with
+// IncludeClosed and IncludeMerged those closed and merged during it. A repo
+// that fails is recorded in the report's Errors.
+func (gt *GiteaRepoActivityService) BuildReportContext(ctx context.Context) (*ActivityReport, error) {
+	report := &ActivityReport{
+		RepoActivityReports: make(map[string]*RepoActivityReport),
+		Errors:              make(map[string]error),
+	}
+	var mu sync.Mutex
+
+	errs, err := gt.shared.eachRepo(ctx, gt.options.Repos, func(ctx context.Context, repo string) error {
+		r := &RepoActivityReport{URL: gt.webURL() + "/" + repo}
+
+		var err error
+		if r.Issues, err = gt.fetchWindow(ctx, repo, "issue"); err != nil {
+			return err
+		}
+		if r.PullRequests, err = gt.fetchWindow(ctx, repo, "pr"); err != nil {
+			return err
+		}
+		if gt.options.IncludeClosed {
+			if r.ClosedIssues, err = gt.fetchResolved(ctx, repo, "issue"); err != nil {
+				return err
+			}
+		}
+		if gt.options.IncludeMerged {
+			if r.MergedPullRequests, err = gt.fetchResolved(ctx, repo, "pr"); err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		report.RepoActivityReports[GiteaPrefix+repo] = r
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.addErrors(prefixRepos(GiteaPrefix, errs))
+
+	for _, repo := range gt.options.Repos {
+		report.order = append(report.order, GiteaPrefix+repo)
+	}
+	if len(gt.options.IgnoreItems) > 0 {
+		gt.shared.ignoreItems(report)
+	}
+	if gt.options.AnonymizeAuthors {
+		if err := gt.shared.anonymizeAuthors(report); err != nil {
+			return nil, err
+		}
+	}
+	report.count()
+	report.sortBy(gt.options.SortBy, gt.options.SortOrder)
+	report.authorStats()
+	report.newContributors()
+	gt.shared.stats(report)
+	gt.shared.timeSeries(report)
+	report.GeneratedAt = gt.shared.now()
+
+	return report, nil
+}
+
+// windowParams returns the query parameters listing the issues or pull
+// requests updated since the report window started, or with ActivityUpdated
+// during it, in State.
+func (gt *GiteaRepoActivityService) windowParams() url.Values {
+	since, until := gt.shared.window()
+
+	params := url.Values{}
+	params.Set("since", since.UTC().Format(time.RFC3339))
+	if gt.shared.activityMode() == ActivityUpdated && !until.IsZero() {
+		params.Set("before", until.UTC().Format(time.RFC3339))
+	}
+	state := gt.options.State
+	if state == "" {
+		state = StateAll
+	}
+	params.Set("state", state)
+
+	return params
+}
+
+// fetchWindow lists a repo's issues or pull requests created, or with
+// ActivityUpdated updated, during the report window, in State. Gitea only
+// filters by when items were updated, so those created outside the window
+// are dropped here.
+func (gt *GiteaRepoActivityService) fetchWindow(ctx context.Context, repo, issueType string) ([]IssueInfo, error) {
+	since, until := gt.shared.window()
+	updated := gt.shared.activityMode() == ActivityUpdated
+
+	raw, err := gt.list(ctx, repo, issueType, gt.windowParams())
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]IssueInfo, 0, len(raw))
+	for _, item := range raw {
+		if !updated && (item.CreatedAt.Before(since) || !until.IsZero() && item.CreatedAt.After(until)) {
+			continue
+		}
+		info := gt.issueInfo(repo, item)
+		info.New = !item.CreatedAt.Before(since)
+		items = append(items, info)
+	}
+
+	return items, nil
+}
+
+// fetchResolved lists a repo's issues closed, or pull requests merged,
+// during the report window. Their Age is how long they were open.
+func (gt *GiteaRepoActivityService) fetchResolved(ctx context.Context, repo, issueType string) ([]IssueInfo, error) {
+	since, until := gt.shared.window()
+	if until.IsZero() {
+		until = gt.shared.now()
+	}
+
+	// Items are updated when they're closed or merged, so those updated
+	// since the window started cover every one resolved during it.
+	params := url.Values{}
+	params.Set("since", since.UTC().Format(time.RFC3339))
+	params.Set("state", StateClosed)
+
+	raw, err := gt.list(ctx, repo, issueType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []IssueInfo
+	for _, item := range raw {
+		resolved := item.ClosedAt
+		if issueType == "pr" {
+			if item.PullRequest == nil || !item.PullRequest.Merged {
+				continue
+			}
+			resolved = item.PullRequest.MergedAt
+		}
+		if resolved == nil || resolved.Before(since) || resolved.After(until) {
+			continue
+		}
+
+		info := gt.issueInfo(repo, item)
+		info.AgeDuration = resolved.Sub(item.CreatedAt)
+		info.AgeSeconds = int64(info.AgeDuration.Seconds())
+		info.Age = gt.shared.formatDuration(info.AgeDuration)
+		items = append(items, info)
+	}
+
+	return items, nil
+}
+
+// list fetches every page of a repo's issues, or pull requests for
+// issueType "pr", matching params.
+func (gt *GiteaRepoActivityService) list(ctx context.Context, repo, issueType string, params url.Values) ([]giteaItem, error) {
+	params.Set("type", "issues")
+	if issueType == "pr" {
+		params.Set("type", "pulls")
+	}
+	params.Set("limit", fmt.Sprint(giteaPageSize))
+
+	next := gt.endpoint.ResolveReference(&url.URL{
+		Path:     "repos/" + repo + "/issues",
+		RawQuery: params.Encode(),
+	}).String()
+
+	var items []giteaItem
+	for next != "" {
+		var batch []giteaItem
+		var err error
+		next, err = gt.get(ctx, next, &batch)
+		var gtErr *GiteaError
+		if errors.As(err, &gtErr) && gtErr.Response.StatusCode == http.StatusNotFound {
+			return nil, &ErrRepoNotFound{Repo: GiteaPrefix + repo, Err: err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, batch...)
+	}
+
+	return items, nil
+}
+
+// GiteaError is a Gitea API request that failed with an error status.
+type GiteaError struct {
+	Response *http.Response
+	Message  string
+}
+
+func (e *GiteaError) Error() string {
+	return fmt.Sprintf("GET %s: %d %s", e.Response.Request.URL, e.Response.StatusCode, e.Message)
+}
+
+// get fetches u and decodes the JSON response into v, returning the next
+// page given by the Link header.
+func (gt *GiteaRepoActivityService) get(ctx context.Context, u string, v interface{}) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if gt.options.Token != "" {
+		req.Header.Set("Authorization", "token "+gt.options.Token)
+	}
+
+	gt.shared.logger().WithField("url", u).Debug("fetching from Gitea")
+	resp, err := gt.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &body) == nil && body.Message != "" {
+			msg = body.Message
+		}
+		return "", &GiteaError{Response: resp, Message: msg}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", err
+	}
+
+	return nextLink(resp.Header.Get("Link")), nil
+}
+
+// issueInfo converts a Gitea issue or pull request to an IssueInfo.
+func (gt *GiteaRepoActivityService) issueInfo(repo string, item giteaItem) IssueInfo {
+	status := StateOpen
+	if item.State == "closed" {
+		status = StateClosed
+	}
+	if item.PullRequest != nil && item.PullRequest.Merged {
+		status = StatusMerged
+	}
+
+	labels := make([]Label, 0, len(item.Labels))
+	for _, l := range item.Labels {
+		labels = append(labels, Label{Name: l.Name, Color: l.Color})
+	}
+	assignees := make([]IssueAuthor, 0, len(item.Assignees))
+	for _, a := range item.Assignees {
+		assignees = append(assignees, gt.author(&a))
+	}
+
+	age := gt.shared.age(item.CreatedAt)
+	info := IssueInfo{
+		ID:          item.ID,
+		Number:      item.Number,
+		Title:       item.Title,
+		Author:      gt.author(item.User),
+		Repo:        GiteaPrefix + repo,
+		URL:         item.HTMLURL,
+		Status:      status,
+		Age:         gt.shared.formatDuration(age),
+		AgeDuration: age,
+		AgeSeconds:  int64(age.Seconds()),
+		New:         true,
+		BodyExcerpt: excerpt(item.Body, gt.shared.excerptLength()),
+		Labels:      labels,
+		Assignees:   assignees,
+		Comments:    item.Comments,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+
+		pullRequest: item.PullRequest != nil,
+	}
+	if item.Milestone != nil {
+		info.Milestone = &Milestone{Title: item.Milestone.Title}
+	}
+	if item.ClosedAt != nil {
+		info.ClosedAt = *item.ClosedAt
+	}
+	if item.PullRequest != nil {
+		info.Draft = item.PullRequest.Draft
+		if item.PullRequest.MergedAt != nil {
+			info.MergedAt = *item.PullRequest.MergedAt
+		}
+	}
+
+	return info
+}
+
+// author converts a Gitea user, which is nil for deleted users, to an
+// IssueAuthor linking to their profile on the Gitea host.
+func (gt *GiteaRepoActivityService) author(u *giteaUser) IssueAuthor {
+	if u == nil {
+		return ghost
+	}
+
+	profile := u.HTMLURL
+	if profile == "" {
+		profile = gt.webURL() + "/" + u.Login
+	}
+
+	return IssueAuthor{DisplayName: u.Login, ProfileURL: profile}
+}
+
+// webURL is the Gitea instance's web URL, derived from APIEndpoint.
+func (gt *GiteaRepoActivityService) webURL() string {
+	u := *gt.endpoint
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v1")
+	u.RawPath = ""
+	u.RawQuery = ""
+
+	return strings.TrimSuffix(u.String(), "/")
+}
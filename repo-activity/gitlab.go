@@ -0,0 +1,599 @@
+package ghra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// GitLabPrefix marks the entries of a repo list that name a GitLab project,
+// such as "gitlab:group/project", rather than a GitHub repo. GitLab projects
+// are keyed by their prefixed name in reports, so they can't clash with
+// GitHub repos.
+const GitLabPrefix = "gitlab:"
+
+// defaultGitLabEndpoint is the API of gitlab.com.
+const defaultGitLabEndpoint = "https://gitlab.com/api/v4/"
+
+type GitLabRepoActivityOptions struct {
+	// Projects are the paths of the GitLab projects to report on, such as
+	// "group/subgroup/project", without GitLabPrefix.
+	Projects []string
+
+	// DaysOld, Since, Until, Timezone, ActivityMode, State, IncludeClosed,
+	// IncludeMerged, SortBy, SortOrder, AgeFormat, AnonymizeAuthors,
+	// AnonymizeSalt, IgnoreItems, and Now work as in
+	// GitHubRepoActivityOptions. Merge requests are reported as pull
+	// requests.
+	DaysOld          int
+	Since            time.Time
+	Until            time.Time
+	Timezone         *time.Location
+	ActivityMode     string
+	State            string
+	IncludeClosed    bool
+	IncludeMerged    bool
+	SortBy           string
+	SortOrder        string
+	AgeFormat        string
+	AnonymizeAuthors bool
+	AnonymizeSalt    string
+	IgnoreItems      []string
+	Now              func() time.Time
+
+	// APIEndpoint is the URL of the GitLab API, such as
+	// "https://gitlab.example.com/api/v4/". It defaults to gitlab.com's.
+	APIEndpoint string
+	// Token is a personal, group, or project access token with the
+	// read_api scope. Public projects can be read without one.
+	Token string
+	// Concurrency is the number of projects fetched in parallel. It
+	// defaults to 3.
+	Concurrency int
+
+	// Log receives debug output about the requests made. It defaults to
+	// discarding everything.
+	Log log.FieldLogger
+	// HTTPClient is the client used to make API requests. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// ProxyURL works as in GitHubRepoActivityOptions.
+	ProxyURL string
+}
+
+// GitLabOptions returns options for reporting on the GitLab projects with
+// the same window, filters, and sorting as o. The API endpoint and token are
+// left for the caller to set.
+func (o *GitHubRepoActivityOptions) GitLabOptions(projects []string) *GitLabRepoActivityOptions {
+	return &GitLabRepoActivityOptions{
+		Projects:         projects,
+		DaysOld:          o.DaysOld,
+		Since:            o.Since,
+		Until:            o.Until,
+		Timezone:         o.Timezone,
+		ActivityMode:     o.ActivityMode,
+		State:            o.State,
+		IncludeClosed:    o.IncludeClosed,
+		IncludeMerged:    o.IncludeMerged,
+		SortBy:           o.SortBy,
+		SortOrder:        o.SortOrder,
+		AgeFormat:        o.AgeFormat,
+		Now:              o.Now,
+		AnonymizeAuthors: o.AnonymizeAuthors,
+		AnonymizeSalt:    o.AnonymizeSalt,
+		IgnoreItems:      o.IgnoreItems,
+		Concurrency:      o.Concurrency,
+		Log:              o.Log,
+		HTTPClient:       o.HTTPClient,
+		ProxyURL:         o.ProxyURL,
+	}
+}
+
+// GitLabRepoActivityService builds activity reports for GitLab projects in
+// the same shape as GitHubRepoActivityService, with merge requests in place
+// of pull requests.
+type GitLabRepoActivityService struct {
+	options  *GitLabRepoActivityOptions
+	endpoint *url.URL
+	client   *http.Client
+
+	// shared computes the report window, item ages, and per-repo summaries
+	// the same way as for GitHub reports.
+	shared *GitHubRepoActivityService
+}
+
+var _ RepoActivityService = &GitLabRepoActivityService{}
+
+// NewGitLabRepoActivityService initializes a service from options. It
+// returns an error if options.APIEndpoint isn't an absolute URL, or one
+// wrapping ErrInvalidOption if options.State or the sort order isn't known
+// or options.ProxyURL can't be used.
+func NewGitLabRepoActivityService(options *GitLabRepoActivityOptions) (*GitLabRepoActivityService, error) {
+	if !validState(options.State) {
+		return nil, fmt.Errorf("%w: state %q must be %q, %q, or %q", ErrInvalidOption, options.State, StateAll, StateOpen, StateClosed)
+	}
+	if !validSort(options.SortBy, options.SortOrder) {
+		return nil, fmt.Errorf("%w: can't sort by %q in %q order", ErrInvalidOption, options.SortBy, options.SortOrder)
+	}
+	if err := validateItemRefs(options.IgnoreItems); err != nil {
+		return nil, err
+	}
+
+	endpoint := options.APIEndpoint
+	if endpoint == "" {
+		endpoint = defaultGitLabEndpoint
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAbs() {
+		return nil, fmt.Errorf("GitLab API endpoint %q must be an absolute URL", options.APIEndpoint)
+	}
+
+	client, err := proxyClient(options.HTTPClient, options.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitLabRepoActivityService{
+		options:  options,
+		endpoint: u,
+		client:   client,
+		shared: &GitHubRepoActivityService{
+			options: &GitHubRepoActivityOptions{
+				DaysOld:       options.DaysOld,
+				Since:         options.Since,
+				Until:         options.Until,
+				Timezone:      options.Timezone,
+				ActivityMode:  options.ActivityMode,
+				AgeFormat:     options.AgeFormat,
+				Concurrency:   options.Concurrency,
+				Log:           options.Log,
+				Now:           options.Now,
+				AnonymizeSalt: options.AnonymizeSalt,
+				IgnoreItems:   options.IgnoreItems,
+			},
+			lookups: &lookups{},
+		},
+	}, nil
+}
+
+// gitLabUser is the author, assignee, or merger of a GitLab issue or merge
+// request.
+type gitLabUser struct {
+	Username string `json:"username"`
+	WebURL   string `json:"web_url"`
+}
+
+// gitLabItem is the part of a GitLab issue or merge request the report
+// needs.
+type gitLabItem struct {
+	ID           int64        `json:"id"`
+	IID          int          `json:"iid"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	State        string       `json:"state"`
+	WebURL       string       `json:"web_url"`
+	Author       *gitLabUser  `json:"author"`
+	Assignees    []gitLabUser `json:"assignees"`
+	Labels       []string     `json:"labels"`
+	Comments     int          `json:"user_notes_count"`
+	Upvotes      int          `json:"upvotes"`
+	Downvotes    int          `json:"downvotes"`
+	Draft        bool         `json:"draft"`
+	TargetBranch string       `json:"target_branch"`
+	SourceBranch string       `json:"source_branch"`
+	MergedBy     *gitLabUser  `json:"merged_by"`
+	Milestone    *struct {
+		Title  string `json:"title"`
+		WebURL string `json:"web_url"`
+	} `json:"milestone"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+}
+
+// BuildQuery returns the query parameters used to list the issues, for
+// issueType "issue", or the merge requests, for "pr", of every project.
+func (gl *GitLabRepoActivityService) BuildQuery(issueType string) string {
+	return gl.windowParams(issueType).Encode()
+}
+
+// FetchIssues is a wrapper around FetchIssuesContext using context.Background.
+//
+// Deprecated: use ListIssues.
+func (gl *GitLabRepoActivityService) FetchIssues(issueType string) (*[]IssueInfo, error) {
+	return gl.FetchIssuesContext(context.Background(), issueType)
+}
+
+// FetchIssuesContext is like ListIssues, but returns a pointer to the items.
+//
+// Deprecated: use ListIssues.
+func (gl *GitLabRepoActivityService) FetchIssuesContext(ctx context.Context, issueType string) (*[]IssueInfo, error) {
+	items, err := gl.ListIssues(ctx, issueType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &items, nil
+}
+
+// ListIssues returns the issues, for issueType "issue", or the merge
+// requests, for "pr", created or updated during the report window across
+// every project. It returns a FetchError if any project couldn't be
+// fetched.
+func (gl *GitLabRepoActivityService) ListIssues(ctx context.Context, issueType string) ([]IssueInfo, error) {
+	var (
+		mu    sync.Mutex
+		items []IssueInfo
+	)
+	errs, err := gl.shared.eachRepo(ctx, gl.options.Projects, func(ctx context.Context, project string) error {
+		found, err := gl.fetchWindow(ctx, project, issueType)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		items = append(items, found...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, &FetchError{Errors: prefixRepos(GitLabPrefix, errs)}
+	}
+
+	return items, nil
+}
+
+// BuildReport is a wrapper around BuildReportContext using context.Background.
+//
+// Deprecated: use BuildReportContext.
+func (gl *GitLabRepoActivityService) BuildReport() (*ActivityReport, error) {
+	return gl.BuildReportContext(context.Background())
+}
+
+// BuildReportContext builds a report of the issues and merge requests
+// created or updated during the report window in each project, and with
+// IncludeClosed and IncludeMerged those closed and merged during it. A
+// project that fails is recorded in the report's Errors.
+func (gl *GitLabRepoActivityService) BuildReportContext(ctx context.Context) (*ActivityReport, error) {
+	report := &ActivityReport{
+		RepoActivityReports: make(map[string]*RepoActivityReport),
+		Errors:              make(map[string]error),
+	}
+	var mu sync.Mutex
+
+	errs, err := gl.shared.eachRepo(ctx, gl.options.Projects, func(ctx context.Context, project string) error {
+		r := &RepoActivityReport{URL: strings.TrimSuffix(gl.webURL(), "/") + "/" + project}
+
+		var err error
+		if r.Issues, err = gl.fetchWindow(ctx, project, "issue"); err != nil {
+			return err
+		}
+		if r.PullRequests, err = gl.fetchWindow(ctx, project, "pr"); err != nil {
+			return err
+		}
+		if gl.options.IncludeClosed {
+			if r.ClosedIssues, err = gl.fetchResolved(ctx, project, "issue"); err != nil {
+				return err
+			}
+		}
+		if gl.options.IncludeMerged {
+			if r.MergedPullRequests, err = gl.fetchResolved(ctx, project, "pr"); err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		report.RepoActivityReports[GitLabPrefix+project] = r
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.addErrors(prefixRepos(GitLabPrefix, errs))
+
+	for _, project := range gl.options.Projects {
+		report.order = append(report.order, GitLabPrefix+project)
+	}
+	if len(gl.options.IgnoreItems) > 0 {
+		gl.shared.ignoreItems(report)
+	}
+	if gl.options.AnonymizeAuthors {
+		if err := gl.shared.anonymizeAuthors(report); err != nil {
+			return nil, err
+		}
+	}
+	report.count()
+	report.sortBy(gl.options.SortBy, gl.options.SortOrder)
+	report.authorStats()
+	report.newContributors()
+	gl.shared.stats(report)
+	gl.shared.timeSeries(report)
+	report.GeneratedAt = gl.shared.now()
+
+	return report, nil
+}
+
+// windowParams returns the query parameters listing the issues, for
+// issueType "issue", or merge requests, for "pr", created, or with
+// ActivityUpdated updated, during the report window, in State.
+func (gl *GitLabRepoActivityService) windowParams(issueType string) url.Values {
+	since, until := gl.shared.window()
+	field := "created"
+	if gl.shared.activityMode() == ActivityUpdated {
+		field = "updated"
+	}
+
+	params := url.Values{}
+	params.Set(field+"_after", since.UTC().Format(time.RFC3339))
+	if !until.IsZero() {
+		params.Set(field+"_before", until.UTC().Format(time.RFC3339))
+	}
+	switch gl.options.State {
+	case StateOpen:
+		params.Set("state", "opened")
+	case StateClosed:
+		params.Set("state", "closed")
+		if issueType == "pr" {
+			// Merged merge requests aren't closed ones in GitLab.
+			params.Del("state")
+		}
+	}
+
+	return params
+}
+
+// fetchWindow lists a project's issues or merge requests created, or with
+// ActivityUpdated updated, during the report window, in State.
+func (gl *GitLabRepoActivityService) fetchWindow(ctx context.Context, project, issueType string) ([]IssueInfo, error) {
+	since, _ := gl.shared.window()
+	raw, err := gl.list(ctx, project, issueType, gl.windowParams(issueType))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]IssueInfo, 0, len(raw))
+	for _, item := range raw {
+		info := gl.issueInfo(project, item, issueType == "pr")
+		if gl.options.State == StateClosed && info.Status == StateOpen {
+			continue
+		}
+		info.New = !item.CreatedAt.Before(since)
+		items = append(items, info)
+	}
+
+	return items, nil
+}
+
+// fetchResolved lists a project's issues closed, or merge requests merged,
+// during the report window. Their Age is how long they were open.
+func (gl *GitLabRepoActivityService) fetchResolved(ctx context.Context, project, issueType string) ([]IssueInfo, error) {
+	since, until := gl.shared.window()
+	if until.IsZero() {
+		until = gl.shared.now()
+	}
+
+	// Items are updated when they're closed or merged, so those updated
+	// since the window started cover every one resolved during it.
+	params := url.Values{}
+	params.Set("updated_after", since.UTC().Format(time.RFC3339))
+	params.Set("state", "closed")
+	if issueType == "pr" {
+		params.Set("state", "merged")
+	}
+
+	raw, err := gl.list(ctx, project, issueType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []IssueInfo
+	for _, item := range raw {
+		resolved := item.ClosedAt
+		if issueType == "pr" {
+			resolved = item.MergedAt
+		}
+		if resolved == nil || resolved.Before(since) || resolved.After(until) {
+			continue
+		}
+
+		info := gl.issueInfo(project, item, issueType == "pr")
+		info.AgeDuration = resolved.Sub(item.CreatedAt)
+		info.AgeSeconds = int64(info.AgeDuration.Seconds())
+		info.Age = gl.shared.formatDuration(info.AgeDuration)
+		items = append(items, info)
+	}
+
+	return items, nil
+}
+
+// list fetches every page of a project's issues, or merge requests for
+// issueType "pr", matching params.
+func (gl *GitLabRepoActivityService) list(ctx context.Context, project, issueType string, params url.Values) ([]gitLabItem, error) {
+	kind := "issues"
+	if issueType == "pr" {
+		kind = "merge_requests"
+	}
+	params.Set("per_page", "100")
+
+	var items []gitLabItem
+	for page := "1"; page != ""; {
+		params.Set("page", page)
+		u := gl.endpoint.ResolveReference(&url.URL{
+			Path:     "projects/" + project + "/" + kind,
+			RawPath:  "projects/" + url.PathEscape(project) + "/" + kind,
+			RawQuery: params.Encode(),
+		})
+
+		var batch []gitLabItem
+		next, err := gl.get(ctx, u, &batch)
+		var glErr *GitLabError
+		if errors.As(err, &glErr) && glErr.Response.StatusCode == http.StatusNotFound {
+			return nil, &ErrRepoNotFound{Repo: GitLabPrefix + project, Err: err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, batch...)
+		page = next
+	}
+
+	return items, nil
+}
+
+// GitLabError is a GitLab API request that failed with an error status.
+type GitLabError struct {
+	Response *http.Response
+	Message  string
+}
+
+func (e *GitLabError) Error() string {
+	return fmt.Sprintf("GET %s: %d %s", e.Response.Request.URL, e.Response.StatusCode, e.Message)
+}
+
+// get fetches u and decodes the JSON response into v, returning the next
+// page given by the X-Next-Page header.
+func (gl *GitLabRepoActivityService) get(ctx context.Context, u *url.URL, v interface{}) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if gl.options.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", gl.options.Token)
+	}
+
+	gl.shared.logger().WithField("url", u.String()).Debug("fetching from GitLab")
+	resp, err := gl.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var body struct {
+			Message interface{} `json:"message"`
+			Error   string      `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &body) == nil {
+			if body.Message != nil {
+				msg = fmt.Sprint(body.Message)
+			} else if body.Error != "" {
+				msg = body.Error
+			}
+		}
+		return "", &GitLabError{Response: resp, Message: msg}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", err
+	}
+
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+// issueInfo converts a GitLab issue or merge request to an IssueInfo.
+func (gl *GitLabRepoActivityService) issueInfo(project string, item gitLabItem, mergeRequest bool) IssueInfo {
+	status := StateOpen
+	switch item.State {
+	case "closed":
+		status = StateClosed
+	case "merged":
+		status = StatusMerged
+	}
+
+	labels := make([]Label, 0, len(item.Labels))
+	for _, name := range item.Labels {
+		labels = append(labels, Label{Name: name})
+	}
+	assignees := make([]IssueAuthor, 0, len(item.Assignees))
+	for _, a := range item.Assignees {
+		assignees = append(assignees, gitLabAuthor(&a))
+	}
+
+	age := gl.shared.age(item.CreatedAt)
+	info := IssueInfo{
+		ID:          item.ID,
+		Number:      item.IID,
+		Title:       item.Title,
+		Author:      gitLabAuthor(item.Author),
+		Repo:        GitLabPrefix + project,
+		URL:         item.WebURL,
+		Status:      status,
+		Age:         gl.shared.formatDuration(age),
+		AgeDuration: age,
+		AgeSeconds:  int64(age.Seconds()),
+		New:         true,
+		BodyExcerpt: excerpt(item.Description, gl.shared.excerptLength()),
+		Labels:      labels,
+		Assignees:   assignees,
+		Comments:    item.Comments,
+		Reactions: Reactions{
+			TotalCount: item.Upvotes + item.Downvotes,
+			PlusOne:    item.Upvotes,
+		},
+		Draft:     item.Draft,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+
+		pullRequest: mergeRequest,
+	}
+	if mergeRequest {
+		info.BaseRef = item.TargetBranch
+		info.HeadRef = item.SourceBranch
+	}
+	if item.Milestone != nil {
+		info.Milestone = &Milestone{Title: item.Milestone.Title, URL: item.Milestone.WebURL}
+	}
+	if item.ClosedAt != nil {
+		info.ClosedAt = *item.ClosedAt
+	}
+	if item.MergedAt != nil {
+		info.MergedAt = *item.MergedAt
+	}
+	if item.MergedBy != nil {
+		by := gitLabAuthor(item.MergedBy)
+		info.MergedBy = &by
+	}
+
+	return info
+}
+
+// gitLabAuthor converts a GitLab user, which is nil for deleted users, to
+// an IssueAuthor.
+func gitLabAuthor(u *gitLabUser) IssueAuthor {
+	if u == nil {
+		return ghost
+	}
+
+	return IssueAuthor{DisplayName: u.Username, ProfileURL: u.WebURL}
+}
+
+// webURL is the GitLab instance's web URL, derived from APIEndpoint.
+func (gl *GitLabRepoActivityService) webURL() string {
+	u := *gl.endpoint
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v4")
+	u.RawQuery = ""
+
+	return u.String()
+}
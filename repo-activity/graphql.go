@@ -0,0 +1,549 @@
+package ghra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultGraphQLEndpoint = "https://api.github.com/graphql"
+
+// searchGraphQLQuery searches issues and pull requests with the GraphQL API.
+// Labels, comment counts, and review state come back with each node so no
+// follow-up requests are needed.
+const searchGraphQLQuery = `query($query: String!, $first: Int!, $after: String) {
+  search(query: $query, type: ISSUE, first: $first, after: $after) {
+    issueCount
+    pageInfo {
+      hasNextPage
+      endCursor
+    }
+    nodes {
+      __typename
+      ... on Issue {
+        databaseId
+        number
+        title
+        url
+        state
+        createdAt
+        updatedAt
+        closedAt
+        author { __typename login url }
+        authorAssociation
+        body
+        repository { nameWithOwner }
+        labels(first: 20) { nodes { name color } }
+        assignees(first: 10) { nodes { login url } }
+        milestone { title url dueOn }
+        comments { totalCount }
+        reactions { totalCount }
+        thumbsUp: reactions(content: THUMBS_UP) { totalCount }
+      }
+      ... on PullRequest {
+        databaseId
+        number
+        title
+        url
+        state
+        createdAt
+        updatedAt
+        closedAt
+        author { __typename login url }
+        authorAssociation
+        body
+        repository { nameWithOwner }
+        labels(first: 20) { nodes { name color } }
+        assignees(first: 10) { nodes { login url } }
+        milestone { title url dueOn }
+        comments { totalCount }
+        reactions { totalCount }
+        thumbsUp: reactions(content: THUMBS_UP) { totalCount }
+        mergedAt
+        mergedBy { login url }
+        isDraft
+        baseRefName
+        headRefName
+        commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+        additions
+        deletions
+        changedFiles
+        reviewDecision
+        reviewRequests(first: 20) {
+          nodes {
+            requestedReviewer {
+              __typename
+              ... on User { login url }
+              ... on Team { combinedSlug url }
+            }
+          }
+        }
+        latestReviews(first: 20) { nodes { author { login } state } }
+      }
+    }
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// graphQLResponse is a decoded GraphQL response, whose errors doGraphQL
+// checks.
+type graphQLResponse interface {
+	graphQLErrors() []graphQLError
+}
+
+type graphQLSearchResponse struct {
+	Data struct {
+		Search struct {
+			IssueCount int `json:"issueCount"`
+			PageInfo   struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []graphQLIssue `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+func (r *graphQLSearchResponse) graphQLErrors() []graphQLError {
+	return r.Errors
+}
+
+// graphQLActor is the author of an issue, pull request, or discussion.
+type graphQLActor struct {
+	Typename string `json:"__typename"`
+	Login    string `json:"login"`
+	URL      string `json:"url"`
+}
+
+type graphQLIssue struct {
+	Typename   string        `json:"__typename"`
+	DatabaseID int64         `json:"databaseId"`
+	Number     int           `json:"number"`
+	Title      string        `json:"title"`
+	URL        string        `json:"url"`
+	State      string        `json:"state"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+	ClosedAt   time.Time     `json:"closedAt"`
+	MergedAt   time.Time     `json:"mergedAt"`
+	Author     *graphQLActor `json:"author"`
+	MergedBy   *struct {
+		Login string `json:"login"`
+		URL   string `json:"url"`
+	} `json:"mergedBy"`
+	AuthorAssociation string `json:"authorAssociation"`
+	Body              string `json:"body"`
+	Repository        struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+	Labels struct {
+		Nodes []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Assignees struct {
+		Nodes []struct {
+			Login string `json:"login"`
+			URL   string `json:"url"`
+		} `json:"nodes"`
+	} `json:"assignees"`
+	Milestone *struct {
+		Title string    `json:"title"`
+		URL   string    `json:"url"`
+		DueOn time.Time `json:"dueOn"`
+	} `json:"milestone"`
+	Comments struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"comments"`
+	Reactions struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"reactions"`
+	ThumbsUp struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"thumbsUp"`
+	ReviewDecision string `json:"reviewDecision"`
+	Additions      int    `json:"additions"`
+	Deletions      int    `json:"deletions"`
+	ChangedFiles   int    `json:"changedFiles"`
+	LatestReviews  struct {
+		Nodes []struct {
+			Author *struct {
+				Login string `json:"login"`
+			} `json:"author"`
+			State string `json:"state"`
+		} `json:"nodes"`
+	} `json:"latestReviews"`
+	ReviewRequests struct {
+		Nodes []struct {
+			RequestedReviewer *struct {
+				Typename     string `json:"__typename"`
+				Login        string `json:"login"`
+				CombinedSlug string `json:"combinedSlug"`
+				URL          string `json:"url"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	IsDraft     bool   `json:"isDraft"`
+	BaseRefName string `json:"baseRefName"`
+	HeadRefName string `json:"headRefName"`
+	Commits     struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string `json:"state"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+// graphQLEndpoint returns the GraphQL URL for the configured API. On GitHub
+// Enterprise the REST API lives under /api/v3/ while GraphQL is /api/graphql.
+func (ghra *GitHubRepoActivityService) graphQLEndpoint() string {
+	if ghra.options.GraphQLEndpoint != "" {
+		return ghra.options.GraphQLEndpoint
+	}
+	if ghra.options.APIEndpoint == "" {
+		return defaultGraphQLEndpoint
+	}
+
+	base := strings.TrimSuffix(ghra.options.APIEndpoint, "/")
+	if strings.HasSuffix(base, "/api/v3") {
+		return strings.TrimSuffix(base, "/v3") + "/graphql"
+	}
+
+	return base + "/graphql"
+}
+
+// buildGraphQLReport fetches issues and pull requests together with one
+// search per batch of repos and splits them by type.
+func (ghra *GitHubRepoActivityService) buildGraphQLReport(ctx context.Context) (*ActivityReport, error) {
+	limiter := ghra.itemLimiter()
+	result, err := ghra.fetchLimited(ctx, "", limiter)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, prs := splitPullRequests(result.items)
+	issueErrs, err := ghra.enrichIssues(ctx, issues)
+	if err != nil {
+		return nil, err
+	}
+
+	report := ghra.assembleReport(ghra.filterIssues(issues), ghra.filterPullRequests(prs))
+	if limiter != nil {
+		limiter.apply(report)
+	}
+	report.markTruncated(result.truncated)
+	report.addErrors(result.errors)
+	report.addErrors(issueErrs)
+
+	return report, nil
+}
+
+// searchGraphQL runs a single search query against the GraphQL API, walking
+// every page of results.
+func (ghra *GitHubRepoActivityService) searchGraphQL(ctx context.Context, query string) ([]IssueInfo, int, error) {
+	return collect(ghra.searchGraphQLPages)(ctx, query)
+}
+
+// searchGraphQLPages runs a single search query against the GraphQL API,
+// passing each page of results to page as it arrives.
+func (ghra *GitHubRepoActivityService) searchGraphQLPages(ctx context.Context, query string, page pageFunc) error {
+	variables := map[string]interface{}{
+		"query": query,
+		"first": ghra.perPage(),
+	}
+
+	logger := ghra.logger().WithField("query", query)
+	logger.Debug("searching issues with GraphQL")
+	start := time.Now()
+
+	total := 0
+	items := 0
+	pages := 0
+	for {
+		var result graphQLSearchResponse
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			if err := ghra.throttle(ctx); err != nil {
+				return nil, err
+			}
+			return ghra.observeSearch(ctx, query, pages+1, func() (*github.Response, error) {
+				return ghra.doGraphQL(ctx, searchGraphQLQuery, variables, &result)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		search := result.Data.Search
+		total = search.IssueCount
+		pages++
+		logger.WithFields(log.Fields{
+			"page":  pages,
+			"items": len(search.Nodes),
+		}).Debug("fetched search page")
+		issueList := make([]IssueInfo, 0, len(search.Nodes))
+		for _, node := range search.Nodes {
+			issueList = append(issueList, ghra.issueInfoFromGraphQL(node))
+		}
+		items += len(issueList)
+		if err := page(issueList, total); err != nil {
+			return err
+		}
+
+		if !search.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = search.PageInfo.EndCursor
+	}
+
+	logger.WithFields(log.Fields{
+		"pages":   pages,
+		"items":   items,
+		"total":   total,
+		"elapsed": time.Since(start),
+	}).Debug("search completed")
+
+	return nil
+}
+
+// doGraphQL posts a GraphQL query and decodes the response into v. Non-2xx
+// responses are checked with github.CheckResponse so retries and rate limit
+// handling match the REST API.
+func (ghra *GitHubRepoActivityService) doGraphQL(ctx context.Context, query string, variables map[string]interface{}, v graphQLResponse) (*github.Response, error) {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ghra.graphQLEndpoint(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ghra.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	ghResp := &github.Response{Response: resp, Rate: parseRate(resp)}
+	if err := github.CheckResponse(resp); err != nil {
+		return ghResp, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return ghResp, err
+	}
+	if errs := v.graphQLErrors(); len(errs) > 0 {
+		msgs := make([]string, 0, len(errs))
+		for _, e := range errs {
+			msgs = append(msgs, e.Message)
+		}
+		return ghResp, fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+
+	return ghResp, nil
+}
+
+// issueInfoFromGraphQL converts a GraphQL search node to the same shape
+// produced by the REST search.
+func (ghra *GitHubRepoActivityService) issueInfoFromGraphQL(node graphQLIssue) IssueInfo {
+	author := graphQLAuthor(node.Author)
+
+	var mergedBy *IssueAuthor
+	if node.MergedBy != nil {
+		mergedBy = &IssueAuthor{DisplayName: node.MergedBy.Login, ProfileURL: node.MergedBy.URL}
+	}
+
+	state := strings.ToLower(node.State)
+
+	labels := make([]Label, 0, len(node.Labels.Nodes))
+	for _, l := range node.Labels.Nodes {
+		labels = append(labels, Label{Name: l.Name, Color: l.Color})
+	}
+
+	assignees := make([]IssueAuthor, 0, len(node.Assignees.Nodes))
+	for _, a := range node.Assignees.Nodes {
+		assignees = append(assignees, IssueAuthor{DisplayName: a.Login, ProfileURL: a.URL})
+	}
+
+	var milestone *Milestone
+	if m := node.Milestone; m != nil {
+		milestone = &Milestone{Title: m.Title, URL: m.URL, DueOn: m.DueOn}
+	}
+
+	var closes []IssueRef
+	if node.Typename == "PullRequest" {
+		closes = closingReferences(node.Title+"\n"+node.Body, node.Repository.NameWithOwner, node.URL)
+	}
+
+	reactions := Reactions{
+		TotalCount: node.Reactions.TotalCount,
+		PlusOne:    node.ThumbsUp.TotalCount,
+	}
+
+	var (
+		size         *Size
+		reviewers    []IssueAuthor
+		reviewStatus string
+		checks       string
+	)
+	if node.Typename == "PullRequest" {
+		if ghra.includePRDetails() {
+			size = ghra.prSize(node.Additions, node.Deletions, node.ChangedFiles)
+			reviewers = graphQLRequestedReviewers(node)
+
+			var state string
+			for _, c := range node.Commits.Nodes {
+				if rollup := c.Commit.StatusCheckRollup; rollup != nil {
+					state = rollup.State
+				}
+			}
+			checks = graphQLChecksStatus(state)
+		}
+		if ghra.includeReviewStatus() {
+			reviewStatus = graphQLReviewStatus(node)
+		}
+	}
+
+	age := ghra.age(node.CreatedAt)
+
+	info := IssueInfo{
+		ID:     node.DatabaseID,
+		Number: node.Number,
+		Title:  node.Title,
+		Author: author,
+		Repo:   node.Repository.NameWithOwner,
+		URL:    node.URL,
+		Status: state,
+		Age:    ghra.formatAge(node.CreatedAt),
+
+		AgeDuration: age,
+		AgeSeconds:  int64(age.Seconds()),
+		New:         ghra.createdInWindow(node.CreatedAt),
+
+		Labels:    labels,
+		Assignees: assignees,
+		Milestone: milestone,
+		Comments:  node.Comments.TotalCount,
+		Reactions: reactions,
+		Size:      size,
+		Draft:     node.IsDraft,
+
+		RequestedReviewers: reviewers,
+		ReviewStatus:       reviewStatus,
+		BaseRef:            node.BaseRefName,
+		HeadRef:            node.HeadRefName,
+		ClosesIssues:       closes,
+		ChecksStatus:       checks,
+
+		AuthorAssociation: node.AuthorAssociation,
+		FirstTime:         firstTime(node.AuthorAssociation),
+		BodyExcerpt:       excerpt(node.Body, ghra.excerptLength()),
+
+		CreatedAt:   node.CreatedAt,
+		UpdatedAt:   node.UpdatedAt,
+		ClosedAt:    node.ClosedAt,
+		MergedAt:    node.MergedAt,
+		MergedBy:    mergedBy,
+		pullRequest: node.Typename == "PullRequest",
+	}
+	info.Involvement = ghra.involvement(info, node.Body)
+
+	return info
+}
+
+// graphQLAuthor converts a GraphQL author, substituting the ghost user when
+// it has been deleted.
+func graphQLAuthor(actor *graphQLActor) IssueAuthor {
+	if actor == nil {
+		return ghost
+	}
+
+	author := IssueAuthor{DisplayName: actor.Login, ProfileURL: actor.URL}
+	// GraphQL names bots without the [bot] suffix REST uses.
+	if actor.Typename == "Bot" {
+		author.DisplayName += "[bot]"
+	}
+
+	return author
+}
+
+// graphQLReviewStatus collapses the latest review from each reviewer the same
+// way as the REST reviews.
+func graphQLReviewStatus(node graphQLIssue) string {
+	states := make([]reviewState, 0, len(node.LatestReviews.Nodes))
+	for _, r := range node.LatestReviews.Nodes {
+		reviewer := "ghost"
+		if r.Author != nil {
+			reviewer = r.Author.Login
+		}
+		states = append(states, reviewState{reviewer: reviewer, state: r.State})
+	}
+
+	return reviewStatus(states)
+}
+
+// graphQLRequestedReviewers lists the users and teams asked to review a pull
+// request, naming teams by org/slug like the REST API.
+func graphQLRequestedReviewers(node graphQLIssue) []IssueAuthor {
+	reviewers := make([]IssueAuthor, 0, len(node.ReviewRequests.Nodes))
+	for _, r := range node.ReviewRequests.Nodes {
+		reviewer := r.RequestedReviewer
+		if reviewer == nil {
+			continue
+		}
+
+		name := reviewer.Login
+		if reviewer.Typename == "Team" {
+			name = reviewer.CombinedSlug
+		}
+		reviewers = append(reviewers, IssueAuthor{
+			DisplayName: name,
+			ProfileURL:  reviewer.URL,
+		})
+	}
+
+	return reviewers
+}
+
+// parseRate reads the rate limit headers from resp, which go-github only does
+// for requests it makes itself.
+func parseRate(resp *http.Response) github.Rate {
+	var rate github.Rate
+	if limit := resp.Header.Get("X-RateLimit-Limit"); limit != "" {
+		rate.Limit, _ = strconv.Atoi(limit)
+	}
+	if remaining := resp.Header.Get("X-RateLimit-Remaining"); remaining != "" {
+		rate.Remaining, _ = strconv.Atoi(remaining)
+	}
+	if reset := resp.Header.Get("X-RateLimit-Reset"); reset != "" {
+		if v, _ := strconv.ParseInt(reset, 10, 64); v != 0 {
+			rate.Reset = github.Timestamp{Time: time.Unix(v, 0)}
+		}
+	}
+
+	return rate
+}
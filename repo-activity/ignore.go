@@ -0,0 +1,69 @@
+package ghra
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// itemRef returns the reference to an item in the form used by IgnoreItems,
+// lowercased so references match regardless of case.
+func itemRef(repo string, number int) string {
+	return strings.ToLower(repo + "#" + strconv.Itoa(number))
+}
+
+// validateItemRefs returns an error wrapping ErrInvalidOption for the first
+// of refs that isn't a reference such as "owner/repo#123".
+func validateItemRefs(refs []string) error {
+	for _, ref := range refs {
+		i := strings.LastIndex(ref, "#")
+		if i < 0 {
+			return fmt.Errorf("%w: item %q must be in the form owner/repo#number", ErrInvalidOption, ref)
+		}
+		repo := strings.TrimSpace(ref[:i])
+		if n, err := strconv.Atoi(ref[i+1:]); err != nil || n <= 0 || !strings.Contains(repo, "/") {
+			return fmt.Errorf("%w: item %q must be in the form owner/repo#number", ErrInvalidOption, ref)
+		}
+	}
+
+	return nil
+}
+
+// ignoreItems drops the items listed in IgnoreItems from every section of the
+// report. It runs before the report is counted, so the totals leave them out
+// too.
+func (ghra *GitHubRepoActivityService) ignoreItems(report *ActivityReport) {
+	ignored := make(map[string]bool, len(ghra.options.IgnoreItems))
+	for _, ref := range ghra.options.IgnoreItems {
+		ignored[strings.ToLower(strings.TrimSpace(ref))] = true
+	}
+
+	filter := func(section string, items []IssueInfo) []IssueInfo {
+		kept := items[:0]
+		for _, i := range items {
+			if ignored[itemRef(i.Repo, i.Number)] {
+				ghra.logger().WithFields(log.Fields{
+					"item":    i.Repo + "#" + strconv.Itoa(i.Number),
+					"section": section,
+				}).Debug("ignoring item")
+				continue
+			}
+			kept = append(kept, i)
+		}
+		return kept
+	}
+
+	for _, r := range report.RepoActivityReports {
+		r.Issues = filter("issues", r.Issues)
+		r.PullRequests = filter("pull requests", r.PullRequests)
+		r.ClosedIssues = filter("closed issues", r.ClosedIssues)
+		r.MergedPullRequests = filter("merged pull requests", r.MergedPullRequests)
+		r.Stale = filter("stale", r.Stale)
+		r.ApprovedUnmerged = filter("approved unmerged", r.ApprovedUnmerged)
+		r.NeedsReview = filter("needs review", r.NeedsReview)
+		r.Discussions = filter("discussions", r.Discussions)
+	}
+	report.ReviewQueue = filter("review queue", report.ReviewQueue)
+}
@@ -0,0 +1,61 @@
+package ghra
+
+import "strings"
+
+// The ways InvolvesUser can relate to an item, for IssueInfo.Involvement.
+const (
+	InvolvementAuthor    = "author"
+	InvolvementAssignee  = "assignee"
+	InvolvementMentioned = "mentioned"
+)
+
+// involvement returns how InvolvesUser relates to info, whose body is given
+// in full since BodyExcerpt may cut a mention off.
+func (ghra *GitHubRepoActivityService) involvement(info IssueInfo, body string) []string {
+	user := ghra.options.InvolvesUser
+	if user == "" {
+		return nil
+	}
+
+	var involvement []string
+	if strings.EqualFold(info.Author.DisplayName, user) {
+		involvement = append(involvement, InvolvementAuthor)
+	}
+	for _, a := range info.Assignees {
+		if strings.EqualFold(a.DisplayName, user) {
+			involvement = append(involvement, InvolvementAssignee)
+			break
+		}
+	}
+	if mentions(info.Title+"\n"+body, user) {
+		involvement = append(involvement, InvolvementMentioned)
+	}
+
+	return involvement
+}
+
+// mentions reports whether text @-mentions login. Logins can contain
+// hyphens, so a mention of one login isn't taken for a mention of a shorter
+// login it starts with.
+func mentions(text, login string) bool {
+	text = strings.ToLower(text)
+	mention := "@" + strings.ToLower(login)
+	for i := strings.Index(text, mention); i >= 0; {
+		end := i + len(mention)
+		if (i == 0 || !loginChar(text[i-1])) && (end == len(text) || !loginChar(text[end])) {
+			return true
+		}
+		next := strings.Index(text[end:], mention)
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+
+	return false
+}
+
+// loginChar reports whether c can appear in a GitHub login.
+func loginChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
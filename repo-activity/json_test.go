@@ -0,0 +1,122 @@
+package ghra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites the file with got
+// when the tests are run with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s doesn't match; run go test -update if the change is intended.\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestIssueInfoJSON(t *testing.T) {
+	created := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	merged := created.Add(3 * time.Hour)
+	approved := created.Add(2 * time.Hour)
+
+	items := []IssueInfo{
+		{
+			ID:     1001,
+			Number: 7,
+			Title:  "Fix the crash on start",
+			Author: IssueAuthor{DisplayName: "octocat", ProfileURL: "https://github.com/octocat"},
+			Repo:   "acme/core",
+			URL:    "https://github.com/acme/core/pull/7",
+			Status: StatusMerged,
+			Age:    "1 day",
+
+			AgeDuration: 24 * time.Hour,
+			AgeSeconds:  86400,
+			New:         true,
+
+			StateReason:       "completed",
+			AuthorAssociation: "FIRST_TIME_CONTRIBUTOR",
+			FirstTime:         true,
+			BodyExcerpt:       "The app crashes when…",
+
+			Labels:    []Label{{Name: "bug", Color: "d73a4a"}},
+			Assignees: []IssueAuthor{{DisplayName: "hubot", ProfileURL: "https://github.com/hubot"}},
+			Milestone: &Milestone{Title: "v1.0", URL: "https://github.com/acme/core/milestone/1"},
+			Comments:  3,
+			Reactions: Reactions{TotalCount: 2, PlusOne: 1},
+
+			CreatedAt: created,
+			UpdatedAt: merged,
+			ClosedAt:  merged,
+			MergedAt:  merged,
+
+			RequestedReviewers: []IssueAuthor{{DisplayName: "hubot", ProfileURL: "https://github.com/hubot"}},
+			BaseRef:            "main",
+			HeadRef:            "fix-crash",
+			ChecksStatus:       "success",
+			ClosesIssues:       []IssueRef{{Repo: "acme/core", Number: 5, URL: "https://github.com/acme/core/issues/5"}},
+			ReviewStatus:       "approved",
+			ApprovedAt:         &approved,
+			Involvement:        []string{"author"},
+		},
+		{
+			Author: IssueAuthor{DisplayName: "ghost", ProfileURL: "https://github.com/ghost"},
+			Repo:   "acme/docs",
+		},
+	}
+
+	got, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "issue_info.golden", append(got, '\n'))
+}
+
+func TestActivityReportJSON(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+		"acme/docs": {searchItem("acme/docs", 3, false)},
+	}, nil)
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core", "acme/docs", "acme/idle"},
+		DaysOld:                 7,
+		Now:                     func() time.Time { return now },
+		APIEndpoint:             srv.URL + "/",
+		SearchRequestsPerMinute: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.BuildReportContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := report.WriteSnapshot(&b); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "activity_report.golden", b.Bytes())
+}
@@ -0,0 +1,122 @@
+package ghra
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// quoteQualifier quotes a qualifier value containing characters that would
+// otherwise end it or change its meaning, such as spaces and commas.
+func quoteQualifier(value string) string {
+	if !strings.ContainsAny(value, " \t:,\"") {
+		return value
+	}
+
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// labelQualifiers returns a qualifier for each label in Labels, or a single
+// empty qualifier when no labels are set. GitHub combines label: qualifiers
+// with AND, so each needs a search of its own to match any of the labels.
+func (ghra *GitHubRepoActivityService) labelQualifiers() []string {
+	if len(ghra.options.Labels) == 0 {
+		return []string{""}
+	}
+
+	q := make([]string, 0, len(ghra.options.Labels))
+	for _, l := range ghra.options.Labels {
+		q = append(q, "label:"+quoteQualifier(l))
+	}
+
+	return q
+}
+
+// longest returns the length of the longest of qualifiers.
+func longest(qualifiers []string) int {
+	n := 0
+	for _, q := range qualifiers {
+		if len(q) > n {
+			n = len(q)
+		}
+	}
+
+	return n
+}
+
+// withQualifier returns a search appending qualifier to every query.
+func withQualifier(search searchFunc, qualifier string) searchFunc {
+	if qualifier == "" {
+		return search
+	}
+
+	return func(ctx context.Context, query string) ([]IssueInfo, int, error) {
+		return search(ctx, query+" "+qualifier)
+	}
+}
+
+// withPagedQualifier is withQualifier for paged searches.
+func withPagedQualifier(search pagedSearchFunc, qualifier string) pagedSearchFunc {
+	if qualifier == "" {
+		return search
+	}
+
+	return func(ctx context.Context, query string, page pageFunc) error {
+		return search(ctx, query+" "+qualifier, page)
+	}
+}
+
+// searchLabels collects the items found by eachLabels.
+func (ghra *GitHubRepoActivityService) searchLabels(ctx context.Context, search pagedSearchFunc, issueType, field string, repos []string, since, until time.Time) ([]IssueInfo, bool, error) {
+	items := []IssueInfo{}
+	truncated, err := ghra.eachLabels(ctx, search, issueType, field, repos, since, until, func(i IssueInfo) error {
+		items = append(items, i)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return items, truncated, nil
+}
+
+// eachLabels runs eachWindow once for each of the label qualifiers, passing
+// each item to fn as its page arrives and skipping items already matched by
+// another label.
+func (ghra *GitHubRepoActivityService) eachLabels(ctx context.Context, search pagedSearchFunc, issueType, field string, repos []string, since, until time.Time, fn func(IssueInfo) error) (bool, error) {
+	qualifiers := ghra.labelQualifiers()
+	if len(qualifiers) == 1 {
+		return ghra.eachWindow(ctx, withPagedQualifier(search, qualifiers[0]), issueType, field, repos, since, until, fn)
+	}
+
+	truncated := false
+	seen := make(map[string]bool)
+	for _, q := range qualifiers {
+		t, err := ghra.eachWindow(ctx, withPagedQualifier(search, q), issueType, field, repos, since, until, func(i IssueInfo) error {
+			if seen[i.URL] {
+				return nil
+			}
+			seen[i.URL] = true
+			return fn(i)
+		})
+		if err != nil {
+			return false, err
+		}
+		truncated = truncated || t
+	}
+
+	return truncated, nil
+}
+
+// mergeItems appends the items in found that aren't already in seen, which
+// is keyed by URL.
+func mergeItems(items []IssueInfo, seen map[string]bool, found []IssueInfo) []IssueInfo {
+	for _, i := range found {
+		if !seen[i.URL] {
+			seen[i.URL] = true
+			items = append(items, i)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,198 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ItemLimit records that a repo's list of issues or pull requests was cut
+// short by MaxItemsPerRepo or MaxItems.
+type ItemLimit struct {
+	// Shown is the number of items kept in the list.
+	Shown int `json:"shown"`
+	// Total is the number of matching items found. AtLeast is set when
+	// paging stopped before every match was found, so there may be more.
+	Total   int  `json:"total"`
+	AtLeast bool `json:"at_least,omitempty"`
+}
+
+// errLimitReached stops paging through a search once every repo it covers
+// is full.
+var errLimitReached = errors.New("item limit reached")
+
+// itemLimiter applies MaxItemsPerRepo and MaxItems to the issues and pull
+// requests found while building a report. It is safe for concurrent use.
+type itemLimiter struct {
+	perRepo int
+	total   int
+
+	mu   sync.Mutex
+	kept map[string]int
+	seen map[string]int
+	all  int
+	// stopped holds the repos whose searches stopped paging early.
+	stopped map[string]bool
+}
+
+// itemLimiter returns a limiter for the configured limits, or nil when
+// there are none.
+func (ghra *GitHubRepoActivityService) itemLimiter() *itemLimiter {
+	if ghra.options.MaxItemsPerRepo <= 0 && ghra.options.MaxItems <= 0 {
+		return nil
+	}
+
+	return &itemLimiter{
+		perRepo: ghra.options.MaxItemsPerRepo,
+		total:   ghra.options.MaxItems,
+		kept:    make(map[string]int),
+		seen:    make(map[string]int),
+		stopped: make(map[string]bool),
+	}
+}
+
+// limitKey keys the limiter's counts by the kind of item and its repo.
+func limitKey(pullRequest bool, repo string) string {
+	kind := "issue"
+	if pullRequest {
+		kind = "pr"
+	}
+
+	return kind + " " + strings.ToLower(repo)
+}
+
+// limitKinds returns whether the searches for issueType find issues, pull
+// requests, or both.
+func limitKinds(issueType string) []bool {
+	switch issueType {
+	case "issue":
+		return []bool{false}
+	case "pr":
+		return []bool{true}
+	}
+
+	return []bool{false, true}
+}
+
+// keep records that an item was found and reports whether it fits within
+// the limits.
+func (l *itemLimiter) keep(i IssueInfo) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := limitKey(i.pullRequest, i.Repo)
+	l.seen[key]++
+	if l.total > 0 && l.all >= l.total {
+		return false
+	}
+	if l.perRepo > 0 && l.kept[key] >= l.perRepo {
+		return false
+	}
+	l.kept[key]++
+	l.all++
+
+	return true
+}
+
+// full reports whether no more items of issueType from repos can be kept.
+func (l *itemLimiter) full(issueType string, repos []string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.total > 0 && l.all >= l.total {
+		return true
+	}
+	if l.perRepo <= 0 {
+		return false
+	}
+	for _, repo := range repos {
+		for _, pr := range limitKinds(issueType) {
+			if l.kept[limitKey(pr, repo)] < l.perRepo {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// stop records that the searches of issueType for repos stopped paging
+// early.
+func (l *itemLimiter) stop(issueType string, repos []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, repo := range repos {
+		for _, pr := range limitKinds(issueType) {
+			l.stopped[limitKey(pr, repo)] = true
+		}
+	}
+}
+
+// apply sets IssuesLimit and PullRequestsLimit on the repos that may have
+// had items left out.
+func (l *itemLimiter) apply(report *ActivityReport) {
+	for repo, r := range report.RepoActivityReports {
+		if limit := l.limit(false, repo); limit != nil {
+			r.IssuesLimit = limit
+		}
+		if limit := l.limit(true, repo); limit != nil {
+			r.PullRequestsLimit = limit
+		}
+	}
+}
+
+// limit returns the ItemLimit for a repo's issues or pull requests, or nil
+// if none were left out and its searches weren't stopped early.
+func (l *itemLimiter) limit(pullRequest bool, repo string) *ItemLimit {
+	key := limitKey(pullRequest, repo)
+	if l.seen[key] <= l.kept[key] && !l.stopped[key] {
+		return nil
+	}
+
+	return &ItemLimit{
+		Shown:   l.kept[key],
+		Total:   l.seen[key],
+		AtLeast: l.stopped[key],
+	}
+}
+
+// fetchLimited is fetchIssues with the limiter applied. Paging through a
+// batch of repos stops once every repo in it is full.
+func (ghra *GitHubRepoActivityService) fetchLimited(ctx context.Context, issueType string, limiter *itemLimiter) (*fetchResult, error) {
+	if limiter == nil {
+		return ghra.fetchIssues(ctx, issueType)
+	}
+
+	search := ghra.pagedSearch()
+	since, until := ghra.window()
+	return ghra.fetchBatches(ctx, ghra.batches(issueType), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		items := []IssueInfo{}
+		if limiter.full(issueType, repos) {
+			limiter.stop(issueType, repos)
+			return items, false, nil
+		}
+		truncated, err := ghra.eachLabels(ctx, search, issueType, ghra.activityMode(), repos, since, until, func(i IssueInfo) error {
+			if (ghra.options.ExcludeBots && ghra.isExcludedBot(i.Author.DisplayName)) || ghra.excludedRepo(i.Repo) {
+				return nil
+			}
+			if limiter.keep(i) {
+				items = append(items, i)
+			}
+			if limiter.full(issueType, repos) {
+				return errLimitReached
+			}
+			return nil
+		})
+		if errors.Is(err, errLimitReached) {
+			limiter.stop(issueType, repos)
+			err = nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		return items, truncated, nil
+	})
+}
@@ -0,0 +1,24 @@
+package ghra
+
+import (
+	"io"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// discardLogger is used when no logger is configured.
+var discardLogger = &log.Logger{
+	Out:       io.Discard,
+	Formatter: new(log.TextFormatter),
+	Hooks:     make(log.LevelHooks),
+	Level:     log.PanicLevel,
+}
+
+// logger returns options.Log, or a logger that discards everything.
+func (ghra *GitHubRepoActivityService) logger() log.FieldLogger {
+	if ghra.options.Log != nil {
+		return ghra.options.Log
+	}
+
+	return discardLogger
+}
@@ -0,0 +1,50 @@
+package ghra
+
+import (
+	"sync"
+	"time"
+)
+
+// lookupTTL is how long org members, expanded repo patterns, and repo
+// metadata are reused before they're looked up again.
+const lookupTTL = time.Hour
+
+// lookups caches what a service looks up from GitHub beyond the items in its
+// reports, along with its random anonymize salt. The services made from one with WithOptions share it, so a
+// server looks each up once rather than for every report.
+type lookups struct {
+	// members holds the members of each org, keyed by the lowercased org.
+	membersMu sync.Mutex
+	members   map[string]*memberList
+
+	// expanded holds Repos with their glob patterns expanded, keyed by
+	// repoPatternKey.
+	reposMu  sync.Mutex
+	expanded map[string]*repoList
+
+	// meta holds each repo's RepoMeta, keyed by its lowercased name.
+	metaMu sync.Mutex
+	meta   map[string]*repoMetaEntry
+
+	// salt is the random salt used without AnonymizeSalt.
+	saltMu sync.Mutex
+	salt   string
+}
+
+// memberList holds the lowercased logins of an org's members.
+type memberList struct {
+	logins    map[string]bool
+	fetchedAt time.Time
+}
+
+// repoList holds the repos matched by a set of patterns.
+type repoList struct {
+	repos     []string
+	fetchedAt time.Time
+}
+
+// repoMetaEntry holds a repo's RepoMeta.
+type repoMetaEntry struct {
+	meta      *RepoMeta
+	fetchedAt time.Time
+}
@@ -0,0 +1,67 @@
+package ghra
+
+import "sort"
+
+// MergeReports combines reports built by different services, such as a
+// GitHub and a GitLab report, into one. Repos keep the order of the reports
+// they came from, and the totals, author stats, and new contributors are
+// recomputed across all of them. The options and rate limit are those of
+// the first report that has them, and GeneratedAt the latest.
+func MergeReports(reports ...*ActivityReport) *ActivityReport {
+	merged := &ActivityReport{
+		RepoActivityReports: make(map[string]*RepoActivityReport),
+		Errors:              make(map[string]error),
+	}
+
+	var previous *Totals
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+
+		merged.order = append(merged.order, report.Repos()...)
+		for repo, r := range report.RepoActivityReports {
+			merged.RepoActivityReports[repo] = r
+		}
+		merged.addErrors(report.Errors)
+
+		merged.HiddenOrgMembers += report.HiddenOrgMembers
+		merged.ReviewQueue = append(merged.ReviewQueue, report.ReviewQueue...)
+		for login, n := range report.ReviewerStats {
+			if merged.ReviewerStats == nil {
+				merged.ReviewerStats = make(map[string]int)
+			}
+			merged.ReviewerStats[login] += n
+		}
+		if report.PreviousTotals != nil {
+			if previous == nil {
+				previous = &Totals{}
+			}
+			previous.Issues += report.PreviousTotals.Issues
+			previous.PullRequests += report.PreviousTotals.PullRequests
+		}
+
+		if merged.Options == nil {
+			merged.Options = report.Options
+		}
+		if merged.RateLimit == nil {
+			merged.RateLimit = report.RateLimit
+		}
+		if report.GeneratedAt.After(merged.GeneratedAt) {
+			merged.GeneratedAt = report.GeneratedAt
+		}
+	}
+
+	sort.SliceStable(merged.ReviewQueue, func(i, j int) bool {
+		return merged.ReviewQueue[i].AgeDuration > merged.ReviewQueue[j].AgeDuration
+	})
+	merged.count()
+	if previous != nil {
+		merged.PreviousTotals = previous
+		merged.Deltas = Totals{Issues: merged.TotalIssues, PullRequests: merged.TotalPullRequests}.delta(*previous)
+	}
+	merged.authorStats()
+	merged.newContributors()
+
+	return merged
+}
@@ -0,0 +1,34 @@
+package ghra
+
+import "context"
+
+// addMergedPullRequests searches for the pull requests merged within the
+// report window and adds them to each repo's MergedPullRequests. Their Age is
+// how long they were open before being merged. The same details and filters
+// apply as to new pull requests.
+func (ghra *GitHubRepoActivityService) addMergedPullRequests(ctx context.Context, report *ActivityReport) error {
+	merged, err := ghra.fetchWindow(ctx, "pr", "merged")
+	if err != nil {
+		return err
+	}
+	prErrs, err := ghra.enrichPullRequests(ctx, merged.items)
+	if err != nil {
+		return err
+	}
+
+	prs := ghra.filterPullRequests(merged.items)
+	ghra.canonicalizeRepos(prs)
+	for _, pr := range prs {
+		ghra.setTimeOpen(&pr, pr.MergedAt)
+		if report.RepoActivityReports[pr.Repo] == nil {
+			report.RepoActivityReports[pr.Repo] = &RepoActivityReport{}
+		}
+		report.RepoActivityReports[pr.Repo].MergedPullRequests = append(report.RepoActivityReports[pr.Repo].MergedPullRequests, pr)
+	}
+	report.TotalMerged = len(prs)
+	report.markTruncated(merged.truncated)
+	report.addErrors(merged.errors)
+	report.addErrors(prErrs)
+
+	return nil
+}
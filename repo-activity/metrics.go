@@ -0,0 +1,99 @@
+package ghra
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// Metrics receives observations about the API calls made by the service.
+type Metrics interface {
+	// ObserveSearch is called after every search request with the query,
+	// the page requested, how long the request took, and the rate limit
+	// remaining afterwards, or -1 if it is unknown.
+	ObserveSearch(query string, page int, duration time.Duration, remaining int)
+}
+
+// NopMetrics discards all observations.
+type NopMetrics struct{}
+
+var _ Metrics = NopMetrics{}
+
+// ObserveSearch does nothing.
+func (NopMetrics) ObserveSearch(string, int, time.Duration, int) {}
+
+// CountingMetrics counts search requests and their total duration. It is safe
+// for concurrent use.
+type CountingMetrics struct {
+	mu    sync.Mutex
+	stats MetricsSnapshot
+}
+
+// MetricsSnapshot is a point in time copy of the numbers collected by
+// CountingMetrics.
+type MetricsSnapshot struct {
+	Searches           int           `json:"searches"`
+	SearchDuration     time.Duration `json:"search_duration_ns"`
+	RateLimitRemaining int           `json:"rate_limit_remaining"`
+	LastSearchAt       time.Time     `json:"last_search_at,omitempty"`
+}
+
+var _ Metrics = &CountingMetrics{}
+
+// NewCountingMetrics returns a CountingMetrics with no observations.
+func NewCountingMetrics() *CountingMetrics {
+	return &CountingMetrics{
+		stats: MetricsSnapshot{RateLimitRemaining: -1},
+	}
+}
+
+// ObserveSearch records a search request.
+func (m *CountingMetrics) ObserveSearch(query string, page int, duration time.Duration, remaining int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats.Searches++
+	m.stats.SearchDuration += duration
+	m.stats.LastSearchAt = time.Now()
+	if remaining >= 0 {
+		m.stats.RateLimitRemaining = remaining
+	}
+}
+
+// Snapshot returns the numbers collected so far.
+func (m *CountingMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+// metrics returns options.Metrics, defaulting to NopMetrics.
+func (ghra *GitHubRepoActivityService) metrics() Metrics {
+	if ghra.options.Metrics != nil {
+		return ghra.options.Metrics
+	}
+
+	return NopMetrics{}
+}
+
+// observeSearch times a single search request and reports it to Metrics and
+// to the rate tracker carried by ctx.
+func (ghra *GitHubRepoActivityService) observeSearch(ctx context.Context, query string, page int, fn func() (*github.Response, error)) (*github.Response, error) {
+	start := time.Now()
+	resp, err := fn()
+
+	remaining := -1
+	if resp != nil && resp.Rate.Limit > 0 {
+		remaining = resp.Rate.Remaining
+		trackRate(ctx, resp.Rate)
+	}
+	ghra.metrics().ObserveSearch(query, page, time.Since(start), remaining)
+	if isTimeout(err) {
+		err = &ErrRequestTimeout{Query: query, Page: page, Err: err}
+	}
+
+	return resp, err
+}
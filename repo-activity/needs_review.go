@@ -0,0 +1,39 @@
+package ghra
+
+import "context"
+
+// needsReviewQualifiers narrow a search to the open pull requests without
+// any reviews that are ready for one.
+const needsReviewQualifiers = "is:open review:none draft:false"
+
+// addNeedsReview searches for the open pull requests new or updated within
+// the report window, as selected by ActivityMode, that haven't been reviewed
+// and adds them to each repo's NeedsReview. Drafts are left out.
+func (ghra *GitHubRepoActivityService) addNeedsReview(ctx context.Context, report *ActivityReport) error {
+	search := withPagedQualifier(ghra.pagedSearch(), needsReviewQualifiers)
+
+	since, until := ghra.window()
+	found, err := ghra.fetchBatches(ctx, ghra.batches("pr"), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		return ghra.searchLabels(ctx, search, "pr", ghra.activityMode(), repos, since, until)
+	})
+	if err != nil {
+		return err
+	}
+
+	ghra.canonicalizeRepos(found.items)
+	for _, pr := range found.items {
+		// Older GitHub Enterprise releases don't know the draft: qualifier.
+		if pr.Draft {
+			continue
+		}
+		if report.RepoActivityReports[pr.Repo] == nil {
+			report.RepoActivityReports[pr.Repo] = &RepoActivityReport{}
+		}
+		report.RepoActivityReports[pr.Repo].NeedsReview = append(report.RepoActivityReports[pr.Repo].NeedsReview, pr)
+		report.TotalNeedsReview++
+	}
+	report.markTruncated(found.truncated)
+	report.addErrors(found.errors)
+
+	return nil
+}
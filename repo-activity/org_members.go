@@ -0,0 +1,117 @@
+package ghra
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// orgMembers returns the logins of ExcludeOrgMembers' members, lowercased.
+// The list is reused for lookupTTL by the service and those made from it
+// with WithOptions.
+func (ghra *GitHubRepoActivityService) orgMembers(ctx context.Context) (map[string]bool, error) {
+	org := strings.ToLower(ghra.options.ExcludeOrgMembers)
+	l := ghra.lookups
+	l.membersMu.Lock()
+	defer l.membersMu.Unlock()
+	if list, ok := l.members[org]; ok && ghra.now().Sub(list.fetchedAt) < lookupTTL {
+		return list.logins, nil
+	}
+
+	opt := &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: maxPerPage}}
+	members := make(map[string]bool)
+	for {
+		var users []*github.User
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			users, resp, err = ghra.client.Organizations.ListMembers(ctx, ghra.options.ExcludeOrgMembers, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range users {
+			members[strings.ToLower(u.GetLogin())] = true
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	if l.members == nil {
+		l.members = make(map[string]*memberList)
+	}
+	l.members[org] = &memberList{logins: members, fetchedAt: ghra.now()}
+
+	return members, nil
+}
+
+// hideOrgMembers drops the items opened by members of ExcludeOrgMembers from
+// every section of the report and counts them in HiddenOrgMembers. Concealed
+// members don't appear in the member list unless the token belongs to the
+// org, so items in the org's own repos are also dropped when their author
+// association is MEMBER or OWNER.
+func (ghra *GitHubRepoActivityService) hideOrgMembers(ctx context.Context, report *ActivityReport) error {
+	org := ghra.options.ExcludeOrgMembers
+	members, err := ghra.orgMembers(ctx)
+	if err != nil {
+		return err
+	}
+
+	isMember := func(i IssueInfo) bool {
+		if members[strings.ToLower(i.Author.DisplayName)] {
+			return true
+		}
+		owner, _, _ := splitRepo(i.Repo)
+		return strings.EqualFold(owner, org) && (i.AuthorAssociation == "MEMBER" || i.AuthorAssociation == "OWNER")
+	}
+	filter := func(items []IssueInfo) ([]IssueInfo, int) {
+		kept := items[:0]
+		for _, i := range items {
+			if !isMember(i) {
+				kept = append(kept, i)
+			}
+		}
+		return kept, len(items) - len(kept)
+	}
+
+	for _, r := range report.RepoActivityReports {
+		var hidden int
+		r.Issues, hidden = filter(r.Issues)
+		report.TotalIssues -= hidden
+		report.HiddenOrgMembers += hidden
+
+		r.PullRequests, hidden = filter(r.PullRequests)
+		report.TotalPullRequests -= hidden
+		report.HiddenOrgMembers += hidden
+
+		r.ClosedIssues, hidden = filter(r.ClosedIssues)
+		report.TotalClosedIssues -= hidden
+		report.HiddenOrgMembers += hidden
+
+		r.MergedPullRequests, hidden = filter(r.MergedPullRequests)
+		report.TotalMerged -= hidden
+		report.HiddenOrgMembers += hidden
+
+		r.Stale, hidden = filter(r.Stale)
+		report.TotalStale -= hidden
+		report.HiddenOrgMembers += hidden
+
+		r.ApprovedUnmerged, hidden = filter(r.ApprovedUnmerged)
+		report.TotalApprovedUnmerged -= hidden
+		report.HiddenOrgMembers += hidden
+
+		r.NeedsReview, hidden = filter(r.NeedsReview)
+		report.TotalNeedsReview -= hidden
+		report.HiddenOrgMembers += hidden
+	}
+
+	return nil
+}
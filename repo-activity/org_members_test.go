@@ -0,0 +1,67 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOrgMembersSharedByDerivedServices(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		lists = make(map[string]int)
+	)
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lists[r.URL.Path]++
+		mu.Unlock()
+		fmt.Fprint(w, `[{"login": "Octocat"}]`)
+	}))
+
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	options := GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		DaysOld:                 7,
+		ExcludeOrgMembers:       "acme",
+		Now:                     func() time.Time { return now },
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	}
+	s, err := NewGitHubRepoActivityService(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(org string) {
+		t.Helper()
+		o := options
+		o.ExcludeOrgMembers = org
+		derived, err := s.WithOptions(&o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := derived.BuildReportContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.HiddenOrgMembers != 2 {
+			t.Errorf("%d items hidden, want 2", r.HiddenOrgMembers)
+		}
+	}
+
+	report("acme")
+	report("ACME")
+	report("hubot-org")
+	now = now.Add(lookupTTL)
+	report("acme")
+
+	want := map[string]int{"/orgs/acme/members": 2, "/orgs/hubot-org/members": 1}
+	if fmt.Sprint(lists) != fmt.Sprint(want) {
+		t.Errorf("member lists requested %v, want %v", lists, want)
+	}
+}
@@ -0,0 +1,53 @@
+package ghra
+
+import "strings"
+
+// orgScopePrefix marks the entries of scopes that are orgs rather than repos.
+const orgScopePrefix = "org:"
+
+// scopes returns what the report searches: every repo from Repos followed by
+// every org in Orgs, prefixed with org:. Scopes are batched into queries the
+// same way whichever kind they are.
+func (ghra *GitHubRepoActivityService) scopes() []string {
+	repos := ghra.repos()
+	scopes := make([]string, 0, len(repos)+len(ghra.options.Orgs))
+	scopes = append(scopes, repos...)
+	for _, org := range ghra.options.Orgs {
+		scopes = append(scopes, orgScopePrefix+org)
+	}
+
+	return scopes
+}
+
+// orgScope returns the org named by scope, if it is one.
+func orgScope(scope string) (string, bool) {
+	if !strings.HasPrefix(scope, orgScopePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(scope, orgScopePrefix), true
+}
+
+// scopeQualifiers returns the repo: or org: qualifier for each scope.
+func scopeQualifiers(scopes []string) []string {
+	q := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		if _, ok := orgScope(s); ok {
+			q = append(q, s)
+		} else {
+			q = append(q, "repo:"+s)
+		}
+	}
+
+	return q
+}
+
+// markOrgTruncated marks every repo of org in the report as truncated, since
+// which of them are missing items can't be told.
+func (report *ActivityReport) markOrgTruncated(org string) {
+	for repo, r := range report.RepoActivityReports {
+		if owner, _, _ := splitRepo(repo); strings.EqualFold(owner, org) {
+			r.Truncated = true
+		}
+	}
+}
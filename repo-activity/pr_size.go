@@ -0,0 +1,47 @@
+package ghra
+
+// defaultPRSizeThresholds are the lines changed below which a pull request
+// is small, medium, and large.
+var defaultPRSizeThresholds = []int{50, 250, 1000}
+
+// Size buckets for pull requests.
+const (
+	SizeSmall      = "S"
+	SizeMedium     = "M"
+	SizeLarge      = "L"
+	SizeExtraLarge = "XL"
+)
+
+// Size is the size of a pull request's diff.
+type Size struct {
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	ChangedFiles int `json:"changed_files"`
+	// Bucket is one of SizeSmall, SizeMedium, SizeLarge, or SizeExtraLarge
+	// depending on the number of lines changed.
+	Bucket string `json:"bucket"`
+}
+
+// prSize builds the Size of a pull request, bucketing it by the configured
+// thresholds.
+func (ghra *GitHubRepoActivityService) prSize(additions, deletions, changedFiles int) *Size {
+	thresholds := ghra.options.PRSizeThresholds
+	if len(thresholds) != 3 {
+		thresholds = defaultPRSizeThresholds
+	}
+
+	bucket := SizeExtraLarge
+	for i, b := range []string{SizeSmall, SizeMedium, SizeLarge} {
+		if additions+deletions < thresholds[i] {
+			bucket = b
+			break
+		}
+	}
+
+	return &Size{
+		Additions:    additions,
+		Deletions:    deletions,
+		ChangedFiles: changedFiles,
+		Bucket:       bucket,
+	}
+}
@@ -0,0 +1,136 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// Preflight checks that the API can be reached and the configured
+// credentials are accepted before any report is built. For classic tokens,
+// which list their scopes in the X-OAuth-Scopes header, it also checks that
+// every configured repo is visible when the token lacks the repo scope
+// needed to read private repos. With VerifyRepos, every repo is looked up
+// whatever the token's scopes.
+func (ghra *GitHubRepoActivityService) Preflight(ctx context.Context) error {
+	if err := ghra.preflightToken(ctx); err != nil {
+		return err
+	}
+	if !ghra.options.VerifyRepos {
+		return nil
+	}
+
+	errs := make(map[string]error)
+	for _, name := range ghra.repos() {
+		owner, repo, _ := splitRepo(name)
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			_, resp, err := ghra.client.Repositories.Get(ctx, owner, repo)
+			return resp, err
+		})
+		if statusCode(err) == http.StatusNotFound {
+			errs[name] = &ErrRepoNotFound{Repo: name, Err: err}
+		} else if err != nil {
+			return fmt.Errorf("checking %s: %w", name, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &FetchError{Errors: errs}
+	}
+
+	return nil
+}
+
+// preflightToken checks the credentials for Preflight.
+func (ghra *GitHubRepoActivityService) preflightToken(ctx context.Context) error {
+	if !ghra.authenticated() {
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			_, resp, err := ghra.client.RateLimits(ctx)
+			return resp, err
+		})
+		if err != nil {
+			return fmt.Errorf("GitHub API check failed: %w", err)
+		}
+		return nil
+	}
+
+	resp, err := ghra.call(ctx, func() (*github.Response, error) {
+		_, resp, err := ghra.client.Users.Get(ctx, "")
+		return resp, err
+	})
+	switch code := statusCode(err); {
+	case err == nil:
+	case code == http.StatusForbidden || code == http.StatusNotFound:
+		// App installation tokens can't read /user; fall back to an
+		// endpoint every token can read.
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			_, resp, err := ghra.client.RateLimits(ctx)
+			return resp, err
+		})
+		if err != nil {
+			return fmt.Errorf("GitHub token check failed: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("GitHub token check failed: %w", err)
+	}
+
+	scopes, ok := resp.Header["X-Oauth-Scopes"]
+	if !ok || hasScope(strings.Join(scopes, ","), "repo") {
+		return nil
+	}
+
+	var missing []string
+	// Repos matched by a pattern were listed with the token, so they're
+	// visible by definition.
+	for _, name := range ghra.repos() {
+		owner, repo, ok := splitRepo(name)
+		if !ok {
+			continue
+		}
+
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			_, resp, err := ghra.client.Repositories.Get(ctx, owner, repo)
+			return resp, err
+		})
+		if statusCode(err) == http.StatusNotFound {
+			missing = append(missing, name)
+		} else if err != nil {
+			return fmt.Errorf("checking access to %s: %w", name, err)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the token can't see %s; private repos need a token with the repo scope (token scopes: %q)", strings.Join(missing, ", "), strings.Join(scopes, ","))
+	}
+
+	return nil
+}
+
+// authenticated reports whether any credentials are configured.
+func (ghra *GitHubRepoActivityService) authenticated() bool {
+	o := ghra.options
+	return o.Token != "" || len(o.Tokens) > 0 || o.TokenFile != "" || o.AppID != 0
+}
+
+// hasScope reports whether the comma separated scopes include scope.
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitRepo splits an owner/name repo into its parts.
+func splitRepo(name string) (string, string, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,97 @@
+package ghra
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Totals counts issues and pull requests.
+type Totals struct {
+	Issues       int `json:"issues"`
+	PullRequests int `json:"pull_requests"`
+}
+
+// delta returns the change from previous to t.
+func (t Totals) delta(previous Totals) *Totals {
+	return &Totals{
+		Issues:       t.Issues - previous.Issues,
+		PullRequests: t.PullRequests - previous.PullRequests,
+	}
+}
+
+// previousWindow returns the window of the same length immediately before
+// the report window.
+func (ghra *GitHubRepoActivityService) previousWindow() (time.Time, time.Time) {
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+
+	// Ranges include both ends, so the previous window stops a second
+	// before the report window starts.
+	return since.Add(-until.Sub(since)), since.Add(-time.Second)
+}
+
+// addPreviousTotals counts the issues and pull requests found by the same
+// searches over the previous window, and the change since then, across all
+// repos and for each repo. Filters that need extra lookups, such as
+// OnlyUnreviewed, aren't applied to the previous window.
+func (ghra *GitHubRepoActivityService) addPreviousTotals(ctx context.Context, report *ActivityReport) error {
+	search := ghra.pagedSearch()
+	since, until := ghra.previousWindow()
+	field := ghra.activityMode()
+
+	fetch := func(ctx context.Context, issueType string) (*fetchResult, error) {
+		return ghra.fetchBatches(ctx, ghra.batches(issueType), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+			return ghra.searchLabels(ctx, search, issueType, field, repos, since, until)
+		})
+	}
+
+	var issues, prs *fetchResult
+	group, gctx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		var err error
+		issues, err = fetch(gctx, "issue")
+		return err
+	})
+	group.Go(func() error {
+		var err error
+		prs, err = fetch(gctx, "pr")
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	ghra.canonicalizeRepos(issues.items)
+	ghra.canonicalizeRepos(prs.items)
+
+	previous := make(map[string]*Totals)
+	count := func(repo string) *Totals {
+		if previous[repo] == nil {
+			previous[repo] = &Totals{}
+		}
+		return previous[repo]
+	}
+	for _, i := range issues.items {
+		count(i.Repo).Issues++
+	}
+	for _, pr := range prs.items {
+		count(pr.Repo).PullRequests++
+	}
+
+	for repo, r := range report.RepoActivityReports {
+		p := count(repo)
+		r.PreviousTotals = p
+		r.Deltas = Totals{Issues: r.Counts.NewIssues, PullRequests: r.Counts.NewPRs}.delta(*p)
+	}
+
+	report.PreviousTotals = &Totals{Issues: len(issues.items), PullRequests: len(prs.items)}
+	report.Deltas = Totals{Issues: report.TotalIssues, PullRequests: report.TotalPullRequests}.delta(*report.PreviousTotals)
+	report.addErrors(issues.errors)
+	report.addErrors(prs.errors)
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultProjectStatusField is the single select field of a project holding
+// its items' status, as on GitHub's board templates.
+const defaultProjectStatusField = "Status"
+
+// projectItemsGraphQLQuery lists the issues and pull requests on a Projects
+// v2 board, with the value of their status field. The owner may be an org or
+// a user.
+const projectItemsGraphQLQuery = `query($owner: String!, $number: Int!, $field: String!, $first: Int!, $after: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        items(first: $first, after: $after) {
+          pageInfo {
+            hasNextPage
+            endCursor
+          }
+          nodes {
+            status: fieldValueByName(name: $field) {
+              ... on ProjectV2ItemFieldSingleSelectValue { name }
+            }
+            content {
+              ... on Issue { number repository { nameWithOwner } }
+              ... on PullRequest { number repository { nameWithOwner } }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+type graphQLProjectItemsResponse struct {
+	Data struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				Items struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						Status *struct {
+							Name string `json:"name"`
+						} `json:"status"`
+						Content *struct {
+							Number     int `json:"number"`
+							Repository struct {
+								NameWithOwner string `json:"nameWithOwner"`
+							} `json:"repository"`
+						} `json:"content"`
+					} `json:"nodes"`
+				} `json:"items"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+func (r *graphQLProjectItemsResponse) graphQLErrors() []graphQLError {
+	return r.Errors
+}
+
+// addProjectStatus sets the ProjectStatus of every item in the report that
+// is on the ProjectOwner's ProjectNumber board. A board that can't be read,
+// such as with a token lacking the read:project scope, is logged and leaves
+// the report as it was.
+func (ghra *GitHubRepoActivityService) addProjectStatus(ctx context.Context, report *ActivityReport) error {
+	statuses, err := ghra.fetchProjectStatuses(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		ghra.logger().WithFields(log.Fields{
+			"owner":   ghra.options.ProjectOwner,
+			"project": ghra.options.ProjectNumber,
+			"error":   err,
+		}).Warn("skipping project status, the project couldn't be read")
+		return nil
+	}
+
+	set := func(items []IssueInfo) {
+		for i := range items {
+			items[i].ProjectStatus = statuses[projectItemKey(items[i].Repo, items[i].Number)]
+		}
+	}
+	for _, r := range report.RepoActivityReports {
+		set(r.Issues)
+		set(r.PullRequests)
+		set(r.ClosedIssues)
+		set(r.MergedPullRequests)
+		set(r.Stale)
+		set(r.ApprovedUnmerged)
+		set(r.NeedsReview)
+	}
+	set(report.ReviewQueue)
+
+	return nil
+}
+
+// fetchProjectStatuses returns the status of each issue and pull request on
+// the project, keyed by projectItemKey. Items without a status are left out.
+func (ghra *GitHubRepoActivityService) fetchProjectStatuses(ctx context.Context) (map[string]string, error) {
+	field := ghra.options.ProjectStatusField
+	if field == "" {
+		field = defaultProjectStatusField
+	}
+	variables := map[string]interface{}{
+		"owner":  ghra.options.ProjectOwner,
+		"number": ghra.options.ProjectNumber,
+		"field":  field,
+		"first":  ghra.perPage(),
+	}
+
+	statuses := make(map[string]string)
+	for {
+		var result graphQLProjectItemsResponse
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			return ghra.doGraphQL(ctx, projectItemsGraphQLQuery, variables, &result)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		owner := result.Data.RepositoryOwner
+		if owner == nil || owner.ProjectV2 == nil {
+			return nil, fmt.Errorf("project %d of %s not found", ghra.options.ProjectNumber, ghra.options.ProjectOwner)
+		}
+
+		page := owner.ProjectV2.Items
+		for _, node := range page.Nodes {
+			if node.Content == nil || node.Status == nil || node.Content.Number == 0 {
+				continue
+			}
+			statuses[projectItemKey(node.Content.Repository.NameWithOwner, node.Content.Number)] = node.Status.Name
+		}
+
+		if !page.PageInfo.HasNextPage {
+			return statuses, nil
+		}
+		variables["after"] = page.PageInfo.EndCursor
+	}
+}
+
+// projectItemKey keys project statuses by repo and number.
+func projectItemKey(repo string, number int) string {
+	return fmt.Sprintf("%s#%d", strings.ToLower(repo), number)
+}
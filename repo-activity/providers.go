@@ -0,0 +1,31 @@
+package ghra
+
+import "strings"
+
+// ProviderRepos are the entries of a repo list grouped by the provider
+// hosting them, with their provider prefix trimmed.
+type ProviderRepos struct {
+	GitHub []string
+	GitLab []string
+	Gitea  []string
+}
+
+// SplitRepos separates a list of repos by provider: GitLab projects are
+// prefixed with GitLabPrefix, Gitea and Forgejo repos with GiteaPrefix, and
+// everything else is a GitHub repo.
+func SplitRepos(repos []string) ProviderRepos {
+	var split ProviderRepos
+	for _, repo := range repos {
+		repo = strings.TrimSpace(repo)
+		switch {
+		case strings.HasPrefix(repo, GitLabPrefix):
+			split.GitLab = append(split.GitLab, strings.TrimPrefix(repo, GitLabPrefix))
+		case strings.HasPrefix(repo, GiteaPrefix):
+			split.Gitea = append(split.Gitea, strings.TrimPrefix(repo, GiteaPrefix))
+		default:
+			split.GitHub = append(split.GitHub, repo)
+		}
+	}
+
+	return split
+}
@@ -0,0 +1,61 @@
+package ghra
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxyTransport returns base with its requests sent through proxyURL, or
+// base unchanged if proxyURL is empty. A nil base is http.DefaultTransport,
+// which already uses the proxy set by HTTP_PROXY, HTTPS_PROXY, and NO_PROXY.
+// It returns an error wrapping ErrInvalidOption if proxyURL isn't an http,
+// https, or socks5 URL, or if base isn't an *http.Transport whose proxy can
+// be set.
+func proxyTransport(base http.RoundTripper, proxyURL string) (http.RoundTripper, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if proxyURL == "" {
+		return base, nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: proxy %q must be a URL such as http://proxy.example.com:3128", ErrInvalidOption, proxyURL)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("%w: proxy %q must use http, https, or socks5", ErrInvalidOption, proxyURL)
+	}
+
+	t, ok := base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: can't set a proxy on the HTTP client's %T transport", ErrInvalidOption, base)
+	}
+	t = t.Clone()
+	t.Proxy = http.ProxyURL(u)
+
+	return t, nil
+}
+
+// proxyClient returns client, or http.DefaultClient if it is nil, with its
+// requests sent through proxyURL. The client is copied rather than modified.
+func proxyClient(client *http.Client, proxyURL string) (*http.Client, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if proxyURL == "" {
+		return client, nil
+	}
+
+	transport, err := proxyTransport(client.Transport, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	proxied := *client
+	proxied.Transport = transport
+
+	return &proxied, nil
+}
@@ -0,0 +1,58 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "unauthenticated"},
+		{name: "with a token", token: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The proxy answers for the API itself, which doesn't exist, so
+			// requests only succeed if they go through it.
+			var proxied []string
+			proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				proxied = append(proxied, r.URL.Host+r.URL.Path)
+				w.Write([]byte(`{"total_count": 0, "items": []}`))
+			}))
+			defer proxy.Close()
+
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Token:       tt.token,
+				ProxyURL:    proxy.URL,
+				APIEndpoint: "http://github.invalid/api/v3/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := s.client.Search.Issues(context.Background(), "repo:acme/core", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			want := "github.invalid/api/v3/search/issues"
+			if len(proxied) != 1 || proxied[0] != want {
+				t.Errorf("proxied %q, want %q", proxied, want)
+			}
+		})
+	}
+}
+
+func TestProxyURLInvalid(t *testing.T) {
+	for _, proxyURL := range []string{"proxy.example.com:3128", "ftp://proxy.example.com"} {
+		_, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{ProxyURL: proxyURL})
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("ProxyURL %q: err = %v, want ErrInvalidOption", proxyURL, err)
+		}
+	}
+}
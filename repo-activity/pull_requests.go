@@ -0,0 +1,98 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/github"
+)
+
+// enrichPullRequests fills in the pull request details that search doesn't
+// return, as enabled by IncludePRDetails and IncludeReviewStatus. Details cost
+// two extra API requests per pull request and review status one more; lookups
+// run Concurrency at a time. A pull request that can't be looked up keeps
+// what search returned, and the error is returned keyed by its repo for the
+// report's Errors.
+func (ghra *GitHubRepoActivityService) enrichPullRequests(ctx context.Context, prs []IssueInfo) (map[string]error, error) {
+	includeDetails := ghra.includePRDetails()
+	includeReviews := ghra.includeReviewStatus()
+	if !includeDetails && !includeReviews {
+		return nil, nil
+	}
+
+	return ghra.eachItem(ctx, prs, func(ctx context.Context, pr *IssueInfo, owner, name string) error {
+		if includeDetails {
+			pull, err := ghra.getPullRequest(ctx, owner, name, pr.Number)
+			if err != nil {
+				return fmt.Errorf("getting pull request #%d: %w", pr.Number, err)
+			}
+			pr.Size = ghra.prSize(pull.GetAdditions(), pull.GetDeletions(), pull.GetChangedFiles())
+			pr.RequestedReviewers = pull.requestedReviewers(owner)
+			pr.BaseRef = pull.GetBase().GetRef()
+			pr.HeadRef = pull.GetHead().GetRef()
+			if pull.MergedBy != nil {
+				mergedBy := author(pull.MergedBy)
+				pr.MergedBy = &mergedBy
+			}
+
+			pr.ChecksStatus, err = ghra.fetchChecksStatus(ctx, owner, name, pull.GetHead().GetSHA())
+			if err != nil {
+				return fmt.Errorf("getting checks of pull request #%d: %w", pr.Number, err)
+			}
+		}
+
+		if includeReviews {
+			status, err := ghra.fetchReviewStatus(ctx, owner, name, pr.Number)
+			if err != nil {
+				return fmt.Errorf("listing reviews of pull request #%d: %w", pr.Number, err)
+			}
+			pr.ReviewStatus = status
+		}
+
+		return nil
+	})
+}
+
+// pullRequest is a pull request as returned by the API, with the fields
+// go-github doesn't decode yet.
+type pullRequest struct {
+	github.PullRequest
+	RequestedTeams []struct {
+		Slug    string `json:"slug"`
+		HTMLURL string `json:"html_url"`
+	} `json:"requested_teams"`
+}
+
+// requestedReviewers lists the users and teams asked to review the pull
+// request. Teams belong to the repo's owner.
+func (pull *pullRequest) requestedReviewers(owner string) []IssueAuthor {
+	reviewers := make([]IssueAuthor, 0, len(pull.RequestedReviewers)+len(pull.RequestedTeams))
+	for _, u := range pull.RequestedReviewers {
+		reviewers = append(reviewers, author(u))
+	}
+	for _, t := range pull.RequestedTeams {
+		reviewers = append(reviewers, IssueAuthor{
+			DisplayName: owner + "/" + t.Slug,
+			ProfileURL:  t.HTMLURL,
+		})
+	}
+
+	return reviewers
+}
+
+func (ghra *GitHubRepoActivityService) getPullRequest(ctx context.Context, owner, name string, number int) (*pullRequest, error) {
+	req, err := ghra.client.NewRequest("GET", fmt.Sprintf("repos/%v/%v/pulls/%d", owner, name, number), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pull := &pullRequest{}
+	_, err = ghra.call(ctx, func() (*github.Response, error) {
+		return ghra.client.Do(ctx, req, pull)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pull, nil
+}
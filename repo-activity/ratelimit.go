@@ -0,0 +1,151 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultMaxRateLimitWait is used when RetryOnRateLimit is set without a
+	// MaxRateLimitWait.
+	defaultMaxRateLimitWait = 2 * time.Minute
+
+	// defaultAbuseRetryAfter is used when GitHub's secondary rate limit
+	// response doesn't carry a Retry-After header.
+	defaultAbuseRetryAfter = time.Minute
+
+	// defaultRequestTimeout bounds each API request unless
+	// RequestTimeout or the HTTP client sets another timeout.
+	defaultRequestTimeout = 30 * time.Second
+
+	// maxRateLimitRetries bounds how many times the same page is retried.
+	maxRateLimitRetries = 3
+
+	// defaultSearchRequestsPerMinute stays comfortably below GitHub's limit
+	// of 30 search requests per minute.
+	defaultSearchRequestsPerMinute = 20
+)
+
+// NewSearchLimiter returns a limiter allowing perMinute search requests a
+// minute. Zero uses the default of 20; a negative value disables throttling.
+func NewSearchLimiter(perMinute int) *rate.Limiter {
+	switch {
+	case perMinute < 0:
+		return rate.NewLimiter(rate.Inf, 0)
+	case perMinute == 0:
+		perMinute = defaultSearchRequestsPerMinute
+	}
+
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), 1)
+}
+
+// throttle blocks until the search limiter allows another request.
+func (ghra *GitHubRepoActivityService) throttle(ctx context.Context) error {
+	return ghra.searchLimiter.Wait(ctx)
+}
+
+// rateLimitDelay reports how long to wait before retrying a request that
+// failed with err. It returns false if err is not a rate limit error, retries
+// are disabled, or the wait would exceed MaxRateLimitWait.
+func (ghra *GitHubRepoActivityService) rateLimitDelay(err error) (time.Duration, bool) {
+	if !ghra.options.RetryOnRateLimit {
+		return 0, false
+	}
+
+	var wait time.Duration
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		// Add a second to account for clock skew with the API.
+		wait = time.Until(rateErr.Rate.Reset.Time) + time.Second
+	case errors.As(err, &abuseErr):
+		wait = defaultAbuseRetryAfter
+		if abuseErr.RetryAfter != nil {
+			wait = *abuseErr.RetryAfter
+		}
+	default:
+		return 0, false
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+
+	maxWait := ghra.options.MaxRateLimitWait
+	if maxWait <= 0 {
+		maxWait = defaultMaxRateLimitWait
+	}
+	if wait > maxWait {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimit describes the search rate limit as last seen while building a
+// report.
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// rateTracker records the lowest remaining rate limit seen across the
+// searches made for a single report.
+type rateTracker struct {
+	mu   sync.Mutex
+	rate *RateLimit
+}
+
+type rateTrackerKey struct{}
+
+// withRateTracker returns a context whose searches are recorded by tracker.
+func withRateTracker(ctx context.Context, tracker *rateTracker) context.Context {
+	return context.WithValue(ctx, rateTrackerKey{}, tracker)
+}
+
+// trackRate records rate against the tracker carried by ctx, if any.
+func trackRate(ctx context.Context, rate github.Rate) {
+	tracker, ok := ctx.Value(rateTrackerKey{}).(*rateTracker)
+	if !ok || rate.Limit == 0 {
+		return
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if tracker.rate == nil || rate.Remaining < tracker.rate.Remaining {
+		tracker.rate = &RateLimit{
+			Limit:     rate.Limit,
+			Remaining: rate.Remaining,
+			Reset:     rate.Reset.Time,
+		}
+	}
+}
+
+// RateLimit returns the lowest rate limit recorded, or nil if none was seen.
+func (t *rateTracker) RateLimit() *RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rate
+}
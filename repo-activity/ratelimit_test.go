@@ -0,0 +1,177 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// rateLimitedServer serves two pages of one issue each, failing the first
+// request for page 2 with fail. It records the page of every request.
+func rateLimitedServer(t *testing.T, fail func(w http.ResponseWriter)) (*httptest.Server, *[]string) {
+	var pages []string
+	failed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		if page == "2" && !failed {
+			failed = true
+			fail(w)
+			return
+		}
+
+		n := 1
+		if page == "2" {
+			n = 2
+		} else {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/search/issues?page=2>; rel="next"`, "http://"+r.Host))
+		}
+		fmt.Fprintf(w, `{"total_count": 2, "items": [%s]}`, searchItem("acme/core", n, false))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &pages
+}
+
+func TestSearchRetriesRateLimitedPage(t *testing.T) {
+	rateLimited := func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "API rate limit exceeded for 127.0.0.1."}`)
+	}
+	abused := func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "You have triggered an abuse detection mechanism.", "documentation_url": "https://developer.github.com/v3/#abuse-rate-limits"}`)
+	}
+
+	tests := []struct {
+		name      string
+		fail      func(w http.ResponseWriter)
+		retry     bool
+		maxWait   time.Duration
+		wantPages []string
+		wantErr   interface{}
+	}{
+		{
+			name:      "rate limit with X-RateLimit-Reset",
+			fail:      rateLimited,
+			retry:     true,
+			wantPages: []string{"", "2", "2"},
+		},
+		{
+			name:      "secondary rate limit with Retry-After",
+			fail:      abused,
+			retry:     true,
+			wantPages: []string{"", "2", "2"},
+		},
+		{
+			name:      "retries disabled",
+			fail:      rateLimited,
+			wantPages: []string{"", "2"},
+			wantErr:   new(*github.RateLimitError),
+		},
+		{
+			name:      "reset beyond MaxRateLimitWait",
+			fail:      rateLimited,
+			retry:     true,
+			maxWait:   time.Millisecond,
+			wantPages: []string{"", "2"},
+			wantErr:   new(*github.RateLimitError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, pages := rateLimitedServer(t, tt.fail)
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				PerPage:                 1,
+				RetryOnRateLimit:        tt.retry,
+				MaxRateLimitWait:        tt.maxWait,
+				SearchRequestsPerMinute: -1,
+				APIEndpoint:             srv.URL + "/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, _, err := s.searchIssues(context.Background(), "repo:acme/core")
+			if tt.wantErr != nil {
+				if !errors.As(err, tt.wantErr) {
+					t.Errorf("searchIssues() = %v, want a %T", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if got := fmt.Sprint(numbers(items)); got != "[1 2]" {
+				t.Errorf("items = %s, want [1 2]", got)
+			}
+
+			if got := fmt.Sprintf("%q", *pages); got != fmt.Sprintf("%q", tt.wantPages) {
+				t.Errorf("requested pages %s, want %q", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestSearchLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		perMinute int
+		want      []time.Duration
+	}{
+		{
+			name: "default",
+			want: []time.Duration{0, 3 * time.Second, 6 * time.Second, 9 * time.Second},
+		},
+		{
+			name:      "configured",
+			perMinute: 30,
+			want:      []time.Duration{0, 2 * time.Second, 4 * time.Second, 6 * time.Second},
+		},
+		{
+			name:      "disabled",
+			perMinute: -1,
+			want:      []time.Duration{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reserving at a fixed time stands in for a clock that doesn't
+			// move while the searches are made.
+			now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+			limiter := NewSearchLimiter(tt.perMinute)
+			for i, want := range tt.want {
+				if got := limiter.ReserveN(now, 1).DelayFrom(now); got != want {
+					t.Errorf("search %d waits %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchLimiterShared(t *testing.T) {
+	limiter := NewSearchLimiter(0)
+	options := &GitHubRepoActivityOptions{SearchLimiter: limiter}
+	a, err := NewGitHubRepoActivityService(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewGitHubRepoActivityService(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.searchLimiter != limiter || b.searchLimiter != limiter {
+		t.Error("services don't share the SearchLimiter")
+	}
+}
@@ -0,0 +1,128 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRedisAddr         = "localhost:6379"
+	defaultRedisKeyPrefix    = "github-repo-activity:"
+	defaultRedisTimeout      = 5 * time.Second
+	defaultRedisMaxIdleConns = 4
+)
+
+// RedisOptions configures a RedisCache.
+type RedisOptions struct {
+	// Addr is the host:port of the Redis server. It defaults to
+	// localhost:6379.
+	Addr string
+	// Password, when set, is sent with AUTH on every new connection.
+	Password string
+	// KeyPrefix is prepended to every key so several deployments can share
+	// a server. It defaults to "github-repo-activity:".
+	KeyPrefix string
+	// Timeout bounds connecting and each command. It defaults to five
+	// seconds.
+	Timeout time.Duration
+	// MaxIdleConns is how many connections are kept open between commands.
+	// It defaults to 4.
+	MaxIdleConns int
+
+	// Log receives a warning for every failed command. It defaults to
+	// discarding everything.
+	Log log.FieldLogger
+}
+
+// RedisCache is a Cache stored in Redis. It is safe for concurrent use. A
+// failed command is logged and treated as a miss, so an unavailable server
+// slows reports down but doesn't break them.
+type RedisCache struct {
+	options RedisOptions
+	client  *redis.Client
+}
+
+var _ Cache = &RedisCache{}
+
+// NewRedisCache returns a RedisCache for the server in options. It doesn't
+// connect until the first command; use Ping to check the server is reachable.
+func NewRedisCache(options RedisOptions) *RedisCache {
+	if options.Addr == "" {
+		options.Addr = defaultRedisAddr
+	}
+	if options.KeyPrefix == "" {
+		options.KeyPrefix = defaultRedisKeyPrefix
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultRedisTimeout
+	}
+	if options.MaxIdleConns <= 0 {
+		options.MaxIdleConns = defaultRedisMaxIdleConns
+	}
+	if options.Log == nil {
+		options.Log = discardLogger
+	}
+
+	return &RedisCache{
+		options: options,
+		client: redis.NewClient(&redis.Options{
+			Addr:         options.Addr,
+			Password:     options.Password,
+			DialTimeout:  options.Timeout,
+			ReadTimeout:  options.Timeout,
+			WriteTimeout: options.Timeout,
+			MaxIdleConns: options.MaxIdleConns,
+		}),
+	}
+}
+
+// Get returns the value stored under key if it hasn't expired.
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	value, err := c.client.Get(ctx, c.options.KeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false
+	}
+	if err != nil {
+		c.options.Log.WithError(err).WithField("key", key).Warn("can not get from Redis")
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Set stores value under key for ttl, or until Redis evicts it if ttl is
+// zero.
+func (c *RedisCache) Set(key string, value []byte, ttl time.Duration) {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.options.KeyPrefix+key, value, ttl).Err(); err != nil {
+		c.options.Log.WithError(err).WithField("key", key).Warn("can not set in Redis")
+	}
+}
+
+// Ping checks that the server is reachable and accepts the password.
+func (c *RedisCache) Ping() error {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	return c.client.Ping(ctx).Err()
+}
+
+// Close closes the connections to the server. The cache can't be used
+// afterwards.
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// context returns a context bounding a command to the configured timeout.
+func (c *RedisCache) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.options.Timeout)
+}
@@ -0,0 +1,135 @@
+package ghra
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := NewRedisCache(RedisOptions{Addr: mr.Addr(), KeyPrefix: "test:"})
+	defer c.Close()
+
+	if err := c.Ping(); err != nil {
+		t.Fatalf("Ping() = %v", err)
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get() of a missing key was a hit")
+	}
+
+	c.Set("kept", []byte("forever"), 0)
+	c.Set("expiring", []byte("soon"), time.Minute)
+
+	if got, err := mr.Get("test:kept"); err != nil || got != "forever" {
+		t.Errorf("stored test:kept = %q, %v, want %q", got, err, "forever")
+	}
+	if ttl := mr.TTL("test:kept"); ttl != 0 {
+		t.Errorf("TTL of test:kept = %v, want none", ttl)
+	}
+	if ttl := mr.TTL("test:expiring"); ttl != time.Minute {
+		t.Errorf("TTL of test:expiring = %v, want %v", ttl, time.Minute)
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if value, ok := c.Get("kept"); !ok || string(value) != "forever" {
+		t.Errorf("Get(kept) = %q, %v, want %q, true", value, ok, "forever")
+	}
+	if _, ok := c.Get("expiring"); ok {
+		t.Error("Get(expiring) was a hit after its TTL")
+	}
+}
+
+func TestRedisCacheUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := NewRedisCache(RedisOptions{Addr: mr.Addr(), Timeout: time.Second})
+	defer c.Close()
+	mr.Close()
+
+	if err := c.Ping(); err == nil {
+		t.Error("Ping() of a stopped server succeeded")
+	}
+	c.Set("key", []byte("value"), time.Minute)
+	if _, ok := c.Get("key"); ok {
+		t.Error("Get() from a stopped server was a hit")
+	}
+}
+
+func TestRedisCachePassword(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.RequireAuth("secret")
+
+	for _, tt := range []struct {
+		password string
+		ok       bool
+	}{
+		{"secret", true},
+		{"wrong", false},
+	} {
+		c := NewRedisCache(RedisOptions{Addr: mr.Addr(), Password: tt.password})
+		if err := c.Ping(); (err == nil) != tt.ok {
+			t.Errorf("Ping() with password %q = %v", tt.password, err)
+		}
+		c.Close()
+	}
+}
+
+func TestReportCacheInRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	backend := NewRedisCache(RedisOptions{Addr: mr.Addr()})
+	defer backend.Close()
+
+	// Two caches stand in for two replicas sharing the server.
+	a := NewReportCache(backend, time.Minute)
+	b := NewReportCache(backend, time.Minute)
+
+	a.Set("key", &ActivityReport{
+		RepoActivityReports: map[string]*RepoActivityReport{
+			"acme/core": {Issues: []IssueInfo{{Number: 1, Title: "Crash", Repo: "acme/core"}}},
+		},
+		Errors:      map[string]error{"acme/gone": errors.New("not found")},
+		TotalIssues: 1,
+	})
+
+	report, ok := b.Get("key")
+	if !ok {
+		t.Fatal("report set by one cache wasn't returned by the other")
+	}
+	if report.TotalIssues != 1 || len(report.RepoActivityReports["acme/core"].Issues) != 1 {
+		t.Errorf("report = %+v, want the cached report", report)
+	}
+	if err := report.Errors["acme/gone"]; err == nil || err.Error() != "not found" {
+		t.Errorf("error = %v, want not found", err)
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if _, ok := b.Get("key"); ok {
+		t.Error("report was returned after its TTL")
+	}
+}
+
+func TestResponseCacheInRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	backend := NewRedisCache(RedisOptions{Addr: mr.Addr()})
+	defer backend.Close()
+
+	c := NewResponseCache(backend, time.Hour)
+	c.Set("https://api.github.com/search/issues?q=repo%3Aacme%2Fcore", &CachedResponse{
+		ETag: `"v1"`,
+		Body: []byte(`{"total_count": 0}`),
+	})
+
+	resp, ok := c.Get("https://api.github.com/search/issues?q=repo%3Aacme%2Fcore")
+	if !ok {
+		t.Fatal("cached response wasn't returned")
+	}
+	if resp.ETag != `"v1"` || string(resp.Body) != `{"total_count": 0}` {
+		t.Errorf("response = %+v, want the cached response", resp)
+	}
+	if ttl := mr.TTL(defaultRedisKeyPrefix + responseCacheKeyPrefix + "https://api.github.com/search/issues?q=repo%3Aacme%2Fcore"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want %v", ttl, time.Hour)
+	}
+}
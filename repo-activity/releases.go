@@ -0,0 +1,178 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// ReleaseInfo is a release published during the period, or with IncludeTags
+// a tag without a release.
+type ReleaseInfo struct {
+	TagName     string      `json:"tag_name"`
+	Name        string      `json:"name,omitempty"`
+	URL         string      `json:"url"`
+	Author      IssueAuthor `json:"author"`
+	PublishedAt time.Time   `json:"published_at"`
+	Prerelease  bool        `json:"prerelease,omitempty"`
+	// TagOnly is set for tags without a release. Their PublishedAt is the
+	// date of the tagged commit.
+	TagOnly bool `json:"tag_only,omitempty"`
+}
+
+// addReleases fills in each repo's Releases with the releases published
+// within the report window, newest first. Lookups run Concurrency repos at a
+// time, and a repo that fails is recorded in the report's Errors.
+func (ghra *GitHubRepoActivityService) addReleases(ctx context.Context, report *ActivityReport) error {
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+
+	errs, err := ghra.eachRepo(ctx, report.Repos(), func(ctx context.Context, repo string) error {
+		owner, name, ok := splitRepo(repo)
+		if !ok {
+			return nil
+		}
+		r := report.RepoActivityReports[repo]
+
+		releases, err := ghra.fetchReleases(ctx, owner, name, since, until)
+		if err == nil && ghra.options.IncludeTags {
+			var tags []ReleaseInfo
+			tags, err = ghra.fetchTags(ctx, owner, name, releases, since, until)
+			releases = append(releases, tags...)
+			sort.SliceStable(releases, func(i, j int) bool {
+				return releases[i].PublishedAt.After(releases[j].PublishedAt)
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("listing releases: %w", err)
+		}
+
+		r.Releases = releases
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.addErrors(errs)
+
+	return nil
+}
+
+// fetchReleases lists a repo's releases published between since and until.
+// Drafts aren't published and are left out.
+func (ghra *GitHubRepoActivityService) fetchReleases(ctx context.Context, owner, name string, since, until time.Time) ([]ReleaseInfo, error) {
+	opt := &github.ListOptions{PerPage: maxPerPage}
+
+	var releases []ReleaseInfo
+	for {
+		var page []*github.RepositoryRelease
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			page, resp, err = ghra.client.Repositories.ListReleases(ctx, owner, name, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Releases are listed newest first, so once a page ends before the
+		// window there's nothing more to find.
+		older := false
+		for _, r := range page {
+			published := r.GetPublishedAt().Time
+			if r.GetDraft() || published.IsZero() {
+				continue
+			}
+			if published.Before(since) {
+				older = true
+				continue
+			}
+			if published.After(until) {
+				continue
+			}
+			releases = append(releases, ReleaseInfo{
+				TagName:     r.GetTagName(),
+				Name:        r.GetName(),
+				URL:         r.GetHTMLURL(),
+				Author:      author(r.Author),
+				PublishedAt: published,
+				Prerelease:  r.GetPrerelease(),
+			})
+		}
+
+		if older || resp.NextPage == 0 {
+			return releases, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// fetchTags lists a repo's tags without a release whose commit is dated
+// between since and until. Tags don't have dates of their own, so each
+// one's commit is looked up. Only the first page of tags is checked, which
+// holds the most recent ones for repos with conventionally named tags.
+func (ghra *GitHubRepoActivityService) fetchTags(ctx context.Context, owner, name string, releases []ReleaseInfo, since, until time.Time) ([]ReleaseInfo, error) {
+	released := make(map[string]bool, len(releases))
+	for _, r := range releases {
+		released[r.TagName] = true
+	}
+
+	var tags []*github.RepositoryTag
+	_, err := ghra.call(ctx, func() (*github.Response, error) {
+		var (
+			resp *github.Response
+			err  error
+		)
+		tags, resp, err = ghra.client.Repositories.ListTags(ctx, owner, name, &github.ListOptions{PerPage: maxPerPage})
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ReleaseInfo
+	for _, t := range tags {
+		if released[t.GetName()] {
+			continue
+		}
+
+		var commit *github.RepositoryCommit
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			commit, resp, err = ghra.client.Repositories.GetCommit(ctx, owner, name, t.GetCommit().GetSHA())
+			return resp, err
+		})
+		if statusCode(err) == http.StatusNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		date := commit.GetCommit().GetCommitter().GetDate()
+		if date.Before(since) || date.After(until) {
+			continue
+		}
+		found = append(found, ReleaseInfo{
+			TagName:     t.GetName(),
+			URL:         strings.TrimSuffix(commit.GetHTMLURL(), "/commit/"+commit.GetSHA()) + "/tree/" + t.GetName(),
+			Author:      author(commit.Author),
+			PublishedAt: date,
+			TagOnly:     true,
+		})
+	}
+
+	return found, nil
+}
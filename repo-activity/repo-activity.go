@@ -2,164 +2,1986 @@ package ghra
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/github"
-	"github.com/hako/durafmt"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxPerPage is the largest page size accepted by the GitHub Search API.
+	maxPerPage = 100
+
+	// maxSearchResults is the most results the Search API returns for a
+	// single query, however many match.
+	maxSearchResults = 1000
+
+	// maxQueryLength is the longest search query GitHub accepts; longer
+	// queries fail with a 422.
+	maxQueryLength = 256
+
+	// defaultConcurrency keeps parallel searches well under GitHub's
+	// secondary rate limits.
+	defaultConcurrency = 3
 )
 
 type ActivityReport struct {
+	// RepoActivityReports holds an entry for every configured repo that
+	// was fetched, even those without any activity, along with any others
+	// found through Orgs.
 	RepoActivityReports map[string]*RepoActivityReport
 	TotalIssues         int
 	TotalPullRequests   int
+	// TotalClosedIssues is the number of issues closed during the period,
+	// when IncludeClosed is enabled.
+	TotalClosedIssues int
+	// TotalMerged is the number of pull requests merged during the period,
+	// when IncludeMerged is enabled.
+	TotalMerged int
+	// TotalStale is the number of stale issues and pull requests, when
+	// StaleDays is set.
+	TotalStale int
+	// TotalApprovedUnmerged is the number of open, approved pull requests,
+	// when IncludeApprovedUnmerged is enabled.
+	TotalApprovedUnmerged int
+	// TotalNeedsReview is the number of pull requests waiting for a first
+	// review, when IncludeNeedsReview is enabled.
+	TotalNeedsReview int
+	// TotalDiscussions is the number of discussions created during the
+	// period, when IncludeDiscussions is enabled.
+	TotalDiscussions int
+	// ReviewQueue holds the open pull requests across all repos whose
+	// review is requested from ReviewRequested, those waiting longest
+	// first.
+	ReviewQueue []IssueInfo `json:"review_queue,omitempty"`
+	// HiddenOrgMembers is the number of items left out of the report
+	// because they were opened by members of ExcludeOrgMembers.
+	HiddenOrgMembers int
+	// AuthorStats counts the issues and pull requests each author opened
+	// during the period across all repos, most active first.
+	AuthorStats []AuthorStats `json:"author_stats"`
+	// NewContributors holds the issues and pull requests opened during the
+	// period by first-time contributors, in repo order.
+	NewContributors []IssueInfo `json:"new_contributors"`
+	// ReviewerStats counts the reviews each reviewer submitted during the
+	// period across all repos, keyed by login, when IncludeReviewerStats is
+	// enabled. TopReviewers orders them.
+	ReviewerStats map[string]int `json:"reviewer_stats,omitempty"`
+	// PreviousTotals counts the issues and pull requests from the window of
+	// the same length before the period, and Deltas the change since, when
+	// CompareWithPrevious is enabled.
+	PreviousTotals *Totals `json:"previous_totals,omitempty"`
+	Deltas         *Totals `json:"deltas,omitempty"`
+
+	// Errors holds the repos that couldn't be fetched, keyed by repo. The
+	// rest of the report is still built when some repos fail.
+	Errors map[string]error `json:"-"`
+
+	// GeneratedAt is when the report was built, and Options what it was
+	// built with. Snapshots record both alongside the report.
+	GeneratedAt time.Time      `json:"-"`
+	Options     *ReportOptions `json:"-"`
+
+	// RateLimit is the lowest search rate limit remaining seen while
+	// building the report, or nil if it is unknown.
+	RateLimit *RateLimit
+
+	// order is the configured order of the repos, for Repos.
+	order []string
 }
 
 type RepoActivityReport struct {
 	Issues       []IssueInfo
 	PullRequests []IssueInfo
+	// ClosedIssues holds the issues closed during the period when
+	// IncludeClosed is enabled. Their Age is how long they were open.
+	ClosedIssues []IssueInfo
+	// MergedPullRequests holds the pull requests merged during the period
+	// when IncludeMerged is enabled. Their Age is how long they were open.
+	MergedPullRequests []IssueInfo
+	// Stale holds the open issues and pull requests that haven't been
+	// updated in StaleDays, when it is set. Their Age is the time since they
+	// were last updated.
+	Stale []IssueInfo
+	// ApprovedUnmerged holds the open pull requests that have been approved,
+	// when IncludeApprovedUnmerged is enabled, those waiting longest first.
+	// Their Age is the time since their latest approval.
+	ApprovedUnmerged []IssueInfo `json:"approved_unmerged,omitempty"`
+	// NeedsReview holds the open pull requests from the period that haven't
+	// been reviewed yet, leaving out drafts, when IncludeNeedsReview is
+	// enabled.
+	NeedsReview []IssueInfo `json:"needs_review,omitempty"`
+	// TriageGaps holds the open issues from Issues that are missing a label
+	// or an assignee, as enabled by TriageUnlabeled and TriageUnassigned.
+	TriageGaps []TriageGap
+	// AuthorStats counts the issues and pull requests each author opened in
+	// the repo during the period, most active first.
+	AuthorStats []AuthorStats `json:"author_stats"`
+	// NewContributors holds the repo's issues and pull requests opened
+	// during the period by first-time contributors.
+	NewContributors []IssueInfo `json:"new_contributors"`
+	// ReviewerStats counts the reviews each reviewer submitted on the repo's
+	// pull requests during the period, keyed by login, when
+	// IncludeReviewerStats is enabled.
+	ReviewerStats map[string]int `json:"reviewer_stats,omitempty"`
+	// Counts is the number of items in each section. The report's totals
+	// are the sums of each repo's.
+	Counts Counts `json:"counts"`
+	// Stats holds the repo's time to close and time to merge.
+	Stats Stats `json:"stats"`
+	// TimeSeries counts the issues and pull requests opened on each day of
+	// the period, including days without any.
+	TimeSeries []DailyActivity `json:"time_series"`
+	// Meta describes the repo as a whole, when IncludeRepoMeta is enabled.
+	Meta *RepoMeta `json:"meta,omitempty"`
+	// SecurityAlerts counts the repo's open Dependabot alerts, when
+	// IncludeSecurityAlerts is enabled and they could be read.
+	SecurityAlerts *SecurityAlerts `json:"security_alerts,omitempty"`
+	// Releases holds the releases published during the period, newest
+	// first, when IncludeReleases is enabled.
+	Releases []ReleaseInfo `json:"releases,omitempty"`
+	// CommitStats counts the commits to the default branch during the
+	// period, when IncludeCommits is enabled.
+	CommitStats *CommitStats `json:"commit_stats,omitempty"`
+	// CIStats counts the workflow runs on the default branch during the
+	// period, when IncludeCIStats is enabled.
+	CIStats *CIStats `json:"ci_stats,omitempty"`
+	// Discussions holds the discussions created during the period, newest
+	// first, when IncludeDiscussions is enabled. Each one's category is
+	// given as its only label.
+	Discussions []IssueInfo `json:"discussions,omitempty"`
+	// PreviousTotals and Deltas compare the repo with the previous window,
+	// when CompareWithPrevious is enabled.
+	PreviousTotals *Totals `json:"previous_totals,omitempty"`
+	Deltas         *Totals `json:"deltas,omitempty"`
+
+	// IssuesLimit and PullRequestsLimit are set when MaxItemsPerRepo or
+	// MaxItems left some of the repo's issues or pull requests out.
+	IssuesLimit       *ItemLimit `json:"issues_limit,omitempty"`
+	PullRequestsLimit *ItemLimit `json:"pull_requests_limit,omitempty"`
+
+	// URL is the repo's web page when it isn't on github.com, such as for
+	// GitLab projects.
+	URL string `json:"url,omitempty"`
+
+	// Truncated is set when GitHub's search result cap was hit and some of
+	// the repo's items are missing from the report.
+	Truncated bool
 }
 
+// StatusMerged is the Status of merged pull requests, which GitHub itself
+// reports as closed.
+const StatusMerged = "merged"
+
+// The StateReason of issues, as GitHub reports it.
+const (
+	StateReasonCompleted  = "completed"
+	StateReasonNotPlanned = "not_planned"
+	StateReasonReopened   = "reopened"
+)
+
 type IssueInfo struct {
-	ID     *int64      `json:"id,omitempty"`
-	Number *int        `json:"number,omitempty"`
-	Title  *string     `json:"title"`
+	ID     int64       `json:"id,omitempty"`
+	Number int         `json:"number,omitempty"`
+	Title  string      `json:"title,omitempty"`
 	Author IssueAuthor `json:"author"`
 	Repo   string      `json:"repo"`
-	URL    *string     `json:"url"`
-	Status *string     `json:"status"`
+	URL    string      `json:"url,omitempty"`
+	Status string      `json:"status,omitempty"`
 	Age    string      `json:"age"`
+
+	// AgeDuration is the time since the item was created, and AgeSeconds
+	// the same in whole seconds for JSON. Age formats it per AgeFormat.
+	AgeDuration time.Duration `json:"-"`
+	AgeSeconds  int64         `json:"age_seconds"`
+
+	// New is set when the item was created within the report window rather
+	// than only updated in it, which is always the case with ActivityCreated.
+	New bool `json:"new"`
+	// StateReason is why an issue was closed, StateReasonCompleted or
+	// StateReasonNotPlanned, or StateReasonReopened for reopened issues. It is
+	// empty for pull requests, for GraphQL searches, and when GitHub doesn't
+	// return it, as with older GitHub Enterprise releases.
+	StateReason string `json:"state_reason,omitempty"`
+
+	// AuthorAssociation is the author's relationship to the repo, such as
+	// MEMBER, CONTRIBUTOR, or FIRST_TIME_CONTRIBUTOR.
+	AuthorAssociation string `json:"author_association,omitempty"`
+	// FirstTime is set when AuthorAssociation marks the author's first
+	// contribution, FIRST_TIME_CONTRIBUTOR or FIRST_TIMER.
+	FirstTime bool `json:"first_time,omitempty"`
+	// BodyExcerpt is the start of the issue body as plain text.
+	BodyExcerpt string `json:"body_excerpt,omitempty"`
+
+	Labels    []Label       `json:"labels,omitempty"`
+	Assignees []IssueAuthor `json:"assignees,omitempty"`
+	// Milestone is nil for items without a milestone.
+	Milestone *Milestone `json:"milestone,omitempty"`
+	Comments  int        `json:"comments"`
+	Reactions Reactions  `json:"reactions"`
+	// Draft is set for draft pull requests.
+	Draft bool `json:"draft"`
+	// Size, RequestedReviewers, BaseRef, HeadRef, and ChecksStatus are only
+	// set for pull requests when IncludePRDetails is enabled, though GraphQL
+	// searches always fill in the branches. Requested teams are listed as
+	// org/slug.
+	Size               *Size         `json:"size,omitempty"`
+	RequestedReviewers []IssueAuthor `json:"requested_reviewers,omitempty"`
+	BaseRef            string        `json:"base_ref,omitempty"`
+	HeadRef            string        `json:"head_ref,omitempty"`
+	ChecksStatus       string        `json:"checks_status,omitempty"`
+	// ClosesIssues are the issues a pull request says it fixes with a closing
+	// keyword such as "Fixes #123".
+	ClosesIssues []IssueRef `json:"closes_issues,omitempty"`
+	// HasLinkedPR is set on issues that a pull request in the same report
+	// closes.
+	HasLinkedPR bool `json:"has_linked_pr,omitempty"`
+	// ReviewStatus is only set for pull requests when IncludeReviewStatus
+	// is enabled.
+	ReviewStatus string `json:"review_status,omitempty"`
+	// ApprovedAt is when a pull request in ApprovedUnmerged was last
+	// approved.
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+	// ProjectStatus is the item's status on the ProjectNumber board, such
+	// as "Todo" or "Done". It is empty for items not on the board.
+	ProjectStatus string `json:"project_status,omitempty"`
+	// Involvement is how InvolvesUser relates to the item, as far as the
+	// item itself tells: InvolvementAuthor, InvolvementAssignee, and
+	// InvolvementMentioned. It is empty when they're only involved in
+	// another way, such as by commenting.
+	Involvement []string `json:"involvement,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ClosedAt is the zero time for items that are still open.
+	ClosedAt time.Time `json:"closed_at"`
+	// MergedAt is the zero time for issues and unmerged pull requests.
+	MergedAt time.Time `json:"merged_at"`
+	// MergedBy is who merged a pull request. It is only known for GraphQL
+	// searches, or with IncludePRDetails through the REST API.
+	MergedBy *IssueAuthor `json:"merged_by,omitempty"`
+
+	// FirstResponseAt is when someone other than the author first
+	// commented on an issue, with IncludeFirstResponse. It is nil when
+	// nobody has yet, as is FirstResponseLatency, the time from the issue
+	// being opened until then.
+	FirstResponseAt      *time.Time     `json:"first_response_at,omitempty"`
+	FirstResponseLatency *time.Duration `json:"first_response_latency,omitempty"`
+	// ResponseOverdue is set on open issues without a response for longer
+	// than ResponseSLA.
+	ResponseOverdue bool `json:"response_overdue,omitempty"`
+
+	pullRequest bool
+	// reviewCounts counts the reviews submitted on a pull request during
+	// the window by each reviewer, for ReviewerStats.
+	reviewCounts map[string]int
+}
+
+// Label is a label applied to an issue or pull request.
+type Label struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
 }
 
+// Milestone is the milestone an issue or pull request belongs to.
+type Milestone struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// DueOn is the zero time for milestones without a due date.
+	DueOn time.Time `json:"due_on"`
+}
+
+// Reactions summarizes the reactions left on an issue or pull request.
+type Reactions struct {
+	TotalCount int `json:"total_count"`
+	// PlusOne is the number of 👍 reactions.
+	PlusOne int `json:"+1"`
+}
+
+// IssueAuthor is a GitHub user, or a team when listed as a requested
+// reviewer. Earlier releases marshaled it with the keys "title" and "url".
 type IssueAuthor struct {
-	DisplayName *string `json:"title"`
-	ProfileURL  *string `json:"url"`
+	DisplayName string `json:"login"`
+	ProfileURL  string `json:"profile_url"`
 }
 
+// ghost stands in for deleted users, as it does on GitHub.
+var ghost = IssueAuthor{
+	DisplayName: "ghost",
+	ProfileURL:  "https://github.com/ghost",
+}
+
+// FetchError collects the errors for repos that couldn't be fetched, keyed by
+// repo.
+type FetchError struct {
+	Errors map[string]error
+}
+
+func (e *FetchError) Error() string {
+	repos := make([]string, 0, len(e.Errors))
+	for repo := range e.Errors {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	msgs := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", repo, e.Errors[repo]))
+	}
+
+	return fmt.Sprintf("failed to fetch %d repos: %s", len(repos), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error for the first repo in alphabetical order, so that
+// errors.Is and errors.As work when every repo failed for the same reason.
+func (e *FetchError) Unwrap() error {
+	var first string
+	for repo := range e.Errors {
+		if first == "" || repo < first {
+			first = repo
+		}
+	}
+
+	return e.Errors[first]
+}
+
+// RepoActivityService builds activity reports for the repos of a code host.
+// GitHubRepoActivityService, GitLabRepoActivityService, and
+// GiteaRepoActivityService implement it, and their reports can be combined
+// with MergeReports. Their deprecated FetchIssues, FetchIssuesContext, and
+// BuildReport methods, and BuildQuery, whose result differs by host, are
+// left out.
 type RepoActivityService interface {
-	FetchIssues(string) (*[]IssueInfo, error)
-	BuildQuery(string) string
-	BuildReport() (*ActivityReport, error)
+	ListIssues(ctx context.Context, issueType string) ([]IssueInfo, error)
+	BuildReportContext(ctx context.Context) (*ActivityReport, error)
 }
 
 type GitHubRepoActivityOptions struct {
-	Repos   []string
+	// Repos may include glob patterns such as "digitalocean/godo-*", which
+	// are expanded by listing the owner's repos when the report is built.
+	Repos []string
+	// Orgs adds every repo in each of the orgs to the report alongside
+	// Repos. Their repos are grouped in the report as returned by the API.
+	Orgs    []string
 	DaysOld int
 
+	// PerPage is the number of search results requested per page. It
+	// defaults to, and is capped at, the Search API maximum of 100.
+	PerPage int
+
+	// BatchSize is the number of repos combined into a single search query.
+	// Zero combines as many repos as fit within GitHub's query length limit.
+	BatchSize int
+	// Concurrency is the number of batches fetched in parallel. It defaults
+	// to 3.
+	Concurrency int
+
+	// RetryOnRateLimit makes searches wait for the rate limit to reset and
+	// then retry the same page, rather than failing immediately.
+	RetryOnRateLimit bool
+	// MaxRateLimitWait is the longest RetryOnRateLimit will wait before
+	// giving up. It defaults to two minutes.
+	MaxRateLimitWait time.Duration
+	// MaxRetries is the number of times a request failing with a 5xx is
+	// retried. Zero uses the default of 3; a negative value disables retries.
+	MaxRetries int
+	// RequestTimeout bounds each API request, including reading its
+	// response, so a hung request fails the report rather than stalling
+	// it. Zero uses HTTPClient's Timeout, or the default of 30 seconds if it
+	// has none; a negative value disables the timeout.
+	RequestTimeout time.Duration
+	// SearchRequestsPerMinute throttles search requests to stay under
+	// GitHub's search rate limit. Zero uses the default of 20; a negative
+	// value disables throttling.
+	SearchRequestsPerMinute int
+	// SearchLimiter, when set, is used instead of a limiter built from
+	// SearchRequestsPerMinute so it can be shared between services.
+	SearchLimiter *rate.Limiter
+
+	// ResponseCache, when set, is used to make repeated API requests
+	// conditional so unchanged results don't use up the rate limit.
+	ResponseCache ResponseCache
+	// ReportCache, when set, returns a previously built report for the same
+	// queries instead of fetching it again.
+	ReportCache *ReportCache
+
+	// ActivityMode is whether the report window selects items by when they
+	// were created, ActivityCreated, the default, or by when they were last
+	// updated, ActivityUpdated.
+	ActivityMode string
+
+	// State restricts the new or updated items to those still StateOpen or
+	// already StateClosed. It defaults to StateAll.
+	State string
+
+	// Milestone restricts the report to items in the milestone with this
+	// title, or without any milestone or with any milestone for
+	// MilestoneNone and MilestoneAny.
+	Milestone string
+
+	// ExtraQuery is appended to every search query after the qualifiers
+	// generated from the other options, with runs of whitespace collapsed
+	// to single spaces. It can't contain newlines.
+	ExtraQuery string
+
+	// Labels restricts the report to items with any of the labels. Each label
+	// is searched for separately.
+	Labels []string
+	// ExcludeLabels drops the items with any of the labels from the report.
+	ExcludeLabels []string
+	// Authors restricts the report to items opened by any of the logins,
+	// and ExcludeAuthors drops the items opened by any of them. Both are
+	// search qualifiers, so they compose with each other and with every
+	// other filter: an author in both lists is excluded.
+	Authors        []string
+	ExcludeAuthors []string
+	// InvolvesUser restricts the report to items the login authored, is
+	// assigned to, was mentioned in, or commented on, and sets each item's
+	// Involvement.
+	InvolvesUser string
+	// ExcludeBots drops the items opened by bots: the accounts in
+	// DefaultBots and Bots are left out of the searches, and any other
+	// author whose login ends in [bot] is filtered out of the results. When
+	// there are too many bots to fit in a query, they're all filtered out of
+	// the results instead. It composes with ExcludeAuthors, which can list
+	// bots GitHub doesn't name as such.
+	ExcludeBots bool
+	// Bots lists the logins of bot accounts, beyond DefaultBots, that
+	// ExcludeBots leaves out.
+	Bots []string
+	// ExcludeOrgMembers, when set to an org's login, leaves out the items
+	// opened by its members so only outside contributions are reported. The
+	// member list is reused for an hour by the service and those made from
+	// it with WithOptions.
+	ExcludeOrgMembers string
+	// IgnoreItems lists items, such as "owner/repo#123", to leave out of
+	// every section of the report and its totals. It suits long-running
+	// tracking issues that would otherwise show up in every report.
+	IgnoreItems []string
+
+	// IncludeClosed adds the issues closed during the report window to each
+	// repo's ClosedIssues, at the cost of an extra search.
+	IncludeClosed bool
+	// ExcludeNotPlanned leaves the issues closed as not planned out of
+	// ClosedIssues and TotalClosedIssues.
+	ExcludeNotPlanned bool
+	// IncludeMerged adds the pull requests merged during the report window
+	// to each repo's MergedPullRequests, at the cost of an extra search.
+	IncludeMerged bool
+	// IncludeReleases adds the releases published during the report window
+	// to each repo's Releases, at the cost of an extra API request per repo.
+	// IncludeTags adds the tags without a release as well, at the cost of an
+	// extra request per tag, and implies IncludeReleases.
+	IncludeReleases bool
+	IncludeTags     bool
+	// IncludeRepoMeta adds each repo's description, stars, forks, and open
+	// issue count to its Meta, at the cost of an extra API request per repo
+	// the first time it's reported on. The counts are reused for an hour by
+	// the service and those made from it with WithOptions.
+	IncludeRepoMeta bool
+	// IncludeSecurityAlerts counts each repo's open Dependabot alerts by
+	// severity in its SecurityAlerts, at the cost of an extra API request
+	// per 100 alerts. The token needs the security_events scope, or the
+	// Dependabot alerts permission for apps; repos whose alerts can't be
+	// read are skipped.
+	IncludeSecurityAlerts bool
+	// IncludeCommits counts the commits made to each repo's default branch
+	// during the report window in its CommitStats, at the cost of an extra
+	// API request per page of 100 commits. MaxCommitPages caps the pages
+	// walked per repo, defaulting to 10.
+	IncludeCommits bool
+	MaxCommitPages int
+	// IncludeCIStats counts the GitHub Actions workflow runs on each repo's
+	// default branch during the report window in its CIStats, at the cost
+	// of an extra API request per repo and per page of 100 runs.
+	// MaxWorkflowRunPages caps the pages walked per repo, defaulting to 5.
+	IncludeCIStats      bool
+	MaxWorkflowRunPages int
+	// IncludeReviewerStats counts the reviews each reviewer submitted during
+	// the report window in ReviewerStats, at the cost of an extra GraphQL
+	// search for the pull requests updated during it. Reviews by bots are
+	// left out with ExcludeBots.
+	IncludeReviewerStats bool
+	// IncludeDiscussions adds the discussions created during the report
+	// window to each repo's Discussions, at the cost of an extra GraphQL
+	// request per repo.
+	IncludeDiscussions bool
+	// CompareWithPrevious counts the issues and pull requests in the window
+	// of the same length before the report window, and how they changed
+	// since, in PreviousTotals and Deltas. It doubles the searches made.
+	CompareWithPrevious bool
+	// StaleDays, when set, adds the open issues and pull requests that
+	// haven't been updated in that many days to each repo's Stale, at the
+	// cost of an extra search.
+	StaleDays int
+	// IncludeApprovedUnmerged adds the open pull requests that have been
+	// approved to each repo's ApprovedUnmerged, at the cost of an extra
+	// search and an extra API request per pull request found.
+	IncludeApprovedUnmerged bool
+	// IncludeNeedsReview adds the open pull requests from the report window
+	// that haven't been reviewed to each repo's NeedsReview, at the cost of
+	// an extra search.
+	IncludeNeedsReview bool
+	// ReviewRequested, a login or an org/team, lists the open pull requests
+	// whose review is requested from that user or team in the report's
+	// ReviewQueue, however old they are, at the cost of an extra search.
+	ReviewRequested string
+	// TriageUnlabeled and TriageUnassigned list the open issues from the
+	// report window without any labels or assignees, respectively, in each
+	// repo's TriageGaps.
+	TriageUnlabeled  bool
+	TriageUnassigned bool
+
+	// ExcludeRepos leaves out the repos matching any of its glob patterns,
+	// such as "digitalocean/*-archive". It applies after the patterns in
+	// Repos are expanded, and to the repos found through Orgs.
+	ExcludeRepos []string
+	// IncludeArchived lets the patterns in Repos match archived repos.
+	// Repos named outright are always included.
+	IncludeArchived bool
+	// VerifyRepos makes Preflight look up every repo named in Repos and
+	// fail with a *FetchError of ErrRepoNotFound for those that don't exist.
+	VerifyRepos bool
+
+	// Since and Until, when set, bound the report window instead of DaysOld.
+	// Both ends are included. Without Since the window starts DaysOld days
+	// before Until, and without Until it runs up to now.
+	Since time.Time
+	Until time.Time
+
+	// Timezone, when set, makes the report cover whole calendar days in that
+	// timezone, starting at midnight DaysOld days ago. By default it covers
+	// exactly DaysOld days up to now.
+	Timezone *time.Location
+
+	// AgeFormat is how IssueInfo.Age is written: AgeFormatShort, the
+	// default, gives only the largest unit such as "3 days", and
+	// AgeFormatFull every unit of the age rounded to the day.
+	AgeFormat string
+
+	// AnonymizeAuthors replaces every user in the report, from authors and
+	// assignees to reviewers and committers, with a pseudonym such as
+	// "contributor-3fa2c1" derived from a hash of their login salted with
+	// AnonymizeSalt, and drops their profile links. Without a salt a random
+	// one is used, so pseudonyms only match within reports from the same
+	// service and those made from it with WithOptions; NewAnonymizeSalt
+	// makes one to share more widely.
+	AnonymizeAuthors bool
+	AnonymizeSalt    string
+
+	// ExcerptLength is the maximum number of runes of each issue body kept
+	// in IssueInfo.BodyExcerpt. It defaults to 200; a negative value leaves
+	// excerpts empty.
+	ExcerptLength int
+
+	// IncludePRDetails looks up every pull request for its Size,
+	// RequestedReviewers, branches, and ChecksStatus, at the cost of two
+	// extra API requests each when searching through the REST API.
+	IncludePRDetails bool
+	// OnlyFailingChecks restricts the report to pull requests whose checks
+	// failed. It implies IncludePRDetails.
+	OnlyFailingChecks bool
+	// PRSizeThresholds are the numbers of lines changed below which a pull
+	// request's Size.Bucket is S, M, and L; anything larger is XL. It
+	// defaults to 50, 250, and 1000.
+	PRSizeThresholds []int
+	// BaseBranch restricts the report to pull requests targeting the named
+	// branch.
+	BaseBranch string
+	// IncludeReviewStatus collapses the reviews on every pull request into
+	// a ReviewStatus, at the cost of an extra API request each when
+	// searching through the REST API.
+	IncludeReviewStatus bool
+	// OnlyUnreviewed restricts the report to pull requests without any
+	// reviews. It implies IncludeReviewStatus.
+	OnlyUnreviewed bool
+
+	// IncludeFirstResponse looks up when each issue was first commented on
+	// by someone other than its author, at the cost of at least one extra
+	// API request per issue.
+	IncludeFirstResponse bool
+	// OnlyUnanswered restricts the report to issues nobody has responded
+	// to yet. It implies IncludeFirstResponse.
+	OnlyUnanswered bool
+	// ResponseSLA, when set, marks the open issues that have gone without a
+	// response for longer as ResponseOverdue.
+	ResponseSLA time.Duration
+
+	// SortBy orders each repo's issues and pull requests by SortByCreated,
+	// SortByUpdated, SortByComments, SortByReactions, or SortByNumber, in
+	// the SortOrder direction. By default the newest items come first.
+	// Items are sorted after they're fetched, since a repo's items can come
+	// from several searches.
+	SortBy    string
+	SortOrder string
+
+	// MaxItemsPerRepo stops paging through a repo's issues and pull
+	// requests once this many of each are found, and MaxItems once this
+	// many are found across the report. Zero means no limit. Repos cut
+	// short have their IssuesLimit or PullRequestsLimit set.
+	MaxItemsPerRepo int
+	MaxItems        int
+
+	// ProjectOwner and ProjectNumber name a Projects v2 board, such as
+	// number 5 of the "acme" org, whose ProjectStatusField sets the
+	// ProjectStatus of the report's items. The field defaults to "Status".
+	// The board is read through GraphQL with a request per 100 items, and
+	// needs a token with the read:project scope; a board that can't be read
+	// is logged and skipped.
+	ProjectOwner       string
+	ProjectNumber      int
+	ProjectStatusField string
+
+	// UseGraphQL fetches issues and pull requests through the GraphQL API,
+	// which returns both in a single paginated search.
+	UseGraphQL bool
+	// GraphQLEndpoint overrides the GraphQL URL derived from APIEndpoint.
+	GraphQLEndpoint string
+	// SeparateSearches searches the REST API for issues and pull requests
+	// with a query each, as before they were combined into one, doubling
+	// the searches made. A BaseBranch always takes separate searches, since
+	// a combined one can't use the base: qualifier.
+	SeparateSearches bool
+
 	APIEndpoint string
-	Token       string
+	// APIUploadEndpoint is the GitHub Enterprise upload URL. It defaults to
+	// APIEndpoint.
+	APIUploadEndpoint string
+	Token             string
+	// Tokens are used in turn to spread requests across several rate
+	// limits, along with Token if it is also set. Tokens that have used up
+	// their limit are skipped until it resets.
+	Tokens []string
+	// TokenFile is read for the API token instead of using Token. The file is
+	// re-read whenever it changes, so rotated tokens take effect without
+	// restarting.
+	TokenFile string
+
+	// AppID authenticates as a GitHub App installation instead of with
+	// Token. InstallationID and one of AppPrivateKey or AppPrivateKeyPath
+	// are then required. Installation tokens are refreshed automatically
+	// when they expire.
+	AppID             int64
+	InstallationID    int64
+	AppPrivateKey     []byte
+	AppPrivateKeyPath string
+
+	// Metrics is notified about every search request. It defaults to
+	// NopMetrics.
+	Metrics Metrics
+
+	// Log receives debug output about the queries made and their results. It
+	// defaults to discarding everything.
+	Log log.FieldLogger
+
+	// Now returns the current time, and is used for the report window and
+	// item ages. It defaults to time.Now.
+	Now func() time.Time
+
+	// HTTPClient is the client used to make API requests, for example to set
+	// up a proxy or instrumentation. Authentication and caching are layered
+	// over its transport. It defaults to a client using
+	// http.DefaultTransport.
+	HTTPClient *http.Client
+	// UserAgent is sent with every API request. It defaults to
+	// "github-repo-activity/" followed by Version.
+	UserAgent string
+	// DebugHTTP logs the method, URL, status, and rate limit headers of
+	// every API request to Log at debug level.
+	DebugHTTP bool
+	// ProxyURL, such as "http://proxy.example.com:3128", sends every API
+	// request through the proxy in place of the one set by HTTP_PROXY,
+	// HTTPS_PROXY, and NO_PROXY. HTTPClient's transport, if set, must be an
+	// *http.Transport.
+	ProxyURL string
 }
 
 type GitHubRepoActivityService struct {
-	client  *github.Client
-	options *GitHubRepoActivityOptions
+	client        *github.Client
+	httpClient    *http.Client
+	searchLimiter *rate.Limiter
+	options       *GitHubRepoActivityOptions
+	lookups       *lookups
 }
 
 var _ RepoActivityService = &GitHubRepoActivityService{}
 
-func NewGitHubRepoActivityService(options *GitHubRepoActivityOptions) *GitHubRepoActivityService {
-	client := github.NewClient(nil)
+// NewGitHubRepoActivityService initializes a service from options, trimming
+// the whitespace around each of options.Repos. Invalid options give an error
+// wrapping ErrInvalidOption, or ErrInvalidWindow when options.Until is
+// before options.Since.
+func NewGitHubRepoActivityService(options *GitHubRepoActivityOptions) (*GitHubRepoActivityService, error) {
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
 
-	if options.Token != "" {
-		tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: options.Token})
-		client = github.NewClient(oauth2.NewClient(context.Background(), tokenSource))
+	httpClient, err := newHTTPClient(options)
+	if err != nil {
+		return nil, err
 	}
+	client := github.NewClient(httpClient)
 
 	if options.APIEndpoint != "" {
-		baseURL, err := url.Parse(options.APIEndpoint)
+		baseURL, err := parseEndpoint(options.APIEndpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		uploadURL := baseURL
+		if options.APIUploadEndpoint != "" {
+			uploadURL, err = parseEndpoint(options.APIUploadEndpoint)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		client, err = github.NewEnterpriseClient(baseURL.String(), uploadURL.String(), httpClient)
 		if err != nil {
-			panic("invalid base URL: " + err.Error())
+			return nil, err
 		}
-		client.BaseURL = baseURL
 	}
 
 	return &GitHubRepoActivityService{
-		client:  client,
-		options: options,
+		client:        client,
+		httpClient:    httpClient,
+		searchLimiter: searchLimiter(options),
+		options:       options,
+		lookups:       &lookups{},
+	}, nil
+}
+
+// validateOptions checks the options that don't configure the HTTP client,
+// trimming the whitespace around each of options.Repos.
+func validateOptions(options *GitHubRepoActivityOptions) error {
+	if !options.Since.IsZero() && !options.Until.IsZero() && options.Until.Before(options.Since) {
+		return fmt.Errorf("%w: until %s is before since %s", ErrInvalidWindow, options.Until.Format(time.RFC3339), options.Since.Format(time.RFC3339))
+	}
+	if strings.ContainsAny(options.ExtraQuery, "\r\n") {
+		return fmt.Errorf("%w: the extra query can't contain newlines", ErrInvalidOption)
+	}
+	if !validState(options.State) {
+		return fmt.Errorf("%w: state %q must be %q, %q, or %q", ErrInvalidOption, options.State, StateAll, StateOpen, StateClosed)
+	}
+	if !validSort(options.SortBy, options.SortOrder) {
+		return fmt.Errorf("%w: can't sort by %q in %q order", ErrInvalidOption, options.SortBy, options.SortOrder)
+	}
+	if err := validateItemRefs(options.IgnoreItems); err != nil {
+		return err
+	}
+	repos, err := validateRepos(options.Repos)
+	if err != nil {
+		return err
+	}
+	options.Repos = repos
+
+	return nil
+}
+
+// WithOptions returns a service for options that shares ghra's HTTP and
+// GitHub clients, search limiter, and cached lookups, so options can be
+// changed for a single report without authenticating again, losing track of
+// exhausted tokens, or looking up the same org members and repos again.
+// The options that configure the clients, such as the tokens, ProxyURL, and
+// RequestTimeout, are ignored in favor of ghra's. It returns the same errors
+// as NewGitHubRepoActivityService for invalid options.
+func (ghra *GitHubRepoActivityService) WithOptions(options *GitHubRepoActivityOptions) (*GitHubRepoActivityService, error) {
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+
+	return &GitHubRepoActivityService{
+		client:        ghra.client,
+		httpClient:    ghra.httpClient,
+		searchLimiter: ghra.searchLimiter,
+		options:       options,
+		lookups:       ghra.lookups,
+	}, nil
+}
+
+// MustNewGitHubRepoActivityService is like NewGitHubRepoActivityService but
+// panics if the options are invalid.
+//
+// Deprecated: use NewGitHubRepoActivityService and handle the error.
+func MustNewGitHubRepoActivityService(options *GitHubRepoActivityOptions) *GitHubRepoActivityService {
+	service, err := NewGitHubRepoActivityService(options)
+	if err != nil {
+		panic(err)
+	}
+
+	return service
+}
+
+// parseEndpoint validates an API endpoint, adding the trailing slash that
+// go-github needs to resolve request paths against it. It returns an error
+// wrapping ErrInvalidOption if endpoint isn't an absolute URL.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: API endpoint %q: %v", ErrInvalidOption, endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: API endpoint %q must be an absolute URL such as https://github.example.com/api/v3/", ErrInvalidOption, endpoint)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	return u, nil
+}
+
+// NewGitHubRepoActivityServiceWithClient initializes a service that makes
+// REST requests with the given client as is, ignoring the Token and
+// APIEndpoint options. GraphQL requests use options.HTTPClient, or
+// http.DefaultClient if it is unset.
+func NewGitHubRepoActivityServiceWithClient(client *github.Client, options *GitHubRepoActivityOptions) *GitHubRepoActivityService {
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &GitHubRepoActivityService{
+		client:        client,
+		httpClient:    httpClient,
+		searchLimiter: searchLimiter(options),
+		options:       options,
+		lookups:       &lookups{},
 	}
 }
 
+// searchLimiter returns options.SearchLimiter, or a new limiter allowing
+// options.SearchRequestsPerMinute.
+func searchLimiter(options *GitHubRepoActivityOptions) *rate.Limiter {
+	if options.SearchLimiter != nil {
+		return options.SearchLimiter
+	}
+
+	return NewSearchLimiter(options.SearchRequestsPerMinute)
+}
+
+// newHTTPClient builds the client used for API requests, layering
+// the User-Agent, authentication, and response caching over
+// options.HTTPClient and applying options.RequestTimeout.
+func newHTTPClient(options *GitHubRepoActivityOptions) (*http.Client, error) {
+	httpClient := &http.Client{}
+	if options.HTTPClient != nil {
+		*httpClient = *options.HTTPClient
+	}
+
+	switch {
+	case options.RequestTimeout > 0:
+		httpClient.Timeout = options.RequestTimeout
+	case options.RequestTimeout < 0:
+		httpClient.Timeout = 0
+	case httpClient.Timeout == 0:
+		httpClient.Timeout = defaultRequestTimeout
+	}
+
+	transport, err := proxyTransport(httpClient.Transport, options.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if options.DebugHTTP && options.Log != nil {
+		transport = &debugTransport{log: options.Log, base: transport}
+	}
+	userAgent := options.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent()
+	}
+	transport = &userAgentTransport{userAgent: userAgent, base: transport}
+
+	switch {
+	case options.AppID != 0:
+		tokenSource, err := newAppTokenSource(options, transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = &oauth2.Transport{Source: tokenSource, Base: transport}
+	case len(options.Tokens) > 0:
+		tokens := options.Tokens
+		if options.Token != "" {
+			tokens = append([]string{options.Token}, tokens...)
+		}
+		transport = newTokenPoolTransport(tokens, transport)
+	case options.TokenFile != "":
+		tokenSource, err := newFileTokenSource(options.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		transport = &oauth2.Transport{Source: tokenSource, Base: transport}
+	case options.Token != "":
+		tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: options.Token})
+		transport = &oauth2.Transport{Source: tokenSource, Base: transport}
+	}
+
+	if options.ResponseCache != nil {
+		transport = &etagTransport{cache: options.ResponseCache, base: transport}
+	}
+
+	httpClient.Transport = transport
+
+	return httpClient, nil
+}
+
+// BuildQuery returns the search query for issueType. When several Labels are
+// set it is the query for the first one; see BuildQueries.
 func (ghra *GitHubRepoActivityService) BuildQuery(issueType string) string {
-	var repos []string
-	for _, s := range ghra.options.Repos {
-		repos = append(repos, fmt.Sprintf("repo:%s", s))
+	return ghra.BuildQueries(issueType)[0]
+}
+
+// BuildQueries returns the search queries for issueType, one for each of
+// Labels or a single query when none are set.
+func (ghra *GitHubRepoActivityService) BuildQueries(issueType string) []string {
+	query := ghra.buildQuery(issueType, ghra.scopes())
+
+	var queries []string
+	for _, q := range ghra.labelQualifiers() {
+		if q == "" {
+			queries = append(queries, query)
+		} else {
+			queries = append(queries, query+" "+q)
+		}
 	}
 
-	created := time.Now().AddDate(0, 0, ghra.options.DaysOld*-1).Format("2006-01-02")
-	query := fmt.Sprintf("is:%s %s created:>=%s", issueType, strings.Join(repos, " "), created)
+	return queries
+}
+
+func (ghra *GitHubRepoActivityService) buildQuery(issueType string, repoNames []string) string {
+	since, until := ghra.window()
+	return ghra.buildWindowQuery(issueType, ghra.activityMode(), repoNames, since, until)
+}
+
+// buildWindowQuery builds a query for items whose field, such as created or
+// closed, falls between since and until, inclusive. A zero until leaves the
+// window open-ended.
+func (ghra *GitHubRepoActivityService) buildWindowQuery(issueType, field string, repoNames []string, since, until time.Time) string {
+	return ghra.windowQuery(issueType, field, repoNames, since, until, ghra.qualifiers(issueType))
+}
+
+// windowQuery builds the query from buildWindowQuery with the qualifiers q.
+func (ghra *GitHubRepoActivityService) windowQuery(issueType, field string, repoNames []string, since, until time.Time, q []string) string {
+	repos := scopeQualifiers(repoNames)
+
+	window := ">=" + since.UTC().Format(time.RFC3339)
+	if !until.IsZero() {
+		window = since.UTC().Format(time.RFC3339) + ".." + until.UTC().Format(time.RFC3339)
+	}
+
+	query := fmt.Sprintf("%s %s:%s", strings.Join(repos, " "), field, window)
+	// State only narrows the new or updated items, not the closed and
+	// merged sections.
+	if q := ghra.stateQualifier(); q != "" && field == ghra.activityMode() {
+		query += " " + q
+	}
+	if len(q) > 0 {
+		query += " " + strings.Join(q, " ")
+	}
+	if issueType != "" {
+		query = fmt.Sprintf("is:%s %s", issueType, query)
+	}
 
 	return query
 }
 
+// window returns the start and end of the report window. A zero end leaves
+// the window running up to now.
+func (ghra *GitHubRepoActivityService) window() (time.Time, time.Time) {
+	since, until := ghra.options.Since, ghra.options.Until
+	switch {
+	case !since.IsZero():
+	case !until.IsZero():
+		since = until.AddDate(0, 0, ghra.options.DaysOld*-1)
+	default:
+		since = ghra.cutoff()
+	}
+
+	return since, until
+}
+
+// cutoff returns the start of a window running up to now: DaysOld days ago
+// rounded down to the minute, or midnight DaysOld days ago when a Timezone
+// is set. Rounding keeps the search queries, and so the URLs the response
+// cache is keyed on, the same for a minute at a time.
+func (ghra *GitHubRepoActivityService) cutoff() time.Time {
+	if tz := ghra.options.Timezone; tz != nil {
+		now := ghra.now().In(tz)
+		return time.Date(now.Year(), now.Month(), now.Day()-ghra.options.DaysOld, 0, 0, 0, 0, tz).UTC()
+	}
+
+	return ghra.now().UTC().AddDate(0, 0, ghra.options.DaysOld*-1).Truncate(time.Minute)
+}
+
+// FetchIssues is a wrapper around FetchIssuesContext using context.Background.
+//
+// Deprecated: use ListIssues.
 func (ghra *GitHubRepoActivityService) FetchIssues(issueType string) (*[]IssueInfo, error) {
-	ctx := context.TODO()
+	return ghra.FetchIssuesContext(context.Background(), issueType)
+}
+
+// FetchIssuesContext is like ListIssues, but returns a pointer to the items.
+//
+// Deprecated: use ListIssues.
+func (ghra *GitHubRepoActivityService) FetchIssuesContext(ctx context.Context, issueType string) (*[]IssueInfo, error) {
+	items, err := ghra.ListIssues(ctx, issueType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &items, nil
+}
+
+// ListIssues searches for issues of the given type, walking every page
+// of results. When the repos are split into batches, the batches are fetched by
+// a bounded pool of workers. If any repo fails, the errors are returned in a
+// *FetchError.
+func (ghra *GitHubRepoActivityService) ListIssues(ctx context.Context, issueType string) ([]IssueInfo, error) {
+	if err := ghra.expandRepos(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := ghra.fetchIssues(ctx, issueType)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.errors) > 0 {
+		return nil, &FetchError{Errors: result.errors}
+	}
+
+	return result.items, nil
+}
+
+// FetchIssuesIter searches for issues of the given type like ListIssues,
+// but calls fn with each item as its page of results arrives instead of
+// gathering them all first. The batches of repos are searched one at a time. If fn returns an error, no further pages are
+// fetched and that error is returned. If any repo fails, the others are
+// still searched and the errors are returned in a *FetchError.
+func (ghra *GitHubRepoActivityService) FetchIssuesIter(ctx context.Context, issueType string, fn func(IssueInfo) error) error {
+	if err := ghra.expandRepos(ctx); err != nil {
+		return err
+	}
+
+	errs := make(map[string]error)
+	for _, batch := range ghra.batches(issueType) {
+		if err := ghra.eachBatch(ctx, issueType, batch, fn, errs); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return &FetchError{Errors: errs}
+	}
+
+	return nil
+}
+
+// stopError carries an error returned by a FetchIssuesIter callback, so it
+// isn't mistaken for a failed search.
+type stopError struct {
+	err error
+}
+
+func (e *stopError) Error() string {
+	return e.err.Error()
+}
+
+// eachBatch searches a single batch of repos for FetchIssuesIter, recording
+// the repos that fail in errs like fetchBatch. Only an error from fn or ctx
+// is returned.
+func (ghra *GitHubRepoActivityService) eachBatch(ctx context.Context, issueType string, batch []string, fn func(IssueInfo) error, errs map[string]error) error {
+	since, until := ghra.window()
+
+	found := false
+	_, err := ghra.eachLabels(ctx, ghra.pagedSearch(), issueType, ghra.activityMode(), batch, since, until, func(i IssueInfo) error {
+		found = true
+		if (ghra.options.ExcludeBots && ghra.isExcludedBot(i.Author.DisplayName)) || ghra.excludedRepo(i.Repo) {
+			return nil
+		}
+		if err := fn(i); err != nil {
+			return &stopError{err: err}
+		}
+		return nil
+	})
+
+	var stop *stopError
+	switch {
+	case err == nil:
+	case errors.As(err, &stop):
+		return stop.err
+	case ctx.Err() != nil:
+		return ctx.Err()
+	// Retrying the repos one at a time would repeat any items already
+	// passed to fn, so that's only done when GitHub rejects the first page.
+	case !found && len(batch) > 1 && isValidationError(err):
+		for _, repo := range batch {
+			if err := ghra.eachBatch(ctx, issueType, []string{repo}, fn, errs); err != nil {
+				return err
+			}
+		}
+	case len(batch) == 1 && (isValidationError(err) || statusCode(err) == http.StatusNotFound):
+		errs[batch[0]] = &ErrRepoNotFound{Repo: batch[0], Err: err}
+	default:
+		for _, repo := range batch {
+			errs[repo] = err
+		}
+	}
+
+	return nil
+}
+
+// searchFunc runs one search query and returns the items found along with
+// the total number of matches reported by the API.
+type searchFunc func(ctx context.Context, query string) ([]IssueInfo, int, error)
+
+// pageFunc receives a page of search results along with the total number of
+// matches reported by the API.
+type pageFunc func(items []IssueInfo, total int) error
+
+// pagedSearchFunc runs one search query, passing each page of results to
+// page as it arrives. Paging stops at the first error page returns, which is
+// returned.
+type pagedSearchFunc func(ctx context.Context, query string, page pageFunc) error
+
+// collect returns a search gathering every page of results from paged.
+func collect(paged pagedSearchFunc) searchFunc {
+	return func(ctx context.Context, query string) ([]IssueInfo, int, error) {
+		items := []IssueInfo{}
+		total := 0
+		err := paged(ctx, query, func(page []IssueInfo, t int) error {
+			items = append(items, page...)
+			total = t
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return items, total, nil
+	}
+}
+
+// paged returns a paged search passing all of search's results as a single
+// page.
+func paged(search searchFunc) pagedSearchFunc {
+	return func(ctx context.Context, query string, page pageFunc) error {
+		items, total, err := search(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		return page(items, total)
+	}
+}
+
+// fetchResult is the outcome of searching every batch of repos.
+type fetchResult struct {
+	items []IssueInfo
+	// truncated holds the repos whose results hit the search result cap.
+	truncated map[string]bool
+	// errors holds the repos that couldn't be fetched.
+	errors map[string]error
+}
+
+func newFetchResult() *fetchResult {
+	return &fetchResult{
+		items:     []IssueInfo{},
+		truncated: make(map[string]bool),
+		errors:    make(map[string]error),
+	}
+}
+
+func (r *fetchResult) merge(other *fetchResult) {
+	r.items = append(r.items, other.items...)
+	for repo := range other.truncated {
+		r.truncated[repo] = true
+	}
+	for repo, err := range other.errors {
+		r.errors[repo] = err
+	}
+}
+
+func (ghra *GitHubRepoActivityService) fetchIssues(ctx context.Context, issueType string) (*fetchResult, error) {
+	return ghra.fetchWindow(ctx, issueType, ghra.activityMode())
+}
+
+// fetchWindow searches every batch of repos for items of issueType whose
+// field falls within the report window.
+func (ghra *GitHubRepoActivityService) fetchWindow(ctx context.Context, issueType, field string) (*fetchResult, error) {
+	search := ghra.pagedSearch()
+	since, until := ghra.window()
+	return ghra.fetchBatches(ctx, ghra.batches(issueType), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		return ghra.searchLabels(ctx, search, issueType, field, repos, since, until)
+	})
+}
+
+// pagedSearch returns the paged search matching UseGraphQL.
+func (ghra *GitHubRepoActivityService) pagedSearch() pagedSearchFunc {
+	if ghra.options.UseGraphQL {
+		return ghra.searchGraphQLPages
+	}
+
+	return ghra.searchIssuesPages
+}
+
+// errSplitWindow stops paging through a search that hit the result cap so
+// its window can be split instead.
+var errSplitWindow = errors.New("search window needs splitting")
+
+// eachWindow searches for items whose field falls between since and until,
+// passing each one to fn as its page arrives. When the first page shows the
+// search will hit the result cap, the window is split in half and each half
+// is searched separately, newer first. It reports whether results are still
+// missing once the window can't be split any further.
+func (ghra *GitHubRepoActivityService) eachWindow(ctx context.Context, search pagedSearchFunc, issueType, field string, repos []string, since, until time.Time, fn func(IssueInfo) error) (bool, error) {
+	end := until
+	if end.IsZero() {
+		end = ghra.now()
+	}
+	span := end.Sub(since)
+
+	first, truncated := true, false
+	err := search(ctx, ghra.buildWindowQuery(issueType, field, repos, since, until), func(items []IssueInfo, total int) error {
+		if first {
+			first = false
+			if total > maxSearchResults {
+				if span >= 2*time.Second {
+					return errSplitWindow
+				}
+				truncated = true
+			}
+		}
+		for _, i := range items {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if !errors.Is(err, errSplitWindow) {
+		return truncated, err
+	}
+
+	// Query timestamps have a resolution of a second and ranges include both
+	// ends, so the older half stops a second before the newer one starts.
+	mid := since.Add(span / 2).Truncate(time.Second)
+	newerTruncated, err := ghra.eachWindow(ctx, search, issueType, field, repos, mid, end, fn)
+	if err != nil {
+		return false, err
+	}
+	olderTruncated, err := ghra.eachWindow(ctx, search, issueType, field, repos, since, mid.Add(-time.Second), fn)
+	if err != nil {
+		return false, err
+	}
+
+	return olderTruncated || newerTruncated, nil
+}
+
+// fetchBatches calls fetch for every batch of repos using a bounded pool of
+// workers and concatenates the results in batch order. Repos that fail are
+// recorded in the result's errors; an error is only returned when ctx is done
+// or no repo could be fetched at all.
+func (ghra *GitHubRepoActivityService) fetchBatches(ctx context.Context, batches [][]string, fetch func(context.Context, []string) ([]IssueInfo, bool, error)) (*fetchResult, error) {
+	results := make([]*fetchResult, len(batches))
+
+	if len(batches) == 1 {
+		results[0] = ghra.fetchBatch(ctx, batches[0], fetch)
+	} else {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < ghra.concurrency(); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					results[i] = ghra.fetchBatch(ctx, batches[i], fetch)
+				}
+			}()
+		}
+		for i := range batches {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := newFetchResult()
+	repoCount := 0
+	for i, batch := range batches {
+		result.merge(results[i])
+		repoCount += len(batch)
+	}
+	if len(result.errors) > 0 && len(result.errors) == repoCount {
+		return nil, &FetchError{Errors: result.errors}
+	}
+
+	return result, nil
+}
+
+// fetchBatch fetches a single batch of repos. If GitHub rejects the query,
+// usually because one of the repos doesn't exist or isn't visible to the
+// token, each repo is retried on its own so the others can still be reported.
+func (ghra *GitHubRepoActivityService) fetchBatch(ctx context.Context, batch []string, fetch func(context.Context, []string) ([]IssueInfo, bool, error)) *fetchResult {
+	result := newFetchResult()
+
+	items, truncated, err := fetch(ctx, batch)
+	switch {
+	case err == nil:
+		result.items = ghra.filterExcludedRepos(ghra.filterBots(items))
+		if truncated {
+			for _, repo := range batch {
+				result.truncated[repo] = true
+			}
+		}
+	case len(batch) > 1 && isValidationError(err):
+		for _, repo := range batch {
+			result.merge(ghra.fetchBatch(ctx, []string{repo}, fetch))
+		}
+	case len(batch) == 1 && (isValidationError(err) || statusCode(err) == http.StatusNotFound):
+		result.errors[batch[0]] = &ErrRepoNotFound{Repo: batch[0], Err: err}
+	default:
+		for _, repo := range batch {
+			result.errors[repo] = err
+		}
+	}
+
+	return result
+}
+
+// searchIssues runs a single search query, walking every page of results.
+// Paging stops as soon as ctx is cancelled.
+func (ghra *GitHubRepoActivityService) searchIssues(ctx context.Context, query string) ([]IssueInfo, int, error) {
+	return collect(ghra.searchIssuesPages)(ctx, query)
+}
+
+// searchIssuesPages runs a single search query, passing each page of results
+// to page as it arrives.
+func (ghra *GitHubRepoActivityService) searchIssuesPages(ctx context.Context, query string, page pageFunc) error {
 	opt := &github.SearchOptions{
 		ListOptions: github.ListOptions{
-			PerPage: 200,
+			PerPage: ghra.perPage(),
 		},
 	}
 
-	query := ghra.BuildQuery(issueType)
+	logger := ghra.logger().WithField("query", query)
+	logger.Debug("searching issues")
+	start := time.Now()
 
-	issueList := []IssueInfo{}
+	total := 0
+	items := 0
+	pages := 0
 	for {
-		result, resp, err := ghra.client.Search.Issues(ctx, query, opt)
+		var result *searchIssuesResult
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			if err := ghra.throttle(ctx); err != nil {
+				return nil, err
+			}
+			return ghra.observeSearch(ctx, query, pages+1, func() (*github.Response, error) {
+				var (
+					resp *github.Response
+					err  error
+				)
+				result, resp, err = ghra.searchIssuesPage(ctx, query, opt)
+				return resp, err
+			})
+		})
 		if err != nil {
-			return nil, err
+			return err
 		}
+		total = result.Total
+		pages++
+		logger.WithFields(log.Fields{
+			"page":  pages,
+			"items": len(result.Issues),
+		}).Debug("fetched search page")
 
+		issueList := make([]IssueInfo, 0, len(result.Issues))
 		for _, issue := range result.Issues {
-			age := durafmt.Parse(time.Since(*issue.CreatedAt).Round(time.Hour * 24))
-
+			repo := repoName(issue.Issue)
+			age := ghra.age(issue.GetCreatedAt())
 			info := IssueInfo{
-				ID:     issue.ID,
-				Number: issue.Number,
-				Title:  issue.Title,
-				Author: IssueAuthor{
-					DisplayName: issue.User.Login,
-					ProfileURL:  issue.User.HTMLURL,
+				ID:     issue.GetID(),
+				Number: issue.GetNumber(),
+				Title:  issue.GetTitle(),
+				Author: author(issue.User),
+				Repo:   repo,
+				URL:    issue.GetHTMLURL(),
+				Status: issue.GetState(),
+				Age:    ghra.formatAge(issue.GetCreatedAt()),
+
+				AgeDuration: age,
+				AgeSeconds:  int64(age.Seconds()),
+				New:         ghra.createdInWindow(issue.GetCreatedAt()),
+
+				AuthorAssociation: issue.AuthorAssociation,
+				FirstTime:         firstTime(issue.AuthorAssociation),
+				BodyExcerpt:       excerpt(issue.GetBody(), ghra.excerptLength()),
+
+				Labels:    labels(issue.Labels),
+				Assignees: assignees(issue.Assignees),
+				Milestone: milestone(issue.Milestone),
+				Comments:  issue.GetComments(),
+				Reactions: Reactions{
+					TotalCount: issue.GetReactions().GetTotalCount(),
+					PlusOne:    issue.GetReactions().GetPlusOne(),
 				},
-				Repo:   strings.TrimPrefix(*issue.RepositoryURL, "https://api.github.com/repos/"),
-				URL:    issue.HTMLURL,
-				Status: issue.State,
-				Age:    age.String(),
+
+				CreatedAt: issue.GetCreatedAt(),
+				UpdatedAt: issue.GetUpdatedAt(),
+				ClosedAt:  issue.GetClosedAt(),
+
+				Draft:       issue.Draft,
+				pullRequest: issue.PullRequest != nil,
+				StateReason: issue.StateReason,
+			}
+
+			info.Involvement = ghra.involvement(info, issue.GetBody())
+
+			if info.pullRequest {
+				info.ClosesIssues = closingReferences(issue.GetTitle()+"\n"+issue.GetBody(), repo, issue.GetHTMLURL())
+				info.MergedAt, err = ghra.mergedAt(ctx, issue, repo)
+				if err != nil {
+					return err
+				}
+				if !info.MergedAt.IsZero() {
+					info.Status = StatusMerged
+				}
 			}
 
 			issueList = append(issueList, info)
 		}
+		items += len(issueList)
+		if err := page(issueList, total); err != nil {
+			return err
+		}
 
+		// Search never returns more than 1,000 results regardless of
+		// result.Total, so rely on the Link header rather than the total.
 		if resp.NextPage == 0 {
 			break
 		}
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	return &issueList, nil
+	logger.WithFields(log.Fields{
+		"pages":   pages,
+		"items":   items,
+		"total":   total,
+		"elapsed": time.Since(start),
+	}).Debug("search completed")
+
+	return nil
 }
 
+// labels converts go-github labels.
+func labels(ghLabels []github.Label) []Label {
+	result := make([]Label, 0, len(ghLabels))
+	for _, l := range ghLabels {
+		result = append(result, Label{Name: l.GetName(), Color: l.GetColor()})
+	}
+
+	return result
+}
+
+// assignees converts the users assigned to an issue.
+func assignees(users []*github.User) []IssueAuthor {
+	result := make([]IssueAuthor, 0, len(users))
+	for _, u := range users {
+		result = append(result, author(u))
+	}
+
+	return result
+}
+
+// author converts a go-github user, substituting the ghost user when it has
+// been deleted.
+func author(u *github.User) IssueAuthor {
+	if u.GetLogin() == "" {
+		return ghost
+	}
+
+	return IssueAuthor{
+		DisplayName: u.GetLogin(),
+		ProfileURL:  u.GetHTMLURL(),
+	}
+}
+
+// milestone converts a go-github milestone, returning nil when there isn't
+// one.
+func milestone(m *github.Milestone) *Milestone {
+	if m == nil {
+		return nil
+	}
+
+	return &Milestone{
+		Title: m.GetTitle(),
+		URL:   m.GetHTMLURL(),
+		DueOn: m.GetDueOn(),
+	}
+}
+
+// repoName returns the owner/name of the repo an issue belongs to. Search
+// results don't embed the repository, so it is usually taken from the last
+// two path segments of the repository API URL, which works on any host.
+func repoName(issue github.Issue) string {
+	if name := issue.GetRepository().GetFullName(); name != "" {
+		return name
+	}
+
+	u, err := url.Parse(issue.GetRepositoryURL())
+	if err != nil {
+		return issue.GetRepositoryURL()
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 {
+		return issue.GetRepositoryURL()
+	}
+
+	return strings.Join(segments[len(segments)-2:], "/")
+}
+
+// now returns the current time from options.Now, defaulting to time.Now.
+func (ghra *GitHubRepoActivityService) now() time.Time {
+	if ghra.options.Now != nil {
+		return ghra.options.Now()
+	}
+
+	return time.Now()
+}
+
+// batches splits the configured repos into groups that are searched with one
+// query each. A group holds at most BatchSize repos, and is kept small enough
+// that its query fits within GitHub's maximum query length.
+func (ghra *GitHubRepoActivityService) batches(issueType string) [][]string {
+	size := ghra.options.BatchSize
+	if size <= 0 {
+		size = len(ghra.scopes())
+	}
+
+	var batches [][]string
+	var batch []string
+	for _, repo := range ghra.scopes() {
+		next := append(batch[:len(batch):len(batch)], repo)
+		if len(batch) > 0 && (len(next) > size || len(ghra.buildQuery(issueType, next))+longest(ghra.labelQualifiers())+1 > maxQueryLength) {
+			batches = append(batches, batch)
+			next = []string{repo}
+		}
+		batch = next
+	}
+	if len(batch) > 0 || len(batches) == 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+func (ghra *GitHubRepoActivityService) concurrency() int {
+	if ghra.options.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+
+	return ghra.options.Concurrency
+}
+
+func (ghra *GitHubRepoActivityService) perPage() int {
+	if ghra.options.PerPage <= 0 || ghra.options.PerPage > maxPerPage {
+		return maxPerPage
+	}
+
+	return ghra.options.PerPage
+}
+
+// BuildReport is a wrapper around BuildReportContext using context.Background.
+//
+// Deprecated: use BuildReportContext.
 func (ghra *GitHubRepoActivityService) BuildReport() (*ActivityReport, error) {
-	issues, err := ghra.FetchIssues("issue")
+	return ghra.BuildReportContext(context.Background())
+}
+
+// BuildReportContext fetches issues and pull requests concurrently and groups
+// them by repo. The first fetch error cancels the other and is returned. If a
+// ReportCache is configured, a cached report for the same queries is returned
+// without fetching.
+func (ghra *GitHubRepoActivityService) BuildReportContext(ctx context.Context) (*ActivityReport, error) {
+	if ghra.options.ReportCache != nil {
+		if key, ok := ghra.reportCacheKey(); ok {
+			if report, ok := ghra.options.ReportCache.Get(key); ok {
+				return report, nil
+			}
+		}
+	}
+
+	return ghra.RefreshReportContext(ctx)
+}
+
+// RefreshReportContext builds the report like BuildReportContext but always
+// fetches from GitHub, replacing any cached copy.
+func (ghra *GitHubRepoActivityService) RefreshReportContext(ctx context.Context) (*ActivityReport, error) {
+	report, err := ghra.buildReport(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	prs, err := ghra.FetchIssues("pr")
+	if ghra.options.ReportCache != nil {
+		if key, ok := ghra.reportCacheKey(); ok {
+			ghra.options.ReportCache.Set(key, report)
+		}
+	}
+
+	return report, nil
+}
+
+// reportCacheKey returns the ReportCache key for the report the options
+// build: the options its snapshot records, along with those that change the
+// report without being recorded. Every option that changes the report is
+// part of it, so services sharing a cache never get each other's reports.
+// It returns false if the options can't be keyed, such as a Since outside
+// the years JSON can hold, and the report isn't cached.
+func (ghra *GitHubRepoActivityService) reportCacheKey() (string, bool) {
+	// Logins and items are case-insensitive, so they're keyed lowercased.
+	// Repos are keyed as configured so the key is the same before and after
+	// their patterns are expanded.
+	recorded := ghra.reportOptions()
+	recorded.ReviewRequested = strings.ToLower(recorded.ReviewRequested)
+	recorded.ExcludeOrgMembers = strings.ToLower(recorded.ExcludeOrgMembers)
+	ignored := make([]string, len(recorded.IgnoreItems))
+	for i, item := range recorded.IgnoreItems {
+		ignored[i] = strings.ToLower(item)
+	}
+	recorded.IgnoreItems = ignored
+
+	b, err := json.Marshal(struct {
+		*ReportOptions
+		Bots                []string `json:"bots,omitempty"`
+		AnonymizeSalt       string   `json:"anonymize_salt,omitempty"`
+		MaxCommitPages      int      `json:"max_commit_pages"`
+		MaxWorkflowRunPages int      `json:"max_workflow_run_pages"`
+		SeparateSearches    bool     `json:"separate_searches,omitempty"`
+		AgeFormat           string   `json:"age_format,omitempty"`
+		ExcerptLength       int      `json:"excerpt_length,omitempty"`
+		PerPage             int      `json:"per_page,omitempty"`
+		PRSizeThresholds    []int    `json:"pr_size_thresholds,omitempty"`
+		VerifyRepos         bool     `json:"verify_repos,omitempty"`
+		APIEndpoint         string   `json:"api_endpoint,omitempty"`
+		GraphQLEndpoint     string   `json:"graphql_endpoint,omitempty"`
+	}{
+		ReportOptions:       recorded,
+		Bots:                ghra.options.Bots,
+		AnonymizeSalt:       ghra.options.AnonymizeSalt,
+		MaxCommitPages:      ghra.maxCommitPages(),
+		MaxWorkflowRunPages: ghra.maxWorkflowRunPages(),
+		SeparateSearches:    ghra.options.SeparateSearches,
+		AgeFormat:           ghra.options.AgeFormat,
+		ExcerptLength:       ghra.options.ExcerptLength,
+		PerPage:             ghra.options.PerPage,
+		PRSizeThresholds:    ghra.options.PRSizeThresholds,
+		VerifyRepos:         ghra.options.VerifyRepos,
+		APIEndpoint:         ghra.options.APIEndpoint,
+		GraphQLEndpoint:     ghra.options.GraphQLEndpoint,
+	})
+	if err != nil {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+func (ghra *GitHubRepoActivityService) buildReport(ctx context.Context) (*ActivityReport, error) {
+	if err := ghra.expandRepos(ctx); err != nil {
+		return nil, err
+	}
+
+	tracker := &rateTracker{}
+	ctx = withRateTracker(ctx, tracker)
+
+	var (
+		report *ActivityReport
+		err    error
+	)
+	if ghra.options.UseGraphQL {
+		report, err = ghra.buildGraphQLReport(ctx)
+	} else {
+		report, err = ghra.buildSearchReport(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ghra.options.IncludeClosed {
+		if err := ghra.addClosedIssues(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeMerged {
+		if err := ghra.addMergedPullRequests(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.StaleDays > 0 {
+		if err := ghra.addStale(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeApprovedUnmerged {
+		if err := ghra.addApprovedUnmerged(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeNeedsReview {
+		if err := ghra.addNeedsReview(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.ReviewRequested != "" {
+		if err := ghra.addReviewQueue(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.ExcludeOrgMembers != "" {
+		if err := ghra.hideOrgMembers(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.ProjectOwner != "" && ghra.options.ProjectNumber != 0 {
+		if err := ghra.addProjectStatus(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	report.order = ghra.repos()
+	for _, repo := range report.order {
+		if report.RepoActivityReports[repo] == nil && report.Errors[repo] == nil {
+			report.RepoActivityReports[repo] = &RepoActivityReport{}
+		}
+	}
+	if ghra.options.IncludeRepoMeta {
+		if err := ghra.addRepoMeta(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeSecurityAlerts {
+		if err := ghra.addSecurityAlerts(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeReleases || ghra.options.IncludeTags {
+		if err := ghra.addReleases(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeCommits {
+		if err := ghra.addCommitStats(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeCIStats {
+		if err := ghra.addCIStats(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeReviewerStats {
+		if err := ghra.addReviewerStats(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if ghra.options.IncludeDiscussions {
+		if err := ghra.addDiscussions(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	if len(ghra.options.IgnoreItems) > 0 {
+		ghra.ignoreItems(report)
+	}
+	if ghra.options.AnonymizeAuthors {
+		if err := ghra.anonymizeAuthors(report); err != nil {
+			return nil, err
+		}
+	}
+	report.count()
+	if ghra.options.CompareWithPrevious {
+		if err := ghra.addPreviousTotals(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	report.sortBy(ghra.options.SortBy, ghra.options.SortOrder)
+	ghra.triageGaps(report)
+	report.authorStats()
+	report.newContributors()
+	ghra.stats(report)
+	ghra.timeSeries(report)
+	report.RateLimit = tracker.RateLimit()
+	report.GeneratedAt = ghra.now()
+	report.Options = ghra.reportOptions()
+
+	return report, nil
+}
+
+// buildSearchReport builds the report with a single REST search for both
+// issues and pull requests, telling them apart by each result's
+// pull_request field, unless SeparateSearches or BaseBranch is set.
+func (ghra *GitHubRepoActivityService) buildSearchReport(ctx context.Context) (*ActivityReport, error) {
+	if ghra.options.SeparateSearches || ghra.options.BaseBranch != "" {
+		return ghra.buildSeparateSearchReport(ctx)
+	}
+
+	limiter := ghra.itemLimiter()
+	result, err := ghra.fetchLimited(ctx, "", limiter)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, prs := splitPullRequests(result.items)
+	issueErrs, err := ghra.enrichIssues(ctx, issues)
+	if err != nil {
+		return nil, err
+	}
+	prErrs, err := ghra.enrichPullRequests(ctx, prs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := ghra.assembleReport(ghra.filterIssues(issues), ghra.filterPullRequests(prs))
+	if limiter != nil {
+		limiter.apply(report)
+	}
+	report.markTruncated(result.truncated)
+	report.addErrors(result.errors)
+	report.addErrors(issueErrs)
+	report.addErrors(prErrs)
+
+	return report, nil
+}
+
+// buildSeparateSearchReport is buildSearchReport with a search for issues
+// and another for pull requests, run concurrently.
+func (ghra *GitHubRepoActivityService) buildSeparateSearchReport(ctx context.Context) (*ActivityReport, error) {
+	var issues, prs *fetchResult
+
+	limiter := ghra.itemLimiter()
+	group, gctx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		var err error
+		issues, err = ghra.fetchLimited(gctx, "issue", limiter)
+		return err
+	})
+	group.Go(func() error {
+		var err error
+		prs, err = ghra.fetchLimited(gctx, "pr", limiter)
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	issueErrs, err := ghra.enrichIssues(ctx, issues.items)
+	if err != nil {
+		return nil, err
+	}
+	prErrs, err := ghra.enrichPullRequests(ctx, prs.items)
 	if err != nil {
 		return nil, err
 	}
 
+	report := ghra.assembleReport(ghra.filterIssues(issues.items), ghra.filterPullRequests(prs.items))
+	if limiter != nil {
+		limiter.apply(report)
+	}
+	report.markTruncated(issues.truncated)
+	report.markTruncated(prs.truncated)
+	report.addErrors(issues.errors)
+	report.addErrors(prs.errors)
+	report.addErrors(issueErrs)
+	report.addErrors(prErrs)
+
+	return report, nil
+}
+
+// splitPullRequests separates the results of a combined search into issues
+// and pull requests.
+func splitPullRequests(items []IssueInfo) (issues, prs []IssueInfo) {
+	for _, item := range items {
+		if item.pullRequest {
+			prs = append(prs, item)
+		} else {
+			issues = append(issues, item)
+		}
+	}
+
+	return issues, prs
+}
+
+// addErrors records per-repo fetch errors, keeping the first error seen for
+// each repo.
+func (report *ActivityReport) addErrors(errs map[string]error) {
+	for repo, err := range errs {
+		if _, ok := report.Errors[repo]; !ok {
+			report.Errors[repo] = err
+		}
+	}
+}
+
+// canonicalizeRepos rewrites each item's Repo to the configured spelling of
+// the repo name when they differ only in case.
+func (ghra *GitHubRepoActivityService) canonicalizeRepos(items []IssueInfo) {
+	repos := ghra.repos()
+	names := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		names[strings.ToLower(repo)] = repo
+	}
+
+	for i := range items {
+		if name, ok := names[strings.ToLower(items[i].Repo)]; ok {
+			items[i].Repo = name
+		}
+		for j, ref := range items[i].ClosesIssues {
+			if name, ok := names[strings.ToLower(ref.Repo)]; ok {
+				items[i].ClosesIssues[j].Repo = name
+			}
+		}
+	}
+}
+
+// markTruncated flags the given repos as missing results.
+func (report *ActivityReport) markTruncated(repos map[string]bool) {
+	for repo := range repos {
+		if org, ok := orgScope(repo); ok {
+			report.markOrgTruncated(org)
+			continue
+		}
+		if report.RepoActivityReports[repo] == nil {
+			report.RepoActivityReports[repo] = &RepoActivityReport{}
+		}
+		report.RepoActivityReports[repo].Truncated = true
+	}
+}
+
+// assembleReport groups issues and pull requests by repo. Repo names on
+// GitHub are case-insensitive, so items are keyed by the name as configured in
+// options.Repos rather than as returned by the API.
+func (ghra *GitHubRepoActivityService) assembleReport(issues, prs []IssueInfo) *ActivityReport {
+	ghra.canonicalizeRepos(issues)
+	ghra.canonicalizeRepos(prs)
+	linkPullRequests(issues, prs)
+
 	repoReports := make(map[string]*RepoActivityReport)
-	for _, i := range *issues {
+	for _, i := range issues {
 		if repoReports[i.Repo] == nil {
 			repoReports[i.Repo] = &RepoActivityReport{}
 		}
 		repoReports[i.Repo].Issues = append(repoReports[i.Repo].Issues, i)
 	}
 
-	for _, p := range *prs {
+	for _, p := range prs {
 		if repoReports[p.Repo] == nil {
 			repoReports[p.Repo] = &RepoActivityReport{}
 		}
@@ -168,7 +1990,8 @@ func (ghra *GitHubRepoActivityService) BuildReport() (*ActivityReport, error) {
 
 	return &ActivityReport{
 		RepoActivityReports: repoReports,
-		TotalIssues:         len(*issues),
-		TotalPullRequests:   len(*prs),
-	}, nil
+		TotalIssues:         len(issues),
+		TotalPullRequests:   len(prs),
+		Errors:              make(map[string]error),
+	}
 }
@@ -0,0 +1,419 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// githubHandler is a fake GitHub API answering searches with the items of
+// each repo named in the query, keeping only issues or pull requests if the
+// query asks for them. Other requests go to other, and fail the test if it's
+// nil.
+func githubHandler(t *testing.T, items map[string][]string, other http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" {
+			if other == nil {
+				t.Errorf("unexpected request for %s", r.URL)
+				http.NotFound(w, r)
+				return
+			}
+			other.ServeHTTP(w, r)
+			return
+		}
+
+		q := strings.Fields(r.URL.Query().Get("q"))
+		var matched []string
+		for _, qualifier := range q {
+			repo := strings.TrimPrefix(qualifier, "repo:")
+			if repo == qualifier {
+				continue
+			}
+			for _, item := range items[repo] {
+				pr := strings.Contains(item, `"pull_request"`)
+				if (pr && contains(q, "is:issue")) || (!pr && contains(q, "is:pr")) {
+					continue
+				}
+				matched = append(matched, item)
+			}
+		}
+		fmt.Fprintf(w, `{"total_count": %d, "items": [%s]}`, len(matched), strings.Join(matched, ","))
+	})
+}
+
+// githubServer serves githubHandler until the test ends.
+func githubServer(t *testing.T, items map[string][]string, other http.Handler) *httptest.Server {
+	srv := httptest.NewServer(githubHandler(t, items, other))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// searchItem returns a search result for an open issue, or a pull request if
+// pr is set, created on March 9, 2024 at number o'clock.
+func searchItem(repo string, number int, pr bool) string {
+	item := fmt.Sprintf(`"number": %d, "title": "Item %d", "state": "open", "user": {"login": "octocat", "html_url": "https://github.com/octocat"}, "created_at": "2024-03-09T%02d:00:00Z", "repository_url": "https://api.github.com/repos/%s", "html_url": "https://github.com/%s/issues/%d"`,
+		number, number, number, repo, repo, number)
+	if pr {
+		item += fmt.Sprintf(`, "pull_request": {"url": "https://api.github.com/repos/%s/pulls/%d"}`, repo, number)
+	}
+
+	return "{" + item + "}"
+}
+
+// numbers returns the numbers of items in order, or nil if there are none.
+func numbers(items []IssueInfo) []int {
+	var n []int
+	for _, item := range items {
+		n = append(n, item.Number)
+	}
+
+	return n
+}
+
+// contains reports whether s contains v.
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestBuildReportFetchesConcurrently(t *testing.T) {
+	h := githubHandler(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+		"acme/docs": {searchItem("acme/docs", 3, false)},
+	}, nil)
+
+	// Each search waits for the other, so the report is only built if
+	// separate issue and pull request searches are in flight at the same
+	// time.
+	var started sync.WaitGroup
+	started.Add(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started.Done()
+		both := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(both)
+		}()
+		select {
+		case <-both:
+		case <-time.After(5 * time.Second):
+			t.Error("the issue and pull request searches didn't run concurrently")
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core", "acme/docs"},
+		DaysOld:                 7,
+		SeparateSearches:        true,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.BuildReportContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.TotalIssues != 2 || report.TotalPullRequests != 1 {
+		t.Errorf("totals = %d issues and %d pull requests, want 2 and 1", report.TotalIssues, report.TotalPullRequests)
+	}
+	want := map[string][2]string{
+		"acme/core": {"[1]", "[2]"},
+		"acme/docs": {"[3]", "[]"},
+	}
+	for repo, w := range want {
+		r := report.RepoActivityReports[repo]
+		if r == nil {
+			t.Errorf("no report for %s", repo)
+			continue
+		}
+		if got := fmt.Sprint(numbers(r.Issues)); got != w[0] {
+			t.Errorf("%s issues = %s, want %s", repo, got, w[0])
+		}
+		if got := fmt.Sprint(numbers(r.PullRequests)); got != w[1] {
+			t.Errorf("%s pull requests = %s, want %s", repo, got, w[1])
+		}
+	}
+}
+
+func TestBuildReportSplitsLongQueries(t *testing.T) {
+	var repos []string
+	items := make(map[string][]string)
+	for i := 1; i <= 30; i++ {
+		repo := fmt.Sprintf("acme-corporation/service-%02d", i)
+		repos = append(repos, repo)
+		items[repo] = []string{searchItem(repo, 1, false)}
+	}
+	h := githubHandler(t, items, nil)
+
+	// GitHub rejects queries over the length limit with a 422.
+	var mu sync.Mutex
+	queries := make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		mu.Lock()
+		queries[q] = true
+		mu.Unlock()
+
+		if len(q) > maxQueryLength {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Validation Failed"}`)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   repos,
+		DaysOld:                 7,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.BuildReportContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) <= 2 {
+		t.Errorf("made %d distinct searches, want the repos split across several", len(queries))
+	}
+	searched := make(map[string]int)
+	for q := range queries {
+		if len(q) > maxQueryLength {
+			t.Errorf("query is %d characters long: %q", len(q), q)
+		}
+		for _, f := range strings.Fields(q) {
+			if strings.HasPrefix(f, "repo:") {
+				searched[strings.TrimPrefix(f, "repo:")]++
+			}
+		}
+	}
+	for _, repo := range repos {
+		if searched[repo] != 1 {
+			t.Errorf("%s was searched %d times, want once", repo, searched[repo])
+		}
+		if r := report.RepoActivityReports[repo]; r == nil || len(r.Issues) != 1 {
+			t.Errorf("%s is missing its issue", repo)
+		}
+	}
+}
+
+func TestBuildQueryCutoff(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		daysOld  int
+		timezone *time.Location
+		want     string
+	}{
+		{
+			name: "today",
+			now:  time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC),
+			want: "is:issue repo:acme/core created:>=2024-03-10T12:34:00Z",
+		},
+		{
+			name:    "one day",
+			now:     time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC),
+			daysOld: 1,
+			want:    "is:issue repo:acme/core created:>=2024-03-09T12:34:00Z",
+		},
+		{
+			name:    "a week",
+			now:     time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC),
+			daysOld: 7,
+			want:    "is:issue repo:acme/core created:>=2024-03-03T12:34:00Z",
+		},
+		{
+			name:    "just after midnight",
+			now:     time.Date(2024, 3, 1, 0, 0, 1, 0, time.UTC),
+			daysOld: 1,
+			want:    "is:issue repo:acme/core created:>=2024-02-29T00:00:00Z",
+		},
+		{
+			name:     "UTC calendar days",
+			now:      time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC),
+			daysOld:  1,
+			timezone: time.UTC,
+			want:     "is:issue repo:acme/core created:>=2024-03-09T00:00:00Z",
+		},
+		{
+			// It's still March 9 in New York, on the day before the
+			// switch to daylight saving time.
+			name:     "New York calendar days",
+			now:      time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC),
+			daysOld:  1,
+			timezone: newYork,
+			want:     "is:issue repo:acme/core created:>=2024-03-08T05:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Repos:    []string{"acme/core"},
+				DaysOld:  tt.daysOld,
+				Timezone: tt.timezone,
+				Now:      func() time.Time { return tt.now },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.BuildQuery("issue"); got != tt.want {
+				t.Errorf("BuildQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	tests := []struct {
+		format  string
+		created time.Time
+		want    string
+	}{
+		{created: now, want: "0 seconds"},
+		{created: now.Add(-3 * time.Minute), want: "3 minutes"},
+		{created: now.Add(-13 * time.Hour), want: "13 hours"},
+		{created: now.AddDate(0, 0, -7), want: "1 week"},
+		{created: now.AddDate(0, 0, -9).Add(-5 * time.Hour), want: "1 week"},
+		// Clock skew can make an item look like it was created in the
+		// future.
+		{created: now.Add(time.Minute), want: "0 seconds"},
+		{format: AgeFormatFull, created: now.Add(-3 * time.Minute), want: "0 seconds"},
+		{format: AgeFormatFull, created: now.Add(-13 * time.Hour), want: "1 day"},
+		{format: AgeFormatFull, created: now.AddDate(0, 0, -9).Add(-5 * time.Hour), want: "1 week 2 days"},
+	}
+
+	for _, tt := range tests {
+		s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+			AgeFormat: tt.format,
+			Now:       func() time.Time { return now },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.formatAge(tt.created); got != tt.want {
+			t.Errorf("%q age of an item created %v before now = %q, want %q", tt.format, now.Sub(tt.created), got, tt.want)
+		}
+	}
+}
+
+func TestAgeDuration(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	// Item 12 was created exactly 1 day before now, just inside the window
+	// that starts at the minute before.
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {strings.Replace(searchItem("acme/core", 12, false), "T12:00:00Z", "T12:34:56Z", 1)},
+	}, nil)
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		DaysOld:                 1,
+		Now:                     func() time.Time { return now },
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := s.ListIssues(context.Background(), "issue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+
+	item := items[0]
+	if item.AgeDuration != 24*time.Hour || item.AgeSeconds != 86400 || item.Age != "1 day" {
+		t.Errorf("age = %v, %d seconds, %q, want 24h, 86400 seconds, \"1 day\"", item.AgeDuration, item.AgeSeconds, item.Age)
+	}
+}
+
+func TestInvalidAPIEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"github.example.com/api/v3", "://github.example.com", "/api/v3/"} {
+		if _, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{APIEndpoint: endpoint}); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("APIEndpoint %q gave %v, want ErrInvalidOption", endpoint, err)
+		}
+	}
+}
+
+func TestExtraQuery(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	tests := []struct {
+		name    string
+		options GitHubRepoActivityOptions
+		want    string
+	}{
+		{
+			name:    "empty",
+			options: GitHubRepoActivityOptions{},
+			want:    "is:issue repo:acme/core created:>=2024-03-09T12:34:00Z",
+		},
+		{
+			name:    "only whitespace",
+			options: GitHubRepoActivityOptions{ExtraQuery: " \t "},
+			want:    "is:issue repo:acme/core created:>=2024-03-09T12:34:00Z",
+		},
+		{
+			name:    "after the other qualifiers",
+			options: GitHubRepoActivityOptions{State: StateOpen, Authors: []string{"octocat"}, ExtraQuery: "linked:pr"},
+			want:    "is:issue repo:acme/core created:>=2024-03-09T12:34:00Z is:open author:octocat linked:pr",
+		},
+		{
+			name:    "whitespace collapsed",
+			options: GitHubRepoActivityOptions{ExtraQuery: "  linked:pr \t no:assignee "},
+			want:    "is:issue repo:acme/core created:>=2024-03-09T12:34:00Z linked:pr no:assignee",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.Repos = []string{"acme/core"}
+			options.DaysOld = 1
+			options.Now = func() time.Time { return now }
+
+			s, err := NewGitHubRepoActivityService(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.BuildQuery("issue"); got != tt.want {
+				t.Errorf("BuildQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtraQueryNewline(t *testing.T) {
+	_, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{ExtraQuery: "linked:pr\nno:assignee"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("NewGitHubRepoActivityService() = %v, want ErrInvalidOption", err)
+	}
+}
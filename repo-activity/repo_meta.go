@@ -0,0 +1,97 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// RepoMeta describes a repo as a whole, rather than its activity during the
+// period.
+type RepoMeta struct {
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	Language    string `json:"language,omitempty"`
+	// DefaultBranch is the branch CIStats counts workflow runs on.
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Stars         int    `json:"stars"`
+	Forks         int    `json:"forks"`
+	// OpenIssues is GitHub's count of open issues, which includes open pull
+	// requests.
+	OpenIssues int  `json:"open_issues"`
+	Archived   bool `json:"archived,omitempty"`
+}
+
+// addRepoMeta fills in each repo's Meta. Repos are looked up Concurrency at
+// a time, and a repo that fails is recorded in the report's Errors.
+func (ghra *GitHubRepoActivityService) addRepoMeta(ctx context.Context, report *ActivityReport) error {
+	errs, err := ghra.eachRepo(ctx, report.Repos(), func(ctx context.Context, repo string) error {
+		owner, name, ok := splitRepo(repo)
+		if !ok {
+			return nil
+		}
+		r := report.RepoActivityReports[repo]
+
+		meta, err := ghra.repoMeta(ctx, owner, name)
+		if err != nil {
+			return fmt.Errorf("getting repo: %w", err)
+		}
+
+		r.Meta = meta
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.addErrors(errs)
+
+	return nil
+}
+
+// repoMeta returns the RepoMeta of owner/name. It's reused for lookupTTL by
+// the service and those made from it with WithOptions.
+func (ghra *GitHubRepoActivityService) repoMeta(ctx context.Context, owner, name string) (*RepoMeta, error) {
+	key := strings.ToLower(owner + "/" + name)
+	l := ghra.lookups
+	l.metaMu.Lock()
+	entry, ok := l.meta[key]
+	l.metaMu.Unlock()
+	if ok && ghra.now().Sub(entry.fetchedAt) < lookupTTL {
+		return entry.meta, nil
+	}
+
+	var repo *github.Repository
+	_, err := ghra.call(ctx, func() (*github.Response, error) {
+		var (
+			resp *github.Response
+			err  error
+		)
+		repo, resp, err = ghra.client.Repositories.Get(ctx, owner, name)
+		return resp, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &RepoMeta{
+		Description:   repo.GetDescription(),
+		URL:           repo.GetHTMLURL(),
+		Language:      repo.GetLanguage(),
+		DefaultBranch: repo.GetDefaultBranch(),
+		Stars:         repo.GetStargazersCount(),
+		Forks:         repo.GetForksCount(),
+		OpenIssues:    repo.GetOpenIssuesCount(),
+		Archived:      repo.GetArchived(),
+	}
+
+	l.metaMu.Lock()
+	defer l.metaMu.Unlock()
+	if l.meta == nil {
+		l.meta = make(map[string]*repoMetaEntry)
+	}
+	l.meta[key] = &repoMetaEntry{meta: meta, fetchedAt: ghra.now()}
+
+	return meta, nil
+}
@@ -0,0 +1,64 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRepoMetaSharedByDerivedServices(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		gets int
+	)
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		gets++
+		mu.Unlock()
+		fmt.Fprint(w, `{"full_name": "acme/core", "stargazers_count": 42}`)
+	}))
+
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	options := GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		IncludeRepoMeta:         true,
+		Now:                     func() time.Time { return now },
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	}
+	s, err := NewGitHubRepoActivityService(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(daysOld int) {
+		t.Helper()
+		o := options
+		o.DaysOld = daysOld
+		derived, err := s.WithOptions(&o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := derived.BuildReportContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta := r.RepoActivityReports["acme/core"].Meta; meta == nil || meta.Stars != 42 {
+			t.Errorf("Meta = %+v, want 42 stars", meta)
+		}
+	}
+
+	report(7)
+	report(30)
+	now = now.Add(lookupTTL)
+	report(7)
+
+	if gets != 2 {
+		t.Errorf("repo fetched %d times, want 2", gets)
+	}
+}
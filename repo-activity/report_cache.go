@@ -0,0 +1,76 @@
+package ghra
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ReportCache holds built reports for a fixed TTL, keyed on the search
+// queries used to build them. The reports are stored as snapshots in a
+// Cache, so reports read back from it have their Errors restored as in
+// ReadSnapshot. It is safe for concurrent use and can be shared between
+// services.
+type ReportCache struct {
+	ttl     time.Duration
+	backend Cache
+
+	mu         sync.Mutex
+	generation int
+}
+
+// NewReportCache returns a ReportCache that stores the reports in backend,
+// expiring after ttl.
+func NewReportCache(backend Cache, ttl time.Duration) *ReportCache {
+	return &ReportCache{
+		ttl:     ttl,
+		backend: backend,
+	}
+}
+
+// Get returns the report cached under key if it hasn't expired.
+func (c *ReportCache) Get(key string) (*ActivityReport, bool) {
+	b, ok := c.backend.Get(c.backendKey(key))
+	if !ok {
+		return nil, false
+	}
+	report, err := ReadSnapshot(bytes.NewReader(b))
+	if err != nil {
+		return nil, false
+	}
+
+	return report, true
+}
+
+// Set caches report under key.
+func (c *ReportCache) Set(key string, report *ActivityReport) {
+	var b bytes.Buffer
+	if err := report.WriteSnapshot(&b); err != nil {
+		return
+	}
+
+	c.backend.Set(c.backendKey(key), b.Bytes(), c.ttl)
+}
+
+// Invalidate drops every cached report. Reports in a backend shared with
+// other processes are only dropped for this one.
+func (c *ReportCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+}
+
+// backendKey returns the backend key for a report key. Report keys hold
+// every query and can be long, so they're hashed.
+func (c *ReportCache) backendKey(key string) string {
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(key))
+	return "report:" + strconv.Itoa(generation) + ":" + hex.EncodeToString(sum[:])
+}
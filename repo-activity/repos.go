@@ -0,0 +1,279 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/github"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// isRepoPattern reports whether repo contains glob wildcards.
+func isRepoPattern(repo string) bool {
+	return strings.ContainsAny(repo, "*?[")
+}
+
+// matchRepo reports whether repo matches the glob pattern. Repo names on
+// GitHub are case-insensitive, and so is matching.
+func matchRepo(pattern, repo string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(repo))
+	return err == nil && ok
+}
+
+// excludedRepo reports whether repo matches any of ExcludeRepos.
+func (ghra *GitHubRepoActivityService) excludedRepo(repo string) bool {
+	for _, pattern := range ghra.options.ExcludeRepos {
+		if matchRepo(pattern, repo) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// repoPatternKey identifies the options that Repos' expansion depends on.
+func (ghra *GitHubRepoActivityService) repoPatternKey() string {
+	return fmt.Sprintf("%q %q %t", ghra.options.Repos, ghra.options.ExcludeRepos, ghra.options.IncludeArchived)
+}
+
+// repos returns the repos to report on: Repos with their patterns expanded
+// once expandRepos has run, or only the repos named outright before then.
+// Repos matching ExcludeRepos are left out either way.
+func (ghra *GitHubRepoActivityService) repos() []string {
+	l := ghra.lookups
+	l.reposMu.Lock()
+	defer l.reposMu.Unlock()
+	if list, ok := l.expanded[ghra.repoPatternKey()]; ok {
+		return list.repos
+	}
+
+	var repos []string
+	for _, repo := range ghra.options.Repos {
+		if !isRepoPattern(repo) && !ghra.excludedRepo(repo) {
+			repos = append(repos, repo)
+		}
+	}
+
+	return repos
+}
+
+// expandRepos expands the glob patterns in Repos, such as
+// "digitalocean/terraform-provider-*", by listing the repos of each
+// pattern's owner. Archived repos only match with IncludeArchived. The
+// expansion is reused for lookupTTL by the service and those made from it
+// with WithOptions.
+func (ghra *GitHubRepoActivityService) expandRepos(ctx context.Context) error {
+	key := ghra.repoPatternKey()
+	l := ghra.lookups
+	l.reposMu.Lock()
+	defer l.reposMu.Unlock()
+	if list, ok := l.expanded[key]; ok && ghra.now().Sub(list.fetchedAt) < lookupTTL {
+		return nil
+	}
+
+	expanded := []string{}
+	seen := make(map[string]bool)
+	add := func(repo string) {
+		if key := strings.ToLower(repo); !seen[key] && !ghra.excludedRepo(repo) {
+			seen[key] = true
+			expanded = append(expanded, repo)
+		}
+	}
+
+	owners := make(map[string][]*github.Repository)
+	for _, repo := range ghra.options.Repos {
+		if !isRepoPattern(repo) {
+			add(repo)
+			continue
+		}
+
+		owner, _, ok := splitRepo(repo)
+		if !ok || isRepoPattern(owner) {
+			return fmt.Errorf("%w: repo pattern %q can only have wildcards in the repo name", ErrInvalidOption, repo)
+		}
+		listed, ok := owners[strings.ToLower(owner)]
+		if !ok {
+			var err error
+			listed, err = ghra.listOwnerRepos(ctx, owner)
+			if err != nil {
+				return fmt.Errorf("listing repos for %s: %w", repo, err)
+			}
+			owners[strings.ToLower(owner)] = listed
+		}
+
+		var matched []string
+		for _, r := range listed {
+			if r.GetArchived() && !ghra.options.IncludeArchived {
+				continue
+			}
+			if name := r.GetFullName(); matchRepo(repo, name) {
+				matched = append(matched, name)
+				add(name)
+			}
+		}
+		ghra.logger().WithFields(log.Fields{
+			"pattern": repo,
+			"matched": matched,
+		}).Info("expanded repo pattern")
+	}
+
+	if l.expanded == nil {
+		l.expanded = make(map[string]*repoList)
+	}
+	l.expanded[key] = &repoList{repos: expanded, fetchedAt: ghra.now()}
+
+	return nil
+}
+
+// listOwnerRepos lists every repo of owner, which may be an org or a user.
+func (ghra *GitHubRepoActivityService) listOwnerRepos(ctx context.Context, owner string) ([]*github.Repository, error) {
+	var all []*github.Repository
+
+	orgOpt := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: maxPerPage}}
+	for {
+		var repos []*github.Repository
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			repos, resp, err = ghra.client.Repositories.ListByOrg(ctx, owner, orgOpt)
+			return resp, err
+		})
+		if statusCode(err) == http.StatusNotFound {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		all = append(all, repos...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		orgOpt.Page = resp.NextPage
+	}
+
+	// Not an org, so list the user's repos instead.
+	userOpt := &github.RepositoryListOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: maxPerPage}}
+	for {
+		var repos []*github.Repository
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			repos, resp, err = ghra.client.Repositories.List(ctx, owner, userOpt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, repos...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		userOpt.Page = resp.NextPage
+	}
+}
+
+// filterExcludedRepos drops the items in repos matching ExcludeRepos, which
+// can still be found through Orgs.
+func (ghra *GitHubRepoActivityService) filterExcludedRepos(items []IssueInfo) []IssueInfo {
+	if len(ghra.options.ExcludeRepos) == 0 {
+		return items
+	}
+
+	filtered := items[:0]
+	for _, i := range items {
+		if !ghra.excludedRepo(i.Repo) {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
+
+// eachRepo calls fn for every repo in repos, Concurrency repos at a time.
+// The errors of repos that fail are returned keyed by repo, unless the
+// context is done, in which case its error is returned instead.
+func (ghra *GitHubRepoActivityService) eachRepo(ctx context.Context, repos []string, fn func(ctx context.Context, repo string) error) (map[string]error, error) {
+	var mu sync.Mutex
+	errs := make(map[string]error)
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(ghra.concurrency())
+	for _, repo := range repos {
+		repo := repo
+		group.Go(func() error {
+			if err := fn(ctx, repo); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				mu.Lock()
+				errs[repo] = err
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// eachItem calls fn for every item of a GitHub repo, Concurrency items at a
+// time. An item that fails doesn't stop the others: the first error of each
+// repo is returned keyed by repo, unless the context is done, in which case
+// its error is returned instead.
+func (ghra *GitHubRepoActivityService) eachItem(ctx context.Context, items []IssueInfo, fn func(ctx context.Context, item *IssueInfo, owner, name string) error) (map[string]error, error) {
+	var mu sync.Mutex
+	errs := make(map[string]error)
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(ghra.concurrency())
+	for i := range items {
+		item := &items[i]
+		owner, name, ok := splitRepo(item.Repo)
+		if !ok {
+			continue
+		}
+
+		group.Go(func() error {
+			if err := fn(ctx, item, owner, name); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				mu.Lock()
+				if _, ok := errs[item.Repo]; !ok {
+					errs[item.Repo] = err
+				}
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// prefixRepos returns errs with prefix added to each repo, for the GitLab
+// and Gitea repos keyed by their prefixed name.
+func prefixRepos(prefix string, errs map[string]error) map[string]error {
+	prefixed := make(map[string]error, len(errs))
+	for repo, err := range errs {
+		prefixed[prefix+repo] = err
+	}
+
+	return prefixed
+}
@@ -0,0 +1,65 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRepoPatternsSharedByDerivedServices(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		lists int
+	)
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lists++
+		mu.Unlock()
+		fmt.Fprint(w, `[{"full_name": "acme/core"}, {"full_name": "acme/old", "archived": true}]`)
+	}))
+
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	options := GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/*"},
+		DaysOld:                 7,
+		Now:                     func() time.Time { return now },
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	}
+	s, err := NewGitHubRepoActivityService(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(includeArchived bool) {
+		t.Helper()
+		o := options
+		o.IncludeArchived = includeArchived
+		derived, err := s.WithOptions(&o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := derived.BuildReportContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.TotalIssues != 1 || r.TotalPullRequests != 1 {
+			t.Errorf("totals = %d issues and %d pull requests, want 1 each", r.TotalIssues, r.TotalPullRequests)
+		}
+	}
+
+	report(false)
+	report(false)
+	report(true)
+	now = now.Add(lookupTTL)
+	report(false)
+
+	if lists != 3 {
+		t.Errorf("repos listed %d times, want 3", lists)
+	}
+}
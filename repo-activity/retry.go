@@ -0,0 +1,92 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	// defaultMaxRetries is the number of times a request failing with a
+	// server error is retried when MaxRetries is unset.
+	defaultMaxRetries = 3
+
+	// retryBaseDelay is the backoff before the first retry; it doubles on
+	// every subsequent attempt.
+	retryBaseDelay = 500 * time.Millisecond
+)
+
+// call invokes fn, retrying it when it fails with a rate limit error or a
+// transient server error. Other errors are returned immediately. Returned
+// errors are wrapped with classifyError.
+func (ghra *GitHubRepoActivityService) call(ctx context.Context, fn func() (*github.Response, error)) (*github.Response, error) {
+	resp, err := ghra.retry(ctx, fn)
+	if err != nil {
+		return resp, classifyError(err)
+	}
+
+	return resp, nil
+}
+
+func (ghra *GitHubRepoActivityService) retry(ctx context.Context, fn func() (*github.Response, error)) (*github.Response, error) {
+	rateRetries, serverRetries := 0, 0
+	for {
+		resp, err := fn()
+		if err == nil {
+			return resp, nil
+		}
+
+		wait, ok := ghra.rateLimitDelay(err)
+		switch {
+		case ok && rateRetries < maxRateLimitRetries:
+			rateRetries++
+		case isServerError(err):
+			if serverRetries >= ghra.maxRetries() {
+				if serverRetries > 0 {
+					return resp, fmt.Errorf("giving up after %d attempts: %w", serverRetries+1, err)
+				}
+				return resp, err
+			}
+			wait = backoff(serverRetries)
+			serverRetries++
+		default:
+			return resp, err
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return resp, err
+		}
+	}
+}
+
+func (ghra *GitHubRepoActivityService) maxRetries() int {
+	switch {
+	case ghra.options.MaxRetries < 0:
+		return 0
+	case ghra.options.MaxRetries == 0:
+		return defaultMaxRetries
+	default:
+		return ghra.options.MaxRetries
+	}
+}
+
+// isServerError reports whether err is a 5xx response from the API.
+func isServerError(err error) bool {
+	return statusCode(err) >= http.StatusInternalServerError
+}
+
+// isValidationError reports whether err is a 422 response, which search
+// returns when a query names repos that don't exist or can't be seen.
+func isValidationError(err error) bool {
+	return statusCode(err) == http.StatusUnprocessableEntity
+}
+
+// backoff returns an exponentially increasing delay with up to 50% jitter.
+func backoff(attempt int) time.Duration {
+	d := retryBaseDelay << uint(attempt)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,101 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestBuildReportRetriesServerErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantErr    bool
+	}{
+		{
+			name:     "fails twice then succeeds",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+		},
+		{
+			name:       "retries disabled",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxRetries: -1,
+			wantErr:    true,
+		},
+		{
+			name:     "not retryable",
+			statuses: []int{http.StatusUnprocessableEntity, http.StatusUnprocessableEntity},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := githubHandler(t, map[string][]string{
+				"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+			}, nil)
+
+			// The first requests fail with statuses, whichever search
+			// makes them.
+			var mu sync.Mutex
+			failures := tt.statuses
+			requests := make(map[string]int)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests[r.URL.Query().Get("q")]++
+				var status int
+				if len(failures) > 0 {
+					status, failures = failures[0], failures[1:]
+				}
+				mu.Unlock()
+
+				if status != 0 {
+					w.WriteHeader(status)
+					fmt.Fprint(w, `{"message": "Something went wrong"}`)
+					return
+				}
+				h.ServeHTTP(w, r)
+			}))
+			defer srv.Close()
+
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Repos:                   []string{"acme/core"},
+				DaysOld:                 7,
+				MaxRetries:              tt.maxRetries,
+				SearchRequestsPerMinute: -1,
+				APIEndpoint:             srv.URL + "/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := s.BuildReportContext(context.Background())
+			switch {
+			case tt.wantErr && err == nil:
+				t.Error("BuildReportContext() succeeded, want an error")
+			case !tt.wantErr && err != nil:
+				t.Fatalf("BuildReportContext() = %v, want a report", err)
+			case !tt.wantErr && (report.TotalIssues != 1 || report.TotalPullRequests != 1):
+				t.Errorf("totals = %d issues and %d pull requests, want 1 each", report.TotalIssues, report.TotalPullRequests)
+			}
+
+			// Each search is retried until it succeeds, or not at all when
+			// the report fails.
+			mu.Lock()
+			defer mu.Unlock()
+			total := 0
+			for q, n := range requests {
+				total += n
+				if tt.wantErr && n > 1 {
+					t.Errorf("%q was requested %d times, want once", q, n)
+				}
+			}
+			if want := len(tt.statuses) + 1; !tt.wantErr && total != want {
+				t.Errorf("made %d requests, want %d", total, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// reviewRequestedQualifier returns the qualifier matching the pull requests
+// whose review is requested from reviewer, a login or an org/team.
+func reviewRequestedQualifier(reviewer string) string {
+	if strings.Contains(reviewer, "/") {
+		return "team-review-requested:" + reviewer
+	}
+
+	return "review-requested:" + reviewer
+}
+
+// buildReviewQueueQuery builds a query for the open pull requests in
+// repoNames whose review is requested from ReviewRequested.
+func (ghra *GitHubRepoActivityService) buildReviewQueueQuery(repoNames []string) string {
+	repos := scopeQualifiers(repoNames)
+
+	query := fmt.Sprintf("is:pr is:open %s %s", reviewRequestedQualifier(ghra.options.ReviewRequested), strings.Join(repos, " "))
+	if q := ghra.qualifiers("pr"); len(q) > 0 {
+		query += " " + strings.Join(q, " ")
+	}
+
+	return query
+}
+
+// addReviewQueue searches for the open pull requests whose review is
+// requested from ReviewRequested and sets the report's ReviewQueue, those
+// waiting longest first. Unlike the other sections it isn't limited to the
+// report window, since a request stays pending however old it is.
+func (ghra *GitHubRepoActivityService) addReviewQueue(ctx context.Context, report *ActivityReport) error {
+	var search searchFunc = ghra.searchIssues
+	if ghra.options.UseGraphQL {
+		search = ghra.searchGraphQL
+	}
+
+	queue, err := ghra.fetchBatches(ctx, ghra.batches("pr"), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		var items []IssueInfo
+		truncated := false
+		seen := make(map[string]bool)
+		for _, q := range ghra.labelQualifiers() {
+			found, total, err := withQualifier(search, q)(ctx, ghra.buildReviewQueueQuery(repos))
+			if err != nil {
+				return nil, false, err
+			}
+			truncated = truncated || total > len(found)
+			items = mergeItems(items, seen, found)
+		}
+		return items, truncated, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	prs := queue.items
+	ghra.canonicalizeRepos(prs)
+	sort.SliceStable(prs, func(i, j int) bool {
+		return prs[i].AgeDuration > prs[j].AgeDuration
+	})
+	report.ReviewQueue = prs
+	report.markTruncated(queue.truncated)
+	report.addErrors(queue.errors)
+
+	return nil
+}
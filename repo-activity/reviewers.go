@@ -0,0 +1,198 @@
+package ghra
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// reviewersGraphQLQuery searches pull requests with the GraphQL API for who
+// reviewed them and when, which takes a single request per page of pull
+// requests rather than one per pull request through the REST API.
+const reviewersGraphQLQuery = `query($query: String!, $first: Int!, $after: String) {
+  search(query: $query, type: ISSUE, first: $first, after: $after) {
+    issueCount
+    pageInfo {
+      hasNextPage
+      endCursor
+    }
+    nodes {
+      ... on PullRequest {
+        url
+        author { __typename login url }
+        repository { nameWithOwner }
+        reviews(first: 100) { nodes { author { __typename login url } state submittedAt } }
+      }
+    }
+  }
+}`
+
+type graphQLReviewersResponse struct {
+	Data struct {
+		Search struct {
+			IssueCount int `json:"issueCount"`
+			PageInfo   struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				URL        string        `json:"url"`
+				Author     *graphQLActor `json:"author"`
+				Repository struct {
+					NameWithOwner string `json:"nameWithOwner"`
+				} `json:"repository"`
+				Reviews struct {
+					Nodes []struct {
+						Author      *graphQLActor `json:"author"`
+						State       string        `json:"state"`
+						SubmittedAt time.Time     `json:"submittedAt"`
+					} `json:"nodes"`
+				} `json:"reviews"`
+			} `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+func (r *graphQLReviewersResponse) graphQLErrors() []graphQLError {
+	return r.Errors
+}
+
+// ReviewerCount is the number of reviews a reviewer submitted.
+type ReviewerCount struct {
+	Login   string `json:"login"`
+	Reviews int    `json:"reviews"`
+}
+
+// TopReviewers orders stats, as in ReviewerStats, by the number of reviews,
+// most first, and then by login.
+func TopReviewers(stats map[string]int) []ReviewerCount {
+	counts := make([]ReviewerCount, 0, len(stats))
+	for login, n := range stats {
+		counts = append(counts, ReviewerCount{Login: login, Reviews: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Reviews != counts[j].Reviews {
+			return counts[i].Reviews > counts[j].Reviews
+		}
+		return strings.ToLower(counts[i].Login) < strings.ToLower(counts[j].Login)
+	})
+
+	return counts
+}
+
+// addReviewerStats searches for the pull requests updated within the report
+// window and counts the reviews submitted on them during the window by each
+// reviewer, across all repos and for each repo. Reviews left by a pull
+// request's author on their own pull request aren't counted.
+func (ghra *GitHubRepoActivityService) addReviewerStats(ctx context.Context, report *ActivityReport) error {
+	since, until := ghra.window()
+	prs, err := ghra.fetchBatches(ctx, ghra.batches("pr"), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		return ghra.searchLabels(ctx, paged(ghra.searchReviewers), "pr", "updated", repos, since, until)
+	})
+	if err != nil {
+		return err
+	}
+
+	ghra.canonicalizeRepos(prs.items)
+	report.ReviewerStats = make(map[string]int)
+	for _, pr := range prs.items {
+		r := report.RepoActivityReports[pr.Repo]
+		if r == nil {
+			r = &RepoActivityReport{}
+			report.RepoActivityReports[pr.Repo] = r
+		}
+		if r.ReviewerStats == nil {
+			r.ReviewerStats = make(map[string]int)
+		}
+		for login, n := range pr.reviewCounts {
+			r.ReviewerStats[login] += n
+			report.ReviewerStats[login] += n
+		}
+	}
+	report.markTruncated(prs.truncated)
+	report.addErrors(prs.errors)
+
+	return nil
+}
+
+// searchReviewers runs a single search query for pull requests against the
+// GraphQL API, walking every page of results. Only each item's Repo, URL,
+// Author, and reviewCounts are set.
+func (ghra *GitHubRepoActivityService) searchReviewers(ctx context.Context, query string) ([]IssueInfo, int, error) {
+	variables := map[string]interface{}{
+		"query": query,
+		"first": ghra.perPage(),
+	}
+
+	logger := ghra.logger().WithField("query", query)
+	logger.Debug("searching reviewers with GraphQL")
+
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+
+	items := []IssueInfo{}
+	total := 0
+	pages := 0
+	for {
+		var result graphQLReviewersResponse
+		_, err := ghra.call(ctx, func() (*github.Response, error) {
+			if err := ghra.throttle(ctx); err != nil {
+				return nil, err
+			}
+			return ghra.observeSearch(ctx, query, pages+1, func() (*github.Response, error) {
+				return ghra.doGraphQL(ctx, reviewersGraphQLQuery, variables, &result)
+			})
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		search := result.Data.Search
+		total = search.IssueCount
+		pages++
+		logger.WithFields(log.Fields{
+			"page":  pages,
+			"items": len(search.Nodes),
+		}).Debug("fetched search page")
+
+		for _, node := range search.Nodes {
+			prAuthor := graphQLAuthor(node.Author)
+			counts := make(map[string]int)
+			for _, review := range node.Reviews.Nodes {
+				// Pending reviews haven't been submitted yet.
+				if review.State == "PENDING" || review.SubmittedAt.Before(since) || review.SubmittedAt.After(until) {
+					continue
+				}
+				reviewer := graphQLAuthor(review.Author)
+				if strings.EqualFold(reviewer.DisplayName, prAuthor.DisplayName) {
+					continue
+				}
+				if ghra.options.ExcludeBots && isBot(reviewer.DisplayName) {
+					continue
+				}
+				counts[reviewer.DisplayName]++
+			}
+
+			items = append(items, IssueInfo{
+				Repo:         node.Repository.NameWithOwner,
+				URL:          node.URL,
+				Author:       prAuthor,
+				reviewCounts: counts,
+			})
+		}
+
+		if !search.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = search.PageInfo.EndCursor
+	}
+
+	return items, total, nil
+}
@@ -0,0 +1,88 @@
+package ghra
+
+import (
+	"context"
+
+	"github.com/google/go-github/github"
+)
+
+// Review statuses for IssueInfo.ReviewStatus.
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	// ReviewPending means the pull request has reviews, but none approving
+	// or requesting changes.
+	ReviewPending = "PENDING"
+	ReviewNone    = "NONE"
+)
+
+func (ghra *GitHubRepoActivityService) includeReviewStatus() bool {
+	return ghra.options.IncludeReviewStatus || ghra.options.OnlyUnreviewed
+}
+
+// fetchReviewStatus lists a pull request's reviews and collapses them into a
+// single status.
+func (ghra *GitHubRepoActivityService) fetchReviewStatus(ctx context.Context, owner, name string, number int) (string, error) {
+	opt := &github.ListOptions{PerPage: maxPerPage}
+
+	var states []reviewState
+	for {
+		var reviews []*github.PullRequestReview
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			var (
+				resp *github.Response
+				err  error
+			)
+			reviews, resp, err = ghra.client.PullRequests.ListReviews(ctx, owner, name, number, opt)
+			return resp, err
+		})
+		if err != nil {
+			return "", err
+		}
+
+		for _, r := range reviews {
+			states = append(states, reviewState{reviewer: r.GetUser().GetLogin(), state: r.GetState()})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return reviewStatus(states), nil
+}
+
+type reviewState struct {
+	reviewer string
+	state    string
+}
+
+// reviewStatus collapses reviews, oldest first, into a single status using
+// each reviewer's latest approval or change request. Comments don't replace
+// an earlier verdict, but a dismissal clears it.
+func reviewStatus(reviews []reviewState) string {
+	if len(reviews) == 0 {
+		return ReviewNone
+	}
+
+	latest := make(map[string]string)
+	for _, r := range reviews {
+		switch r.state {
+		case ReviewApproved, ReviewChangesRequested:
+			latest[r.reviewer] = r.state
+		case "DISMISSED":
+			delete(latest, r.reviewer)
+		}
+	}
+
+	status := ReviewPending
+	for _, state := range latest {
+		if state == ReviewChangesRequested {
+			return ReviewChangesRequested
+		}
+		status = ReviewApproved
+	}
+
+	return status
+}
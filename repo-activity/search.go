@@ -0,0 +1,109 @@
+package ghra
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// searchIssuesResult is the issue search response. It is decoded here rather
+// than by the go-github client so fields it doesn't know about yet, such as
+// author_association, are available.
+type searchIssuesResult struct {
+	Total  int           `json:"total_count"`
+	Issues []searchIssue `json:"items"`
+}
+
+type searchIssue struct {
+	github.Issue
+	AuthorAssociation string `json:"author_association"`
+	Draft             bool   `json:"draft"`
+	StateReason       string `json:"state_reason"`
+	// PullRequest is only set for pull requests.
+	PullRequest *searchPullRequest `json:"pull_request"`
+}
+
+type searchPullRequest struct {
+	MergedAt *time.Time
+	// hasMergedAt is set when the response included merged_at, even as
+	// null. Older GitHub Enterprise releases leave it out.
+	hasMergedAt bool
+}
+
+func (pr *searchPullRequest) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	raw, ok := fields["merged_at"]
+	if !ok {
+		return nil
+	}
+	pr.hasMergedAt = true
+
+	return json.Unmarshal(raw, &pr.MergedAt)
+}
+
+// searchIssuesPage fetches a single page of issue search results.
+func (ghra *GitHubRepoActivityService) searchIssuesPage(ctx context.Context, query string, opt *github.SearchOptions) (*searchIssuesResult, *github.Response, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	if opt.PerPage != 0 {
+		params.Set("per_page", strconv.Itoa(opt.PerPage))
+	}
+	if opt.Page != 0 {
+		params.Set("page", strconv.Itoa(opt.Page))
+	}
+
+	req, err := ghra.client.NewRequest("GET", "search/issues?"+params.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &searchIssuesResult{}
+	resp, err := ghra.client.Do(ctx, req, result)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return result, resp, nil
+}
+
+// mergedAt returns when a pull request from the search results was merged,
+// or the zero time if it wasn't. Where the search results don't say, closed
+// pull requests are looked up individually; with a ResponseCache repeat
+// lookups are conditional and don't count against the rate limit.
+func (ghra *GitHubRepoActivityService) mergedAt(ctx context.Context, issue searchIssue, repo string) (time.Time, error) {
+	pr := issue.PullRequest
+	if pr.hasMergedAt || issue.GetState() != "closed" {
+		if pr.MergedAt == nil {
+			return time.Time{}, nil
+		}
+		return *pr.MergedAt, nil
+	}
+
+	owner, name, ok := splitRepo(repo)
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	var pull *github.PullRequest
+	_, err := ghra.call(ctx, func() (*github.Response, error) {
+		var (
+			resp *github.Response
+			err  error
+		)
+		pull, resp, err = ghra.client.PullRequests.Get(ctx, owner, name, issue.GetNumber())
+		return resp, err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return pull.GetMergedAt(), nil
+}
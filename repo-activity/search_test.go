@@ -0,0 +1,225 @@
+package ghra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// searchServer is a fake Search API returning issues numbered from 1, split
+// into pages of the requested per_page and linked with a Link header. Every
+// page claims total results, so a total beyond what search returns can be
+// tested. It records the per_page and page of every request.
+type searchServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	perPage []string
+	pages   []string
+}
+
+func newSearchServer(t *testing.T, issues, total int) *searchServer {
+	s := &searchServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		s.mu.Lock()
+		s.perPage = append(s.perPage, q.Get("per_page"))
+		s.pages = append(s.pages, q.Get("page"))
+		s.mu.Unlock()
+
+		perPage, err := strconv.Atoi(q.Get("per_page"))
+		if err != nil || perPage <= 0 {
+			perPage = 30
+		}
+		page, err := strconv.Atoi(q.Get("page"))
+		if err != nil || page <= 0 {
+			page = 1
+		}
+
+		first := (page - 1) * perPage
+		last := first + perPage
+		if last > issues {
+			last = issues
+		}
+		if last < issues {
+			next := *r.URL
+			values := next.Query()
+			values.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = values.Encode()
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="next"`, s.URL, next.RequestURI()))
+		}
+
+		fmt.Fprintf(w, `{"total_count": %d, "items": [`, total)
+		for n := first + 1; n <= last; n++ {
+			if n > first+1 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"number": %d, "title": "Issue %d", "user": {"login": "octocat"}, "created_at": "2024-03-09T12:00:00Z", "repository_url": "https://api.github.com/repos/acme/core"}`, n, n)
+		}
+		fmt.Fprint(w, "]}")
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestSearchIssuesPaginates(t *testing.T) {
+	tests := []struct {
+		name        string
+		perPage     int
+		issues      int
+		total       int
+		wantPerPage string
+		wantPages   []string
+	}{
+		{
+			name:        "default page size",
+			issues:      150,
+			total:       150,
+			wantPerPage: "100",
+			wantPages:   []string{"", "2"},
+		},
+		{
+			name:        "configured page size",
+			perPage:     2,
+			issues:      5,
+			total:       5,
+			wantPerPage: "2",
+			wantPages:   []string{"", "2", "3"},
+		},
+		{
+			name:        "page size clamped to the maximum",
+			perPage:     200,
+			issues:      150,
+			total:       150,
+			wantPerPage: "100",
+			wantPages:   []string{"", "2"},
+		},
+		{
+			name:        "total beyond what search returns",
+			perPage:     2,
+			issues:      4,
+			total:       5000,
+			wantPerPage: "2",
+			wantPages:   []string{"", "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSearchServer(t, tt.issues, tt.total)
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				PerPage:                 tt.perPage,
+				SearchRequestsPerMinute: -1,
+				APIEndpoint:             srv.URL + "/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, total, err := s.searchIssues(context.Background(), "repo:acme/core")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if total != tt.total {
+				t.Errorf("total = %d, want %d", total, tt.total)
+			}
+			if len(items) != tt.issues {
+				t.Fatalf("got %d items, want %d", len(items), tt.issues)
+			}
+			for i, item := range items {
+				if item.Number != i+1 {
+					t.Errorf("item %d is #%d, want #%d", i, item.Number, i+1)
+				}
+			}
+
+			if len(srv.pages) != len(tt.wantPages) {
+				t.Fatalf("requested pages %q, want %q", srv.pages, tt.wantPages)
+			}
+			for i := range srv.pages {
+				if srv.pages[i] != tt.wantPages[i] {
+					t.Errorf("request %d asked for page %q, want %q", i+1, srv.pages[i], tt.wantPages[i])
+				}
+				if srv.perPage[i] != tt.wantPerPage {
+					t.Errorf("request %d per_page = %q, want %q", i+1, srv.perPage[i], tt.wantPerPage)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchIssuesDeletedUser(t *testing.T) {
+	deleted := strings.Replace(searchItem("acme/core", 1, false), `"user": {"login": "octocat", "html_url": "https://github.com/octocat"}`, `"user": null`, 1)
+	noProfile := strings.Replace(searchItem("acme/core", 2, false), `"user": {"login": "octocat", "html_url": "https://github.com/octocat"}`, `"user": {"login": "build-bot"}`, 1)
+	srv := githubServer(t, map[string][]string{"acme/core": {deleted, noProfile}}, nil)
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		DaysOld:                 7,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := s.ListIssues(context.Background(), "issue")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int]IssueAuthor{
+		1: {DisplayName: "ghost", ProfileURL: "https://github.com/ghost"},
+		2: {DisplayName: "build-bot"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for _, item := range items {
+		if item.Author != want[item.Number] {
+			t.Errorf("#%d author = %+v, want %+v", item.Number, item.Author, want[item.Number])
+		}
+	}
+}
+
+func TestFetchIssuesIterStopsWhenCancelled(t *testing.T) {
+	srv := newSearchServer(t, 6, 6)
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		PerPage:                 2,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var seen []int
+	err = s.FetchIssuesIter(ctx, "issue", func(item IssueInfo) error {
+		seen = append(seen, item.Number)
+		// Cancel once the first page has arrived.
+		if len(seen) == 2 {
+			cancel()
+		}
+		return nil
+	})
+
+	if ctx.Err() == nil || !errors.Is(err, ctx.Err()) {
+		t.Errorf("FetchIssuesIter() = %v, want %v", err, context.Canceled)
+	}
+	if len(seen) != 2 {
+		t.Errorf("saw items %v, want only the first page", seen)
+	}
+	if len(srv.pages) != 1 {
+		t.Errorf("made %d requests, want 1", len(srv.pages))
+	}
+}
@@ -0,0 +1,125 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// SecurityAlerts counts a repo's open Dependabot alerts by severity.
+type SecurityAlerts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+// Total is the number of open alerts of any severity.
+func (a SecurityAlerts) Total() int {
+	return a.Critical + a.High + a.Medium + a.Low
+}
+
+// dependabotAlert is the part of a Dependabot alert the summary needs, which
+// go-github doesn't have a type for.
+type dependabotAlert struct {
+	SecurityAdvisory struct {
+		Severity string `json:"severity"`
+	} `json:"security_advisory"`
+}
+
+// addSecurityAlerts fills in each repo's SecurityAlerts. Lookups run
+// Concurrency repos at a time. Repos whose alerts the token can't read, or
+// that have Dependabot alerts disabled, are logged and left without a
+// summary; other failures are recorded in the report's Errors.
+func (ghra *GitHubRepoActivityService) addSecurityAlerts(ctx context.Context, report *ActivityReport) error {
+	errs, err := ghra.eachRepo(ctx, report.Repos(), func(ctx context.Context, repo string) error {
+		owner, name, ok := splitRepo(repo)
+		if !ok {
+			return nil
+		}
+		r := report.RepoActivityReports[repo]
+
+		alerts, err := ghra.fetchSecurityAlerts(ctx, owner, name)
+		if code := statusCode(err); code == http.StatusForbidden || code == http.StatusNotFound {
+			ghra.logger().WithFields(log.Fields{
+				"repo":  repo,
+				"error": err,
+			}).Warn("skipping Dependabot alerts, which are disabled or need a token with the security_events scope")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("listing Dependabot alerts: %w", err)
+		}
+
+		r.SecurityAlerts = alerts
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.addErrors(errs)
+
+	return nil
+}
+
+// fetchSecurityAlerts counts a repo's open Dependabot alerts by severity.
+// The alerts are paged with cursors rather than page numbers, so each page
+// is found by following the Link header.
+func (ghra *GitHubRepoActivityService) fetchSecurityAlerts(ctx context.Context, owner, name string) (*SecurityAlerts, error) {
+	alerts := &SecurityAlerts{}
+	u := fmt.Sprintf("repos/%v/%v/dependabot/alerts?state=open&per_page=%d", owner, name, maxPerPage)
+	for u != "" {
+		req, err := ghra.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []dependabotAlert
+		resp, err := ghra.call(ctx, func() (*github.Response, error) {
+			page = nil
+			return ghra.client.Do(ctx, req, &page)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range page {
+			switch strings.ToLower(a.SecurityAdvisory.Severity) {
+			case "critical":
+				alerts.Critical++
+			case "high":
+				alerts.High++
+			case "medium", "moderate":
+				alerts.Medium++
+			case "low":
+				alerts.Low++
+			}
+		}
+
+		u = nextLink(resp.Header.Get("Link"))
+	}
+
+	return alerts, nil
+}
+
+// nextLink returns the URL of the rel="next" link in a Link header, or an
+// empty string if there isn't one.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,487 @@
+package ghra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+func TestBuildQueries(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	window := "created:>=2024-03-03T12:34:00Z"
+
+	tests := []struct {
+		name       string
+		options    GitHubRepoActivityOptions
+		wantIssues []string
+		wantPRs    []string
+	}{
+		{
+			name:       "single repo",
+			options:    GitHubRepoActivityOptions{Repos: []string{"acme/core"}},
+			wantIssues: []string{"is:issue repo:acme/core " + window},
+			wantPRs:    []string{"is:pr repo:acme/core " + window},
+		},
+		{
+			name:       "several repos and a state",
+			options:    GitHubRepoActivityOptions{Repos: []string{"acme/core", "acme/docs"}, State: StateOpen},
+			wantIssues: []string{"is:issue repo:acme/core repo:acme/docs " + window + " is:open"},
+			wantPRs:    []string{"is:pr repo:acme/core repo:acme/docs " + window + " is:open"},
+		},
+		{
+			name:       "org",
+			options:    GitHubRepoActivityOptions{Orgs: []string{"acme"}},
+			wantIssues: []string{"is:issue org:acme " + window},
+			wantPRs:    []string{"is:pr org:acme " + window},
+		},
+		{
+			name:    "a query per label",
+			options: GitHubRepoActivityOptions{Repos: []string{"acme/core"}, Labels: []string{"bug", "good first issue"}},
+			wantIssues: []string{
+				"is:issue repo:acme/core " + window + " label:bug",
+				"is:issue repo:acme/core " + window + ` label:"good first issue"`,
+			},
+			wantPRs: []string{
+				"is:pr repo:acme/core " + window + " label:bug",
+				"is:pr repo:acme/core " + window + ` label:"good first issue"`,
+			},
+		},
+		{
+			name: "filters",
+			options: GitHubRepoActivityOptions{
+				Repos:         []string{"acme/core"},
+				ExcludeLabels: []string{"wontfix"},
+				Authors:       []string{"octocat"},
+				Milestone:     MilestoneNone,
+			},
+			wantIssues: []string{"is:issue repo:acme/core " + window + " -label:wontfix author:octocat no:milestone"},
+			wantPRs:    []string{"is:pr repo:acme/core " + window + " -label:wontfix author:octocat no:milestone"},
+		},
+		{
+			name:       "involved user and extra qualifiers",
+			options:    GitHubRepoActivityOptions{Repos: []string{"acme/core"}, InvolvesUser: "octocat", ExtraQuery: "sort:updated  no:assignee"},
+			wantIssues: []string{"is:issue repo:acme/core " + window + " involves:octocat sort:updated no:assignee"},
+			wantPRs:    []string{"is:pr repo:acme/core " + window + " involves:octocat sort:updated no:assignee"},
+		},
+		{
+			name:       "base branch only for pull requests",
+			options:    GitHubRepoActivityOptions{Repos: []string{"acme/core"}, BaseBranch: "main"},
+			wantIssues: []string{"is:issue repo:acme/core " + window},
+			wantPRs:    []string{"is:pr repo:acme/core " + window + " base:main"},
+		},
+		{
+			name:       "bots",
+			options:    GitHubRepoActivityOptions{Repos: []string{"acme/core"}, ExcludeBots: true, Bots: []string{"ci-user"}},
+			wantIssues: []string{"is:issue repo:acme/core " + window + " -author:app/dependabot -author:app/renovate -author:app/github-actions -author:ci-user"},
+			wantPRs:    []string{"is:pr repo:acme/core " + window + " -author:app/dependabot -author:app/renovate -author:app/github-actions -author:ci-user"},
+		},
+		{
+			name:       "too many bots to search for",
+			options:    GitHubRepoActivityOptions{Repos: []string{"acme/core"}, ExcludeBots: true, Bots: manyBots(20)},
+			wantIssues: []string{"is:issue repo:acme/core " + window},
+			wantPRs:    []string{"is:pr repo:acme/core " + window},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.DaysOld = 7
+			options.Now = func() time.Time { return now }
+
+			s, err := NewGitHubRepoActivityService(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, c := range []struct {
+				issueType string
+				want      []string
+			}{
+				{"issue", tt.wantIssues},
+				{"pr", tt.wantPRs},
+			} {
+				got := s.BuildQueries(c.issueType)
+				if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+					t.Errorf("BuildQueries(%q) = %q, want %q", c.issueType, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, false), searchItem("acme/core", 3, true)},
+		"acme/docs": {searchItem("acme/docs", 4, true)},
+	}, nil)
+
+	// want maps each repo to the numbers of its issues and pull requests,
+	// in the order they're reported.
+	tests := []struct {
+		name    string
+		options GitHubRepoActivityOptions
+		want    map[string][2][]int
+	}{
+		{
+			name:    "one search",
+			options: GitHubRepoActivityOptions{},
+			want: map[string][2][]int{
+				"acme/core": {{2, 1}, {3}},
+				"acme/docs": {nil, {4}},
+				"acme/idle": {nil, nil},
+			},
+		},
+		{
+			name:    "separate searches",
+			options: GitHubRepoActivityOptions{SeparateSearches: true},
+			want: map[string][2][]int{
+				"acme/core": {{2, 1}, {3}},
+				"acme/docs": {nil, {4}},
+				"acme/idle": {nil, nil},
+			},
+		},
+		{
+			name:    "oldest first",
+			options: GitHubRepoActivityOptions{SortBy: SortByNumber, SortOrder: SortAsc},
+			want: map[string][2][]int{
+				"acme/core": {{1, 2}, {3}},
+				"acme/docs": {nil, {4}},
+				"acme/idle": {nil, nil},
+			},
+		},
+		{
+			name:    "batched repos",
+			options: GitHubRepoActivityOptions{BatchSize: 1},
+			want: map[string][2][]int{
+				"acme/core": {{2, 1}, {3}},
+				"acme/docs": {nil, {4}},
+				"acme/idle": {nil, nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.Repos = []string{"acme/core", "acme/docs", "acme/idle"}
+			options.DaysOld = 7
+			options.Now = func() time.Time { return now }
+			options.APIEndpoint = srv.URL + "/"
+			options.SearchRequestsPerMinute = -1
+
+			s, err := NewGitHubRepoActivityService(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := s.BuildReportContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(report.RepoActivityReports) != len(tt.want) {
+				t.Errorf("report has %d repos, want %d", len(report.RepoActivityReports), len(tt.want))
+			}
+			totalIssues, totalPRs := 0, 0
+			for repo, want := range tt.want {
+				r := report.RepoActivityReports[repo]
+				if r == nil {
+					t.Errorf("report is missing %s", repo)
+					continue
+				}
+				if got := numbers(r.Issues); fmt.Sprint(got) != fmt.Sprint(want[0]) {
+					t.Errorf("%s issues = %v, want %v", repo, got, want[0])
+				}
+				if got := numbers(r.PullRequests); fmt.Sprint(got) != fmt.Sprint(want[1]) {
+					t.Errorf("%s pull requests = %v, want %v", repo, got, want[1])
+				}
+				totalIssues += len(want[0])
+				totalPRs += len(want[1])
+			}
+			if report.TotalIssues != totalIssues || report.TotalPullRequests != totalPRs {
+				t.Errorf("totals = %d issues, %d pull requests, want %d, %d",
+					report.TotalIssues, report.TotalPullRequests, totalIssues, totalPRs)
+			}
+			if len(report.Errors) != 0 {
+				t.Errorf("errors = %v, want none", report.Errors)
+			}
+			if !report.GeneratedAt.Equal(now) {
+				t.Errorf("GeneratedAt = %v, want %v", report.GeneratedAt, now)
+			}
+		})
+	}
+}
+
+// countingTransport counts the requests made through it.
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.requests++
+	c.mu.Unlock()
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestInjectedClient(t *testing.T) {
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, true)},
+	}, nil)
+
+	tests := []struct {
+		name       string
+		newService func(options *GitHubRepoActivityOptions, httpClient *http.Client) (*GitHubRepoActivityService, error)
+	}{
+		{
+			name: "HTTP client",
+			newService: func(options *GitHubRepoActivityOptions, httpClient *http.Client) (*GitHubRepoActivityService, error) {
+				options.APIEndpoint = srv.URL + "/"
+				options.HTTPClient = httpClient
+				return NewGitHubRepoActivityService(options)
+			},
+		},
+		{
+			name: "GitHub client",
+			newService: func(options *GitHubRepoActivityOptions, httpClient *http.Client) (*GitHubRepoActivityService, error) {
+				client := github.NewClient(httpClient)
+				client.BaseURL, _ = url.Parse(srv.URL + "/")
+				return NewGitHubRepoActivityServiceWithClient(client, options), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &countingTransport{}
+			s, err := tt.newService(&GitHubRepoActivityOptions{
+				Repos:                   []string{"acme/core"},
+				DaysOld:                 7,
+				SearchRequestsPerMinute: -1,
+			}, &http.Client{Transport: transport})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := s.BuildReportContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.TotalIssues != 1 || report.TotalPullRequests != 1 {
+				t.Errorf("totals = %d issues, %d pull requests, want 1, 1", report.TotalIssues, report.TotalPullRequests)
+			}
+			if transport.requests != 1 {
+				t.Errorf("%d requests went through the injected client, want 1", transport.requests)
+			}
+		})
+	}
+}
+
+func TestBuildReportOneSearch(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, false), searchItem("acme/core", 3, true)},
+		"acme/docs": {searchItem("acme/docs", 4, true)},
+	}, nil)
+
+	// build returns the report made with separate or combined searches as
+	// JSON, and the number of requests it took.
+	build := func(separate bool) ([]byte, int) {
+		transport := &countingTransport{}
+		s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+			Repos:                   []string{"acme/core", "acme/docs"},
+			DaysOld:                 7,
+			Now:                     func() time.Time { return now },
+			SeparateSearches:        separate,
+			SearchRequestsPerMinute: -1,
+			APIEndpoint:             srv.URL + "/",
+			HTTPClient:              &http.Client{Transport: transport},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := s.BuildReportContext(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(report)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return b, transport.requests
+	}
+
+	separate, separateRequests := build(true)
+	combined, combinedRequests := build(false)
+	if !bytes.Equal(combined, separate) {
+		t.Errorf("one search reported:\n%s\nwant the same as separate searches:\n%s", combined, separate)
+	}
+	if combinedRequests*2 != separateRequests {
+		t.Errorf("one search made %d requests, want half of the %d separate searches made", combinedRequests, separateRequests)
+	}
+}
+
+// manyBots returns n bot logins.
+func manyBots(n int) []string {
+	bots := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		bots = append(bots, fmt.Sprintf("ci-user-%02d", i))
+	}
+
+	return bots
+}
+
+func TestBuildReportExcludesBots(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	byAuthor := func(item, login string) string {
+		return strings.ReplaceAll(item, `"login": "octocat"`, fmt.Sprintf(`"login": %q`, login))
+	}
+	items := map[string][]string{
+		"acme/core": {
+			searchItem("acme/core", 1, false),
+			byAuthor(searchItem("acme/core", 2, false), "dependabot[bot]"),
+			byAuthor(searchItem("acme/core", 3, false), "ci-user-07"),
+			byAuthor(searchItem("acme/core", 4, true), "CI-User-20"),
+			searchItem("acme/core", 5, true),
+		},
+	}
+
+	tests := []struct {
+		name string
+		bots []string
+	}{
+		{name: "in the query", bots: []string{"ci-user-07", "ci-user-20"}},
+		{name: "too many for the query", bots: manyBots(20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake server ignores -author:, so every bot's items come
+			// back and have to be dropped from the results.
+			srv := githubServer(t, items, nil)
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Repos:                   []string{"acme/core"},
+				DaysOld:                 7,
+				ExcludeBots:             true,
+				Bots:                    tt.bots,
+				Now:                     func() time.Time { return now },
+				APIEndpoint:             srv.URL + "/",
+				SearchRequestsPerMinute: -1,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, q := range append(s.BuildQueries("issue"), s.BuildQueries("pr")...) {
+				if len(q) > maxQueryLength {
+					t.Errorf("query is %d characters long, over the limit of %d: %q", len(q), maxQueryLength, q)
+				}
+			}
+
+			report, err := s.BuildReportContext(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := report.RepoActivityReports["acme/core"]
+			if got := numbers(r.Issues); fmt.Sprint(got) != "[1]" {
+				t.Errorf("issues = %v, want [1]", got)
+			}
+			if got := numbers(r.PullRequests); fmt.Sprint(got) != "[5]" {
+				t.Errorf("pull requests = %v, want [5]", got)
+			}
+		})
+	}
+}
+
+func TestBuildReportKeepsGoingWhenLookupsFail(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	// Looking up issue #1 and pull request #3 of acme/core fails; the rest
+	// succeed.
+	srv := githubServer(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false), searchItem("acme/core", 2, false), searchItem("acme/core", 3, true)},
+		"acme/docs": {searchItem("acme/docs", 4, true)},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/core/issues/2/comments":
+			fmt.Fprint(w, `[{"user": {"login": "hubot"}, "created_at": "2024-03-09T03:00:00Z"}]`)
+		case "/repos/acme/docs/pulls/4/reviews":
+			fmt.Fprint(w, `[{"user": {"login": "hubot"}, "state": "APPROVED", "submitted_at": "2024-03-09T05:00:00Z"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		}
+	}))
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core", "acme/docs"},
+		DaysOld:                 7,
+		IncludeFirstResponse:    true,
+		IncludeReviewStatus:     true,
+		Now:                     func() time.Time { return now },
+		APIEndpoint:             srv.URL + "/",
+		SearchRequestsPerMinute: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.BuildReportContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core := report.RepoActivityReports["acme/core"]
+	if core == nil || len(core.Issues) != 2 || len(core.PullRequests) != 1 {
+		t.Fatalf("acme/core = %+v, want both issues and the pull request", core)
+	}
+	for _, issue := range core.Issues {
+		if answered := issue.FirstResponseAt != nil; answered != (issue.Number == 2) {
+			t.Errorf("issue #%d first response = %v", issue.Number, issue.FirstResponseAt)
+		}
+	}
+	if err := report.Errors["acme/core"]; err == nil {
+		t.Error("acme/core has no error for its failed lookups")
+	}
+
+	docs := report.RepoActivityReports["acme/docs"]
+	if docs == nil || len(docs.PullRequests) != 1 || docs.PullRequests[0].ReviewStatus == "" {
+		t.Errorf("acme/docs = %+v, want its reviewed pull request", docs)
+	}
+	if err := report.Errors["acme/docs"]; err != nil {
+		t.Errorf("acme/docs error = %v, want none", err)
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{Token: "secret", DaysOld: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derived, err := s.WithOptions(&GitHubRepoActivityOptions{DaysOld: 30, State: StateOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if derived.httpClient != s.httpClient || derived.client != s.client || derived.searchLimiter != s.searchLimiter {
+		t.Error("the derived service doesn't share the clients and search limiter")
+	}
+	if derived.options.DaysOld != 30 || s.options.DaysOld != 7 {
+		t.Errorf("DaysOld = %d and %d, want 30 for the derived service and 7 for the original", derived.options.DaysOld, s.options.DaysOld)
+	}
+
+	if _, err := s.WithOptions(&GitHubRepoActivityOptions{State: "merged"}); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("WithOptions() with an unknown state = %v, want ErrInvalidOption", err)
+	}
+}
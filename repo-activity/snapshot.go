@@ -0,0 +1,332 @@
+package ghra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// snapshotVersion is the version of the snapshot format written by
+// WriteSnapshot.
+const snapshotVersion = 1
+
+// ReportOptions records the options a report was built with, leaving out
+// credentials and clients, so a snapshot can be rendered the same way later.
+type ReportOptions struct {
+	Repos           []string  `json:"repos,omitempty"`
+	Orgs            []string  `json:"orgs,omitempty"`
+	ExcludeRepos    []string  `json:"exclude_repos,omitempty"`
+	IncludeArchived bool      `json:"include_archived,omitempty"`
+	DaysOld         int       `json:"days_old"`
+	Since           time.Time `json:"since,omitempty"`
+	Until           time.Time `json:"until,omitempty"`
+	// Timezone is the name of the Timezone location, such as
+	// "Europe/Berlin".
+	Timezone     string `json:"timezone,omitempty"`
+	ActivityMode string `json:"activity_mode,omitempty"`
+	State        string `json:"state,omitempty"`
+	Milestone    string `json:"milestone,omitempty"`
+	ExtraQuery   string `json:"extra_query,omitempty"`
+
+	Labels            []string `json:"labels,omitempty"`
+	ExcludeLabels     []string `json:"exclude_labels,omitempty"`
+	Authors           []string `json:"authors,omitempty"`
+	ExcludeAuthors    []string `json:"exclude_authors,omitempty"`
+	InvolvesUser      string   `json:"involves_user,omitempty"`
+	ReviewRequested   string   `json:"review_requested,omitempty"`
+	ExcludeBots       bool     `json:"exclude_bots,omitempty"`
+	ExcludeOrgMembers string   `json:"exclude_org_members,omitempty"`
+	IgnoreItems       []string `json:"ignore_items,omitempty"`
+	BaseBranch        string   `json:"base_branch,omitempty"`
+
+	IncludeClosed           bool `json:"include_closed,omitempty"`
+	ExcludeNotPlanned       bool `json:"exclude_not_planned,omitempty"`
+	IncludeMerged           bool `json:"include_merged,omitempty"`
+	IncludeReleases         bool `json:"include_releases,omitempty"`
+	IncludeTags             bool `json:"include_tags,omitempty"`
+	IncludeRepoMeta         bool `json:"include_repo_meta,omitempty"`
+	IncludeSecurityAlerts   bool `json:"include_security_alerts,omitempty"`
+	IncludeCommits          bool `json:"include_commits,omitempty"`
+	IncludeCIStats          bool `json:"include_ci_stats,omitempty"`
+	IncludeDiscussions      bool `json:"include_discussions,omitempty"`
+	IncludeReviewerStats    bool `json:"include_reviewer_stats,omitempty"`
+	IncludeApprovedUnmerged bool `json:"include_approved_unmerged,omitempty"`
+	IncludeNeedsReview      bool `json:"include_needs_review,omitempty"`
+	AnonymizeAuthors        bool `json:"anonymize_authors,omitempty"`
+	CompareWithPrevious     bool `json:"compare_with_previous,omitempty"`
+	StaleDays               int  `json:"stale_days,omitempty"`
+	TriageUnlabeled         bool `json:"triage_unlabeled,omitempty"`
+	TriageUnassigned        bool `json:"triage_unassigned,omitempty"`
+
+	IncludePRDetails     bool          `json:"include_pr_details,omitempty"`
+	OnlyFailingChecks    bool          `json:"only_failing_checks,omitempty"`
+	IncludeReviewStatus  bool          `json:"include_review_status,omitempty"`
+	OnlyUnreviewed       bool          `json:"only_unreviewed,omitempty"`
+	IncludeFirstResponse bool          `json:"include_first_response,omitempty"`
+	OnlyUnanswered       bool          `json:"only_unanswered,omitempty"`
+	ResponseSLA          time.Duration `json:"response_sla,omitempty"`
+
+	SortBy          string `json:"sort_by,omitempty"`
+	SortOrder       string `json:"sort_order,omitempty"`
+	MaxItemsPerRepo int    `json:"max_items_per_repo,omitempty"`
+	MaxItems        int    `json:"max_items,omitempty"`
+	UseGraphQL      bool   `json:"use_graphql,omitempty"`
+
+	ProjectOwner       string `json:"project_owner,omitempty"`
+	ProjectNumber      int    `json:"project_number,omitempty"`
+	ProjectStatusField string `json:"project_status_field,omitempty"`
+}
+
+// reportOptions records the service's options for its reports.
+func (ghra *GitHubRepoActivityService) reportOptions() *ReportOptions {
+	o := ghra.options
+
+	var tz string
+	if o.Timezone != nil {
+		tz = o.Timezone.String()
+	}
+
+	return &ReportOptions{
+		Repos:           o.Repos,
+		Orgs:            o.Orgs,
+		ExcludeRepos:    o.ExcludeRepos,
+		IncludeArchived: o.IncludeArchived,
+		DaysOld:         o.DaysOld,
+		Since:           o.Since,
+		Until:           o.Until,
+		Timezone:        tz,
+		ActivityMode:    ghra.activityMode(),
+		State:           o.State,
+		Milestone:       o.Milestone,
+		ExtraQuery:      o.ExtraQuery,
+
+		Labels:            o.Labels,
+		ExcludeLabels:     o.ExcludeLabels,
+		Authors:           o.Authors,
+		ExcludeAuthors:    o.ExcludeAuthors,
+		InvolvesUser:      o.InvolvesUser,
+		ReviewRequested:   o.ReviewRequested,
+		ExcludeBots:       o.ExcludeBots,
+		ExcludeOrgMembers: o.ExcludeOrgMembers,
+		IgnoreItems:       o.IgnoreItems,
+		BaseBranch:        o.BaseBranch,
+
+		IncludeClosed:           o.IncludeClosed,
+		ExcludeNotPlanned:       o.ExcludeNotPlanned,
+		IncludeMerged:           o.IncludeMerged,
+		IncludeReleases:         o.IncludeReleases,
+		IncludeTags:             o.IncludeTags,
+		IncludeRepoMeta:         o.IncludeRepoMeta,
+		IncludeSecurityAlerts:   o.IncludeSecurityAlerts,
+		IncludeCommits:          o.IncludeCommits,
+		IncludeCIStats:          o.IncludeCIStats,
+		IncludeDiscussions:      o.IncludeDiscussions,
+		IncludeReviewerStats:    o.IncludeReviewerStats,
+		IncludeApprovedUnmerged: o.IncludeApprovedUnmerged,
+		IncludeNeedsReview:      o.IncludeNeedsReview,
+		AnonymizeAuthors:        o.AnonymizeAuthors,
+		CompareWithPrevious:     o.CompareWithPrevious,
+		StaleDays:               o.StaleDays,
+		TriageUnlabeled:         o.TriageUnlabeled,
+		TriageUnassigned:        o.TriageUnassigned,
+
+		IncludePRDetails:     o.IncludePRDetails,
+		OnlyFailingChecks:    o.OnlyFailingChecks,
+		IncludeReviewStatus:  o.IncludeReviewStatus,
+		OnlyUnreviewed:       o.OnlyUnreviewed,
+		IncludeFirstResponse: o.IncludeFirstResponse,
+		OnlyUnanswered:       o.OnlyUnanswered,
+		ResponseSLA:          o.ResponseSLA,
+
+		SortBy:          o.SortBy,
+		SortOrder:       o.SortOrder,
+		MaxItemsPerRepo: o.MaxItemsPerRepo,
+		MaxItems:        o.MaxItems,
+		UseGraphQL:      o.UseGraphQL,
+
+		ProjectOwner:       o.ProjectOwner,
+		ProjectNumber:      o.ProjectNumber,
+		ProjectStatusField: o.ProjectStatusField,
+	}
+}
+
+// GitHubRepoActivityOptions returns service options matching the recorded
+// ones. A Timezone that can't be loaded is left unset.
+func (o *ReportOptions) GitHubRepoActivityOptions() *GitHubRepoActivityOptions {
+	var tz *time.Location
+	if o.Timezone != "" {
+		tz, _ = time.LoadLocation(o.Timezone)
+	}
+
+	return &GitHubRepoActivityOptions{
+		Repos:           o.Repos,
+		Orgs:            o.Orgs,
+		ExcludeRepos:    o.ExcludeRepos,
+		IncludeArchived: o.IncludeArchived,
+		DaysOld:         o.DaysOld,
+		Since:           o.Since,
+		Until:           o.Until,
+		Timezone:        tz,
+		ActivityMode:    o.ActivityMode,
+		State:           o.State,
+		Milestone:       o.Milestone,
+		ExtraQuery:      o.ExtraQuery,
+
+		Labels:            o.Labels,
+		ExcludeLabels:     o.ExcludeLabels,
+		Authors:           o.Authors,
+		ExcludeAuthors:    o.ExcludeAuthors,
+		InvolvesUser:      o.InvolvesUser,
+		ReviewRequested:   o.ReviewRequested,
+		ExcludeBots:       o.ExcludeBots,
+		ExcludeOrgMembers: o.ExcludeOrgMembers,
+		IgnoreItems:       o.IgnoreItems,
+		BaseBranch:        o.BaseBranch,
+
+		IncludeClosed:           o.IncludeClosed,
+		ExcludeNotPlanned:       o.ExcludeNotPlanned,
+		IncludeMerged:           o.IncludeMerged,
+		IncludeReleases:         o.IncludeReleases,
+		IncludeTags:             o.IncludeTags,
+		IncludeRepoMeta:         o.IncludeRepoMeta,
+		IncludeSecurityAlerts:   o.IncludeSecurityAlerts,
+		IncludeCommits:          o.IncludeCommits,
+		IncludeCIStats:          o.IncludeCIStats,
+		IncludeDiscussions:      o.IncludeDiscussions,
+		IncludeReviewerStats:    o.IncludeReviewerStats,
+		IncludeApprovedUnmerged: o.IncludeApprovedUnmerged,
+		IncludeNeedsReview:      o.IncludeNeedsReview,
+		AnonymizeAuthors:        o.AnonymizeAuthors,
+		CompareWithPrevious:     o.CompareWithPrevious,
+		StaleDays:               o.StaleDays,
+		TriageUnlabeled:         o.TriageUnlabeled,
+		TriageUnassigned:        o.TriageUnassigned,
+
+		IncludePRDetails:     o.IncludePRDetails,
+		OnlyFailingChecks:    o.OnlyFailingChecks,
+		IncludeReviewStatus:  o.IncludeReviewStatus,
+		OnlyUnreviewed:       o.OnlyUnreviewed,
+		IncludeFirstResponse: o.IncludeFirstResponse,
+		OnlyUnanswered:       o.OnlyUnanswered,
+		ResponseSLA:          o.ResponseSLA,
+
+		SortBy:          o.SortBy,
+		SortOrder:       o.SortOrder,
+		MaxItemsPerRepo: o.MaxItemsPerRepo,
+		MaxItems:        o.MaxItems,
+		UseGraphQL:      o.UseGraphQL,
+
+		ProjectOwner:       o.ProjectOwner,
+		ProjectNumber:      o.ProjectNumber,
+		ProjectStatusField: o.ProjectStatusField,
+	}
+}
+
+// snapshot is the JSON form of a saved report. The report's Errors and the
+// order of its repos don't marshal with it, so they're stored alongside.
+type snapshot struct {
+	Version     int               `json:"version"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Options     *ReportOptions    `json:"options"`
+	Repos       []string          `json:"repos"`
+	Errors      map[string]string `json:"errors,omitempty"`
+	Report      *ActivityReport   `json:"report"`
+}
+
+// WriteSnapshot writes the report as JSON, along with the options it was
+// built with and when, so it can be read back with ReadSnapshot.
+func (report *ActivityReport) WriteSnapshot(w io.Writer) error {
+	s := snapshot{
+		Version:     snapshotVersion,
+		GeneratedAt: report.GeneratedAt,
+		Options:     report.Options,
+		Repos:       report.Repos(),
+		Report:      report,
+	}
+	if len(report.Errors) > 0 {
+		s.Errors = make(map[string]string, len(report.Errors))
+		for repo, err := range report.Errors {
+			s.Errors[repo] = err.Error()
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
+
+// SaveSnapshot writes the report to a new file in dir named after when it
+// was generated, such as report-20240102T150405Z.json, creating dir if
+// needed. Existing files are never overwritten: a report generated in the
+// same second as an earlier one is saved as report-20240102T150405Z-2.json,
+// and so on. It returns the path of the file.
+func (report *ActivityReport) SaveSnapshot(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := "report-" + report.GeneratedAt.UTC().Format("20060102T150405Z")
+	path := filepath.Join(dir, name+".json")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	for n := 2; errors.Is(err, fs.ErrExist); n++ {
+		path = filepath.Join(dir, name+"-"+strconv.Itoa(n)+".json")
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := report.WriteSnapshot(f); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	return path, f.Close()
+}
+
+// ReadSnapshot reads a report written by WriteSnapshot. The report's Errors
+// are restored with their messages only, so they no longer match the
+// package's typed errors.
+func ReadSnapshot(r io.Reader) (*ActivityReport, error) {
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion || s.Report == nil {
+		return nil, fmt.Errorf("reading snapshot: unsupported version %d", s.Version)
+	}
+
+	report := s.Report
+	report.GeneratedAt = s.GeneratedAt
+	report.Options = s.Options
+	if report.Options == nil {
+		report.Options = &ReportOptions{}
+	}
+	if report.RepoActivityReports == nil {
+		report.RepoActivityReports = make(map[string]*RepoActivityReport)
+	}
+	report.order = s.Repos
+	report.Errors = make(map[string]error, len(s.Errors))
+	for repo, msg := range s.Errors {
+		report.Errors[repo] = errors.New(msg)
+	}
+
+	return report, nil
+}
+
+// LoadSnapshot reads the report saved in the file at path.
+func LoadSnapshot(path string) (*ActivityReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadSnapshot(f)
+}
@@ -0,0 +1,67 @@
+package ghra
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveSnapshotRoundTrip(t *testing.T) {
+	generated := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	dir := filepath.Join(t.TempDir(), "snapshots")
+
+	want := []string{
+		"report-20240102T150405Z.json",
+		"report-20240102T150405Z-2.json",
+		"report-20240102T150405Z-3.json",
+	}
+	for i, name := range want {
+		report := &ActivityReport{
+			RepoActivityReports: map[string]*RepoActivityReport{
+				"acme/core": {Issues: []IssueInfo{{Number: i + 1, Title: "Crash", Repo: "acme/core"}}},
+				"acme/docs": {},
+			},
+			Errors:      map[string]error{"acme/gone": errors.New("not found")},
+			TotalIssues: i + 1,
+			GeneratedAt: generated,
+			Options:     &ReportOptions{Repos: []string{"acme/core", "acme/gone"}, DaysOld: 7},
+			order:       []string{"acme/docs", "acme/core"},
+		}
+
+		path, err := report.SaveSnapshot(dir)
+		if err != nil {
+			t.Fatalf("save %d: %v", i+1, err)
+		}
+		if got := filepath.Base(path); got != name {
+			t.Errorf("save %d wrote %s, want %s", i+1, got, name)
+		}
+	}
+
+	// Each snapshot keeps its own report rather than being overwritten.
+	for i, name := range want {
+		report, err := LoadSnapshot(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("load %s: %v", name, err)
+		}
+		if report.TotalIssues != i+1 {
+			t.Errorf("%s TotalIssues = %d, want %d", name, report.TotalIssues, i+1)
+		}
+		issues := report.RepoActivityReports["acme/core"].Issues
+		if len(issues) != 1 || issues[0].Number != i+1 {
+			t.Errorf("%s issues = %+v, want #%d", name, issues, i+1)
+		}
+		if !report.GeneratedAt.Equal(generated) {
+			t.Errorf("%s GeneratedAt = %v, want %v", name, report.GeneratedAt, generated)
+		}
+		if report.Options.DaysOld != 7 {
+			t.Errorf("%s DaysOld = %d, want 7", name, report.Options.DaysOld)
+		}
+		if repos := report.Repos(); len(repos) != 2 || repos[0] != "acme/docs" || repos[1] != "acme/core" {
+			t.Errorf("%s repos = %q, want the saved order", name, repos)
+		}
+		if err := report.Errors["acme/gone"]; err == nil || err.Error() != "not found" {
+			t.Errorf("%s error = %v, want not found", name, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ghra
+
+import "sort"
+
+// Orderings for GitHubRepoActivityOptions.SortBy.
+const (
+	// SortByCreated orders items by when they were opened. It is the
+	// default.
+	SortByCreated = "created"
+	// SortByUpdated orders items by when they were last updated.
+	SortByUpdated = "updated"
+	// SortByComments orders items by how much they were discussed.
+	SortByComments = "comments"
+	// SortByReactions orders items by their 👍 reactions.
+	SortByReactions = "reactions"
+	// SortByNumber orders items by their issue or pull request number.
+	SortByNumber = "number"
+)
+
+// Directions for GitHubRepoActivityOptions.SortOrder.
+const (
+	// SortDesc puts the newest or most active items first. It is the
+	// default.
+	SortDesc = "desc"
+	// SortAsc puts the oldest or least active items first.
+	SortAsc = "asc"
+)
+
+// validSort reports whether by and order are known orderings.
+func validSort(by, order string) bool {
+	switch by {
+	case "", SortByCreated, SortByUpdated, SortByComments, SortByReactions, SortByNumber:
+	default:
+		return false
+	}
+
+	return order == "" || order == SortDesc || order == SortAsc
+}
+
+// compareItems compares a and b by the field named by, returning a negative
+// number when a comes first in ascending order.
+func compareItems(a, b IssueInfo, by string) int {
+	switch by {
+	case SortByUpdated:
+		return compareInt64(a.UpdatedAt.Unix(), b.UpdatedAt.Unix())
+	case SortByComments:
+		return a.Comments - b.Comments
+	case SortByReactions:
+		return a.Reactions.PlusOne - b.Reactions.PlusOne
+	case SortByNumber:
+		return 0
+	}
+
+	return compareInt64(a.CreatedAt.Unix(), b.CreatedAt.Unix())
+}
+
+// compareInt64 compares a and b like compareItems.
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// sortItems orders items in place according to by and order, and by number
+// when they compare equal, so the order doesn't depend on what search
+// returned.
+func sortItems(items []IssueInfo, by, order string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		c := compareItems(items[i], items[j], by)
+		if c == 0 {
+			c = items[i].Number - items[j].Number
+		}
+		if order == SortAsc {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
+// Repos returns the names of the repos in the report in a stable order: the
+// configured repos in the order they were given, followed by any others, such
+// as those found through an org, in alphabetical order.
+func (report *ActivityReport) Repos() []string {
+	repos := make([]string, 0, len(report.RepoActivityReports))
+	listed := make(map[string]bool, len(report.order))
+	for _, repo := range report.order {
+		if _, ok := report.RepoActivityReports[repo]; ok && !listed[repo] {
+			repos = append(repos, repo)
+			listed[repo] = true
+		}
+	}
+
+	var others []string
+	for repo := range report.RepoActivityReports {
+		if !listed[repo] {
+			others = append(others, repo)
+		}
+	}
+	sort.Strings(others)
+
+	return append(repos, others...)
+}
+
+// sortBy orders each repo's issues and pull requests according to by and
+// order.
+func (report *ActivityReport) sortBy(by, order string) {
+	for _, r := range report.RepoActivityReports {
+		sortItems(r.Issues, by, order)
+		sortItems(r.PullRequests, by, order)
+		sortItems(r.ClosedIssues, by, order)
+		sortItems(r.MergedPullRequests, by, order)
+		sortItems(r.Stale, by, order)
+		sortItems(r.NeedsReview, by, order)
+	}
+}
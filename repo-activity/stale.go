@@ -0,0 +1,68 @@
+package ghra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// buildStaleQuery builds a query for the open issues and pull requests in
+// repoNames that haven't been updated since before.
+func (ghra *GitHubRepoActivityService) buildStaleQuery(repoNames []string, before time.Time) string {
+	repos := scopeQualifiers(repoNames)
+
+	query := fmt.Sprintf("is:open %s updated:<%s", strings.Join(repos, " "), before.UTC().Format(time.RFC3339))
+	if q := ghra.qualifiers(""); len(q) > 0 {
+		query += " " + strings.Join(q, " ")
+	}
+
+	return query
+}
+
+// addStale searches for the open issues and pull requests that haven't been
+// updated in StaleDays and adds them to each repo's Stale. Their Age is the
+// time since they were last updated.
+func (ghra *GitHubRepoActivityService) addStale(ctx context.Context, report *ActivityReport) error {
+	var search searchFunc = ghra.searchIssues
+	if ghra.options.UseGraphQL {
+		search = ghra.searchGraphQL
+	}
+
+	before := ghra.now().AddDate(0, 0, ghra.options.StaleDays*-1)
+	stale, err := ghra.fetchBatches(ctx, ghra.batches(""), func(ctx context.Context, repos []string) ([]IssueInfo, bool, error) {
+		var items []IssueInfo
+		truncated := false
+		seen := make(map[string]bool)
+		for _, q := range ghra.labelQualifiers() {
+			found, total, err := withQualifier(search, q)(ctx, ghra.buildStaleQuery(repos, before))
+			if err != nil {
+				return nil, false, err
+			}
+			truncated = truncated || total > len(found)
+			items = mergeItems(items, seen, found)
+		}
+		return items, truncated, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ghra.canonicalizeRepos(stale.items)
+	for _, i := range stale.items {
+		idle := ghra.age(i.UpdatedAt)
+		i.Age = ghra.formatDuration(idle)
+		i.AgeDuration = idle
+		i.AgeSeconds = int64(idle.Seconds())
+
+		if report.RepoActivityReports[i.Repo] == nil {
+			report.RepoActivityReports[i.Repo] = &RepoActivityReport{}
+		}
+		report.RepoActivityReports[i.Repo].Stale = append(report.RepoActivityReports[i.Repo].Stale, i)
+	}
+	report.TotalStale = len(stale.items)
+	report.markTruncated(stale.truncated)
+	report.addErrors(stale.errors)
+
+	return nil
+}
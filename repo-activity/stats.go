@@ -0,0 +1,87 @@
+package ghra
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats summarizes how quickly a repo's issues were closed and its pull
+// requests merged during the period.
+type Stats struct {
+	// TimeToClose covers the issues in ClosedIssues, when IncludeClosed is
+	// enabled, leaving out those closed as not planned or as duplicates.
+	TimeToClose TimeStats `json:"time_to_close"`
+	// TimeToMerge covers the pull requests in MergedPullRequests, when
+	// IncludeMerged is enabled.
+	TimeToMerge TimeStats `json:"time_to_merge"`
+}
+
+// TimeStats is the median and 90th percentile of how long items were open.
+// Both are zero when Count is.
+type TimeStats struct {
+	Count  int           `json:"count"`
+	Median time.Duration `json:"median"`
+	P90    time.Duration `json:"p90"`
+
+	// MedianAge and P90Age are Median and P90 formatted like Age.
+	MedianAge string `json:"median_age,omitempty"`
+	P90Age    string `json:"p90_age,omitempty"`
+}
+
+// resolved reports whether a closed issue was resolved, rather than closed
+// as not planned or as a duplicate. Issues without a state reason, which
+// older GitHub Enterprise releases don't return, count as resolved.
+func resolved(i IssueInfo) bool {
+	return i.StateReason != StateReasonNotPlanned && i.StateReason != "duplicate"
+}
+
+// timeStats summarizes the time each of items was open, taken from their
+// AgeDuration.
+func (ghra *GitHubRepoActivityService) timeStats(items []IssueInfo) TimeStats {
+	durations := make([]time.Duration, 0, len(items))
+	for _, i := range items {
+		durations = append(durations, i.AgeDuration)
+	}
+	if len(durations) == 0 {
+		return TimeStats{}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	s := TimeStats{
+		Count:  len(durations),
+		Median: percentile(durations, 50),
+		P90:    percentile(durations, 90),
+	}
+	s.MedianAge = ghra.formatDuration(s.Median)
+	s.P90Age = ghra.formatDuration(s.P90)
+
+	return s
+}
+
+// percentile returns the nearest-rank pth percentile of sorted, which must
+// not be empty.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// stats fills in each repo's Stats from its closed issues and merged pull
+// requests.
+func (ghra *GitHubRepoActivityService) stats(report *ActivityReport) {
+	for _, r := range report.RepoActivityReports {
+		var closed []IssueInfo
+		for _, i := range r.ClosedIssues {
+			if resolved(i) {
+				closed = append(closed, i)
+			}
+		}
+		r.Stats = Stats{
+			TimeToClose: ghra.timeStats(closed),
+			TimeToMerge: ghra.timeStats(r.MergedPullRequests),
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package ghra
+
+import "time"
+
+// DailyActivity counts the issues and pull requests opened on one day.
+type DailyActivity struct {
+	// Date is midnight at the start of the day, in Timezone if it is set
+	// and UTC otherwise.
+	Date         time.Time `json:"date"`
+	IssuesOpened int       `json:"issues_opened"`
+	PRsOpened    int       `json:"prs_opened"`
+}
+
+// day returns midnight at the start of t's day in the report's timezone.
+func (ghra *GitHubRepoActivityService) day(t time.Time) time.Time {
+	loc := time.UTC
+	if ghra.options.Timezone != nil {
+		loc = ghra.options.Timezone
+	}
+	t = t.In(loc)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// timeSeries fills in each repo's TimeSeries with a bucket for every day of
+// the window, counting the items opened in it.
+func (ghra *GitHubRepoActivityService) timeSeries(report *ActivityReport) {
+	since, until := ghra.window()
+	if until.IsZero() {
+		until = ghra.now()
+	}
+	first, last := ghra.day(since), ghra.day(until)
+
+	for _, r := range report.RepoActivityReports {
+		var series []DailyActivity
+		index := make(map[string]int)
+		for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+			index[d.Format("2006-01-02")] = len(series)
+			series = append(series, DailyActivity{Date: d})
+		}
+
+		for _, i := range r.Issues {
+			if n, ok := index[ghra.day(i.CreatedAt).Format("2006-01-02")]; ok && i.New {
+				series[n].IssuesOpened++
+			}
+		}
+		for _, pr := range r.PullRequests {
+			if n, ok := index[ghra.day(pr.CreatedAt).Format("2006-01-02")]; ok && pr.New {
+				series[n].PRsOpened++
+			}
+		}
+		r.TimeSeries = series
+	}
+}
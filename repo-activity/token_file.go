@@ -0,0 +1,61 @@
+package ghra
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fileTokenSource reads the API token from a file, re-reading it whenever
+// the file's modification time changes so a rotated secret is picked up
+// without a restart.
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+var _ oauth2.TokenSource = &fileTokenSource{}
+
+func newFileTokenSource(path string) (*fileTokenSource, error) {
+	source := &fileTokenSource{path: path}
+	if _, err := source.Token(); err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// Token returns the token currently stored in the file.
+func (s *fileTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading token file: %w", err)
+	}
+
+	if s.token == "" || !info.ModTime().Equal(s.modTime) {
+		b, err := os.ReadFile(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading token file: %w", err)
+		}
+
+		token := strings.TrimSpace(string(b))
+		if token == "" {
+			return nil, errors.New("token file is empty")
+		}
+		s.token = token
+		s.modTime = info.ModTime()
+	}
+
+	return &oauth2.Token{AccessToken: s.token}, nil
+}
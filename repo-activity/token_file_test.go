@@ -0,0 +1,80 @@
+package ghra
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenFileRotation(t *testing.T) {
+	h := githubHandler(t, map[string][]string{
+		"acme/core": {searchItem("acme/core", 1, false)},
+	}, nil)
+
+	var mu sync.Mutex
+	auth := make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth[r.Header.Get("Authorization")] = true
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Repos:                   []string{"acme/core"},
+		DaysOld:                 7,
+		TokenFile:               path,
+		SearchRequestsPerMinute: -1,
+		APIEndpoint:             srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range []string{"first", "second"} {
+		if token != "first" {
+			if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			// Make sure the rotation is seen even on file systems with
+			// coarse modification times.
+			later := time.Now().Add(time.Minute)
+			if err := os.Chtimes(path, later, later); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		mu.Lock()
+		auth = make(map[string]bool)
+		mu.Unlock()
+		if _, err := s.BuildReportContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		mu.Lock()
+		if want := "Bearer " + token; len(auth) != 1 || !auth[want] {
+			t.Errorf("requests were authorized with %v, want only %q", auth, want)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestTokenFileMissing(t *testing.T) {
+	_, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		TokenFile: filepath.Join(t.TempDir(), "missing"),
+	})
+	if err == nil {
+		t.Error("a missing token file was accepted, want an error")
+	}
+}
@@ -0,0 +1,149 @@
+package ghra
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenPoolTransport spreads requests across several API tokens in turn,
+// skipping tokens whose rate limit was exhausted by an earlier response until
+// that limit resets.
+//
+// go-github keeps a single rate limit per client and refuses to make
+// requests once it reaches zero, so while another token still has requests
+// left the transport hides an exhausted token's rate limit headers from the
+// client, and retries a request rejected by the limit with the next token.
+type tokenPoolTransport struct {
+	base http.RoundTripper
+
+	mu     sync.Mutex
+	tokens []*pooledToken
+	next   int
+}
+
+type pooledToken struct {
+	token string
+	// parkedUntil maps a rate limit resource, such as "core" or "search",
+	// to when the token may be used for it again.
+	parkedUntil map[string]time.Time
+}
+
+func newTokenPoolTransport(tokens []string, base http.RoundTripper) *tokenPoolTransport {
+	t := &tokenPoolTransport{base: base}
+	for _, token := range tokens {
+		t.tokens = append(t.tokens, &pooledToken{
+			token:       token,
+			parkedUntil: make(map[string]time.Time),
+		})
+	}
+
+	return t
+}
+
+func (t *tokenPoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := rateLimitResource(req)
+
+	for attempt := 0; ; attempt++ {
+		token := t.pick(resource, time.Now())
+
+		r := req.Clone(req.Context())
+		r.Header.Set("Authorization", "token "+token.token)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+
+		t.observe(token, resource, resp)
+		if resp.Header.Get("X-RateLimit-Remaining") != "0" || !t.available(resource, time.Now()) {
+			return resp, nil
+		}
+
+		rejected := resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests
+		replayable := req.Body == nil || req.GetBody != nil
+		if rejected && replayable && attempt < len(t.tokens) {
+			resp.Body.Close()
+			continue
+		}
+
+		resp.Header.Del("X-RateLimit-Remaining")
+		resp.Header.Del("X-RateLimit-Reset")
+		return resp, nil
+	}
+}
+
+// pick returns the next token in turn that isn't parked for resource. If
+// every token is parked, the one that resets soonest is returned.
+func (t *tokenPoolTransport) pick(resource string, now time.Time) *pooledToken {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var soonest *pooledToken
+	for i := 0; i < len(t.tokens); i++ {
+		token := t.tokens[(t.next+i)%len(t.tokens)]
+		until := token.parkedUntil[resource]
+		if !now.Before(until) {
+			t.next = (t.next + i + 1) % len(t.tokens)
+			return token
+		}
+		if soonest == nil || until.Before(soonest.parkedUntil[resource]) {
+			soonest = token
+		}
+	}
+
+	return soonest
+}
+
+// available reports whether any token isn't parked for resource.
+func (t *tokenPoolTransport) available(resource string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, token := range t.tokens {
+		if !now.Before(token.parkedUntil[resource]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// observe parks token for resource when resp shows its limit is used up.
+func (t *tokenPoolTransport) observe(token *pooledToken, resource string, resp *http.Response) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	token.parkedUntil[resource] = time.Unix(reset, 0)
+}
+
+// rateLimitResource returns the rate limit bucket a request counts against.
+// Search and GraphQL requests have limits separate from the rest of the API.
+func rateLimitResource(req *http.Request) string {
+	switch {
+	case strings.Contains(req.URL.Path, "/search/"):
+		return "search"
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	default:
+		return "core"
+	}
+}
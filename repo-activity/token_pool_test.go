@@ -0,0 +1,86 @@
+package ghra
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenPoolRotatesPastExhaustedToken(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name string
+		// exhausted is the status returned for token "a", whose limit is
+		// used up by its first request.
+		exhausted int
+		want      []string
+	}{
+		{
+			name:      "last request within the limit",
+			exhausted: http.StatusOK,
+			want:      []string{"token a", "token b", "token b"},
+		},
+		{
+			name:      "request rejected by the limit",
+			exhausted: http.StatusForbidden,
+			want:      []string{"token a", "token b", "token b", "token b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				auth []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				auth = append(auth, r.Header.Get("Authorization"))
+				mu.Unlock()
+
+				w.Header().Set("X-RateLimit-Limit", "30")
+				w.Header().Set("X-RateLimit-Reset", reset)
+				if r.Header.Get("Authorization") == "token a" {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					if tt.exhausted != http.StatusOK {
+						w.WriteHeader(tt.exhausted)
+						w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+						return
+					}
+				} else {
+					w.Header().Set("X-RateLimit-Remaining", "29")
+				}
+				w.Write([]byte(`{"total_count": 0, "items": []}`))
+			}))
+			defer srv.Close()
+
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Tokens:      []string{"a", "b"},
+				APIEndpoint: srv.URL + "/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 3; i++ {
+				if _, _, err := s.client.Search.Issues(context.Background(), "repo:acme/core", nil); err != nil {
+					t.Fatalf("search %d: %v", i+1, err)
+				}
+			}
+
+			if len(auth) != len(tt.want) {
+				t.Fatalf("Authorization headers = %q, want %q", auth, tt.want)
+			}
+			for i := range auth {
+				if auth[i] != tt.want[i] {
+					t.Errorf("request %d used %q, want %q", i+1, auth[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ghra
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Version is the version of the package, sent in the default User-Agent.
+// Release builds set it with -ldflags.
+var Version = "dev"
+
+// defaultUserAgent identifies the package to GitHub, as it asks integrators
+// to. Builds that set an empty Version are reported as "dev".
+func defaultUserAgent() string {
+	version := Version
+	if version == "" {
+		version = "dev"
+	}
+
+	return "github-repo-activity/" + version
+}
+
+// userAgentTransport sets the User-Agent of every request.
+type userAgentTransport struct {
+	userAgent string
+	base      http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	return t.base.RoundTrip(req)
+}
+
+// debugTransport logs every request with its response's status and rate
+// limit headers.
+type debugTransport struct {
+	log  log.FieldLogger
+	base http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	logger := t.log.WithFields(log.Fields{
+		"method":   req.Method,
+		"url":      req.URL.String(),
+		"duration": time.Since(start),
+	})
+	if err != nil {
+		logger.WithError(err).Debug("HTTP request failed")
+		return nil, err
+	}
+
+	logger.WithFields(log.Fields{
+		"status":              resp.StatusCode,
+		"ratelimit_limit":     resp.Header.Get("X-RateLimit-Limit"),
+		"ratelimit_remaining": resp.Header.Get("X-RateLimit-Remaining"),
+		"ratelimit_reset":     resp.Header.Get("X-RateLimit-Reset"),
+		"ratelimit_resource":  resp.Header.Get("X-RateLimit-Resource"),
+	}).Debug("HTTP request")
+
+	return resp, nil
+}
@@ -0,0 +1,111 @@
+package ghra
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      string
+	}{
+		{
+			name: "default",
+			want: "github-repo-activity/" + Version,
+		},
+		{
+			name:      "configured",
+			userAgent: "acme-dashboard/1.2",
+			want:      "acme-dashboard/1.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, r.Header.Get("User-Agent"))
+				w.Write([]byte(`{"total_count": 0, "items": []}`))
+			}))
+			defer srv.Close()
+
+			s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+				Token:       "secret",
+				UserAgent:   tt.userAgent,
+				APIEndpoint: srv.URL + "/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := s.client.Search.Issues(context.Background(), "repo:acme/core", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebugHTTP(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "29")
+		w.Write([]byte(`{"total_count": 0, "items": []}`))
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&out)
+	logger.SetLevel(log.DebugLevel)
+
+	s, err := NewGitHubRepoActivityService(&GitHubRepoActivityOptions{
+		Token:       "secret",
+		DebugHTTP:   true,
+		Log:         logger,
+		APIEndpoint: srv.URL + "/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const requests = 3
+	for i := 0; i < requests; i++ {
+		if _, _, err := s.client.Search.Issues(context.Background(), "repo:acme/core", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, a := range auth {
+		if a != "Bearer secret" {
+			t.Errorf("request %d Authorization = %q, want the token", i+1, a)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != requests {
+		t.Fatalf("logged %d lines, want one per request:\n%s", len(lines), out.String())
+	}
+	for _, line := range lines {
+		for _, want := range []string{"method=GET", "/search/issues", "status=200", "ratelimit_remaining=29"} {
+			if !strings.Contains(line, want) {
+				t.Errorf("log line %q is missing %q", line, want)
+			}
+		}
+		if strings.Contains(line, "secret") || strings.Contains(strings.ToLower(line), "authorization") {
+			t.Errorf("log line %q shows the Authorization header", line)
+		}
+	}
+}
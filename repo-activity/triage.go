@@ -0,0 +1,35 @@
+package ghra
+
+// TriageGap is an open issue from the report window that is missing a label
+// or an assignee.
+type TriageGap struct {
+	IssueInfo
+	Unlabeled  bool `json:"unlabeled"`
+	Unassigned bool `json:"unassigned"`
+}
+
+// triageGaps fills in each repo's TriageGaps from its issues, checking for
+// the gaps enabled by TriageUnlabeled and TriageUnassigned.
+func (ghra *GitHubRepoActivityService) triageGaps(report *ActivityReport) {
+	unlabeled, unassigned := ghra.options.TriageUnlabeled, ghra.options.TriageUnassigned
+	if !unlabeled && !unassigned {
+		return
+	}
+
+	for _, r := range report.RepoActivityReports {
+		for _, i := range r.Issues {
+			if i.Status != "open" {
+				continue
+			}
+
+			gap := TriageGap{
+				IssueInfo:  i,
+				Unlabeled:  unlabeled && len(i.Labels) == 0,
+				Unassigned: unassigned && len(i.Assignees) == 0,
+			}
+			if gap.Unlabeled || gap.Unassigned {
+				r.TriageGaps = append(r.TriageGaps, gap)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package ghra
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// repoNameRe matches an owner/name repo. The name may hold the glob
+// wildcards accepted in Repos.
+var repoNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*/[A-Za-z0-9._*?\[\]^-]+$`)
+
+// ValidateRepo checks that name is an owner/name repo or repo pattern, such
+// as "digitalocean/godo" or "digitalocean/terraform-*".
+func ValidateRepo(name string) error {
+	if !repoNameRe.MatchString(name) {
+		return fmt.Errorf("%w: repo %q must be in the form owner/name", ErrInvalidOption, name)
+	}
+
+	return nil
+}
+
+// validateRepos trims the whitespace around each of repos and validates it,
+// returning the trimmed repos.
+func validateRepos(repos []string) ([]string, error) {
+	trimmed := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		repo = strings.TrimSpace(repo)
+		if err := ValidateRepo(repo); err != nil {
+			return nil, err
+		}
+		trimmed = append(trimmed, repo)
+	}
+
+	return trimmed, nil
+}
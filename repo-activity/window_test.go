@@ -0,0 +1,78 @@
+package ghra
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowQueryWithPinnedClock(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 34, 56, 0, time.UTC)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options GitHubRepoActivityOptions
+		now     time.Time
+		want    string
+	}{
+		{
+			name:    "days old rounded down to the minute",
+			options: GitHubRepoActivityOptions{DaysOld: 7},
+			now:     now,
+			want:    "is:issue repo:acme/core created:>=2024-03-03T12:34:00Z",
+		},
+		{
+			name:    "same query later in the minute",
+			options: GitHubRepoActivityOptions{DaysOld: 7},
+			now:     now.Add(3 * time.Second),
+			want:    "is:issue repo:acme/core created:>=2024-03-03T12:34:00Z",
+		},
+		{
+			name:    "next minute",
+			options: GitHubRepoActivityOptions{DaysOld: 7},
+			now:     now.Add(time.Minute),
+			want:    "is:issue repo:acme/core created:>=2024-03-03T12:35:00Z",
+		},
+		{
+			name:    "midnight in the time zone",
+			options: GitHubRepoActivityOptions{DaysOld: 7, Timezone: newYork},
+			now:     now,
+			want:    "is:issue repo:acme/core created:>=2024-03-03T05:00:00Z",
+		},
+		{
+			name:    "updated items",
+			options: GitHubRepoActivityOptions{DaysOld: 1, ActivityMode: ActivityUpdated},
+			now:     now,
+			want:    "is:issue repo:acme/core updated:>=2024-03-09T12:34:00Z",
+		},
+		{
+			name: "explicit window kept to the second",
+			options: GitHubRepoActivityOptions{
+				Since: time.Date(2024, 3, 1, 10, 0, 30, 0, time.UTC),
+				Until: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			},
+			now:  now,
+			want: "is:issue repo:acme/core created:2024-03-01T10:00:30Z..2024-03-02T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			options.Repos = []string{"acme/core"}
+			clock := tt.now
+			options.Now = func() time.Time { return clock }
+
+			s, err := NewGitHubRepoActivityService(&options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.BuildQuery("issue"); got != tt.want {
+				t.Errorf("BuildQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
+)
+
+// Dimensions of the daily activity chart, in SVG user units.
+const (
+	chartHeight   = 60
+	chartBarWidth = 10
+	chartBarGap   = 2
+)
+
+// chart renders a repo's daily activity as an inline SVG bar chart, with the
+// pull requests opened each day stacked on the issues.
+func chart(series []ghra.DailyActivity) template.HTML {
+	if len(series) == 0 {
+		return ""
+	}
+
+	max := 1
+	for _, d := range series {
+		if total := d.IssuesOpened + d.PRsOpened; total > max {
+			max = total
+		}
+	}
+
+	var b strings.Builder
+	width := len(series) * (chartBarWidth + chartBarGap)
+	fmt.Fprintf(&b, `<svg class="activity-chart" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="Issues and PRs opened per day">`, width, chartHeight, width, chartHeight)
+	for n, d := range series {
+		x := n * (chartBarWidth + chartBarGap)
+		issues := d.IssuesOpened * chartHeight / max
+		prs := d.PRsOpened * chartHeight / max
+		fmt.Fprintf(&b, `<g><title>%s: %d issues, %d PRs</title>`, d.Date.Format("Jan 2"), d.IssuesOpened, d.PRsOpened)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#48c78e"/>`, x, chartHeight-issues, chartBarWidth, issues)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#3e8ed0"/>`, x, chartHeight-issues-prs, chartBarWidth, prs)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="1" fill="#dbdbdb"/></g>`, x, chartHeight-1, chartBarWidth)
+	}
+	b.WriteString(`</svg>`)
+
+	return template.HTML(b.String())
+}
+
+// charts renders the daily activity chart of every repo in the report.
+func charts(report *ghra.ActivityReport) map[string]template.HTML {
+	c := make(map[string]template.HTML, len(report.RepoActivityReports))
+	for repo, r := range report.RepoActivityReports {
+		c[repo] = chart(r.TimeSeries)
+	}
+
+	return c
+}
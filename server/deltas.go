@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+
+	ghra "github.com/andrewsomething/github-repo-activity/repo-activity"
+)
+
+// deltas holds the rendered change in issue and PR counts since the
+// previous period.
+type deltas struct {
+	Issues       template.HTML
+	PullRequests template.HTML
+}
+
+// newDeltas renders the change from previous, or nothing if the report
+// wasn't compared with the previous period.
+func newDeltas(change, previous *ghra.Totals) deltas {
+	if change == nil || previous == nil {
+		return deltas{}
+	}
+
+	return deltas{
+		Issues:       delta(change.Issues, previous.Issues),
+		PullRequests: delta(change.PullRequests, previous.PullRequests),
+	}
+}
+
+// delta renders a change as a ▲ or ▼ indicator.
+func delta(change, previous int) template.HTML {
+	switch {
+	case change > 0:
+		return template.HTML(fmt.Sprintf(`<span class="has-text-success" title="Up from %d last period">▲ %d</span>`, previous, change))
+	case change < 0:
+		return template.HTML(fmt.Sprintf(`<span class="has-text-danger" title="Down from %d last period">▼ %d</span>`, previous, -change))
+	}
+
+	return `<span class="has-text-grey" title="Same as last period">=</span>`
+}
+
+// repoDeltas renders the changes of every repo in the report.
+func repoDeltas(report *ghra.ActivityReport) map[string]deltas {
+	d := make(map[string]deltas, len(report.RepoActivityReports))
+	for repo, r := range report.RepoActivityReports {
+		d[repo] = newDeltas(r.Deltas, r.PreviousTotals)
+	}
+
+	return d
+}
@@ -2,9 +2,15 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
@@ -13,40 +19,246 @@ import (
 )
 
 const (
-	defaultDays = 14
-	defaultPort = "3000"
+	defaultDays     = 14
+	defaultPort     = "3000"
+	defaultCacheTTL = 5 * time.Minute
+	// responseCacheTTL is how long API responses are kept for conditional
+	// requests.
+	responseCacheTTL = time.Hour
 )
 
 // Server is the interface for the server.
 type Server interface {
 	Start() error
 	Shutdown(ctx context.Context) error
+	Preflight(ctx context.Context) error
 
 	Report(w http.ResponseWriter, r *http.Request)
+	Stats(w http.ResponseWriter, r *http.Request)
 }
 
 // Options hold options for the server.
 type Options struct {
 	Log         *log.Logger
 	Repos       []string
+	Orgs        []string
 	DaysOld     int
+	BatchSize   int
+	Concurrency int
 	APIEndpoint string
 	Token       string
 	Port        string
+
+	// ExcludeRepos and IncludeArchived are passed through to the report
+	// service to control how repo patterns in Repos are expanded.
+	ExcludeRepos    []string
+	IncludeArchived bool
+	// VerifyRepos makes Preflight look up every repo, logging a warning for
+	// each one that doesn't exist.
+	VerifyRepos bool
+
+	// APIUploadEndpoint is the GitHub Enterprise upload URL. It defaults to
+	// APIEndpoint.
+	APIUploadEndpoint string
+
+	// Tokens are used in turn alongside Token to spread out rate limits.
+	Tokens []string
+
+	// TokenFile is read for the API token instead of using Token, and is
+	// re-read when it changes.
+	TokenFile string
+
+	// AppID, InstallationID, and AppPrivateKeyPath authenticate as a GitHub
+	// App installation instead of with Token.
+	AppID             int64
+	InstallationID    int64
+	AppPrivateKeyPath string
+
+	// RetryOnRateLimit and MaxRateLimitWait are passed through to the
+	// report service.
+	RetryOnRateLimit bool
+	MaxRateLimitWait time.Duration
+	// RequestTimeout bounds each GitHub API request. It defaults to 30
+	// seconds; a negative value disables it.
+	RequestTimeout time.Duration
+	// ProxyURL sends every API request through the proxy in place of the
+	// one set by HTTP_PROXY and HTTPS_PROXY.
+	ProxyURL string
+
+	// CacheTTL is how long a built report is reused for identical requests.
+	// It defaults to five minutes; a negative value disables the cache.
+	CacheTTL time.Duration
+	// Cache holds the built reports and API responses. It defaults to a
+	// ghra.MemoryCache; a ghra.RedisCache shares them between replicas.
+	Cache ghra.Cache
+	// CacheResponses makes repeated API requests conditional on the ETag of
+	// the previous response, so unchanged results don't use up the rate
+	// limit.
+	CacheResponses bool
+
+	// UseGraphQL fetches reports through the GraphQL API.
+	UseGraphQL bool
+	// SeparateSearches searches for issues and pull requests with a query
+	// each instead of a combined one.
+	SeparateSearches bool
+
+	// IncludePRDetails looks up the size, requested reviewers, branches, and
+	// checks of every pull request.
+	IncludePRDetails bool
+	// IncludeReviewStatus looks up the review status of every pull request.
+	IncludeReviewStatus bool
+	// IncludeFirstResponse looks up when every issue was first responded
+	// to. Open issues without a response for longer than ResponseSLA are
+	// highlighted.
+	IncludeFirstResponse bool
+	ResponseSLA          time.Duration
+
+	// IncludeClosed adds a section of the issues closed during the period.
+	// ExcludeNotPlanned leaves those closed as not planned out of it.
+	IncludeClosed     bool
+	ExcludeNotPlanned bool
+	// IncludeMerged adds a section of the pull requests merged during the
+	// period.
+	IncludeMerged bool
+	// IncludeReleases adds a section of the releases published during the
+	// period, and IncludeTags the tags without a release along with them.
+	IncludeReleases bool
+	IncludeTags     bool
+	// IncludeRepoMeta shows each repo's description, stars, forks, and open
+	// issue count in its header.
+	IncludeRepoMeta bool
+	// IncludeSecurityAlerts shows each repo's open Dependabot alerts by
+	// severity in its header.
+	IncludeSecurityAlerts bool
+	// IncludeCommits shows how many commits were made to each repo's
+	// default branch during the period, walking at most MaxCommitPages
+	// pages of them.
+	IncludeCommits bool
+	MaxCommitPages int
+	// IncludeCIStats shows how many workflow runs on each repo's default
+	// branch failed during the period, walking at most MaxWorkflowRunPages
+	// pages of them.
+	IncludeCIStats      bool
+	MaxWorkflowRunPages int
+	// IncludeDiscussions adds a section of the discussions created during
+	// the period.
+	IncludeDiscussions bool
+	// IncludeReviewerStats lists the reviewers who submitted the most
+	// reviews during the period.
+	IncludeReviewerStats bool
+	// StaleDays, when set, adds a section of the open issues and pull
+	// requests not updated in that many days.
+	StaleDays int
+	// MaxItemsPerRepo and MaxItems cap the issues and pull requests shown
+	// per repo and across the report, noting how many were left out.
+	MaxItemsPerRepo int
+	MaxItems        int
+	// ProjectOwner and ProjectNumber name a Projects v2 board whose
+	// ProjectStatusField is shown as a column of the issues and pull
+	// requests.
+	ProjectOwner       string
+	ProjectNumber      int
+	ProjectStatusField string
+	// IncludeApprovedUnmerged adds a section of the open pull requests that
+	// have been approved, those waiting longest first.
+	IncludeApprovedUnmerged bool
+	// IncludeNeedsReview highlights the open pull requests from the period
+	// that haven't been reviewed yet.
+	IncludeNeedsReview bool
+	// Triage lists the open new issues missing a label or an assignee.
+	Triage bool
+
+	// ExcludeBots leaves out items opened by bots unless a request sets
+	// exclude_bots=0.
+	ExcludeBots bool
+
+	// IgnoreItems lists items, such as "owner/repo#123", left out of every
+	// report.
+	IgnoreItems []string
+
+	// AnonymizeAuthors replaces every username with a pseudonym salted with
+	// AnonymizeSalt, and leaves out profile links. Without a salt, a random
+	// one is generated when the server starts.
+	AnonymizeAuthors bool
+	AnonymizeSalt    string
+
+	// CompareWithPrevious shows how the counts changed since the previous
+	// period, doubling the searches made, unless a request sets compare=0.
+	CompareWithPrevious bool
+
+	// Timezone anchors reports to whole calendar days in that timezone.
+	Timezone *time.Location
+
+	// SearchRequestsPerMinute throttles search requests across all
+	// report builds. Zero uses the library default.
+	SearchRequestsPerMinute int
+
+	// GitLabEndpoint and GitLabToken are used for the repos in Repos
+	// prefixed with ghra.GitLabPrefix. The endpoint defaults to gitlab.com.
+	GitLabEndpoint string
+	GitLabToken    string
+	// GiteaEndpoint and GiteaToken are used for the repos in Repos prefixed
+	// with ghra.GiteaPrefix, on a Gitea or Forgejo instance.
+	GiteaEndpoint string
+	GiteaToken    string
 }
 
 type server struct {
-	options    *ghra.GitHubRepoActivityOptions
+	options *ghra.GitHubRepoActivityOptions
+	// service is built from options once, and each report is built with a
+	// copy of it carrying the request's options, sharing its HTTP client.
+	service    *ghra.GitHubRepoActivityService
+	gitlab     *ghra.GitLabRepoActivityOptions
+	gitea      *ghra.GiteaRepoActivityOptions
+	metrics    *ghra.CountingMetrics
 	logger     *log.Logger
 	httpServer *http.Server
 }
 
 type pageData struct {
 	Days              int
+	Period            string
+	Activity          string
+	Updated           bool
+	State             string
+	Milestone         string
+	ExtraQuery        string
+	Org               string
+	StateNote         string
+	Closed            bool
+	Merged            bool
+	Discussions       bool
+	StaleDays         int
+	ApprovedUnmerged  bool
+	Filters           template.URL
+	Labels            string
+	ExcludeLabels     string
+	Author            string
+	ExcludeAuthor     string
+	ExcludeBots       bool
+	ExcludeOrgMembers string
+	HiddenOrgMembers  int
+	Involves          string
+	ReviewRequested   string
+	ReviewQueue       []ghra.IssueInfo
+	SortBy            string
+	SortOrder         string
+	PRDetails         bool
+	Project           bool
 	Repos             []string
 	Report            map[string]*ghra.RepoActivityReport
 	TotalIssues       int
 	TotalPullRequests int
+	TotalClosedIssues int
+	TotalMerged       int
+	TotalDiscussions  int
+	Contributors      []ghra.AuthorStats
+	Reviewers         []ghra.ReviewerCount
+	Charts            map[string]template.HTML
+	Compare           bool
+	Deltas            deltas
+	RepoDeltas        map[string]deltas
+	Errors            map[string]error
 }
 
 // NewServer initializes a new server.
@@ -63,22 +275,152 @@ func NewServer(opts Options) (Server, error) {
 		opts.Log = log.New()
 	}
 
+	// A malformed repo would fail every report, so it's left out instead.
+	split := ghra.SplitRepos(opts.Repos)
+	repos := make([]string, 0, len(split.GitHub))
+	for _, repo := range split.GitHub {
+		if err := ghra.ValidateRepo(repo); err != nil {
+			opts.Log.WithError(err).Warn("skipping invalid repo")
+			continue
+		}
+		repos = append(repos, repo)
+	}
+	opts.Repos = repos
+
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = defaultCacheTTL
+	}
+
+	// Every report, from GitHub, GitLab, or Gitea, uses the same salt so
+	// that a user keeps their pseudonym across them.
+	if opts.AnonymizeAuthors && opts.AnonymizeSalt == "" {
+		salt, err := ghra.NewAnonymizeSalt()
+		if err != nil {
+			return nil, err
+		}
+		opts.AnonymizeSalt = salt
+	}
+
+	if opts.Cache == nil {
+		opts.Cache = ghra.NewMemoryCache(0)
+	}
+
+	var reportCache *ghra.ReportCache
+	if opts.CacheTTL > 0 {
+		reportCache = ghra.NewReportCache(opts.Cache, opts.CacheTTL)
+	}
+	var responseCache ghra.ResponseCache
+	if opts.CacheResponses {
+		responseCache = ghra.NewResponseCache(opts.Cache, responseCacheTTL)
+	}
+
+	metrics := ghra.NewCountingMetrics()
 	router := mux.NewRouter()
 	srv := &server{
 		options: &ghra.GitHubRepoActivityOptions{
-			Repos:       opts.Repos,
-			DaysOld:     opts.DaysOld,
-			APIEndpoint: opts.APIEndpoint,
-			Token:       opts.Token,
+			Repos:             opts.Repos,
+			Orgs:              opts.Orgs,
+			DaysOld:           opts.DaysOld,
+			BatchSize:         opts.BatchSize,
+			Concurrency:       opts.Concurrency,
+			APIEndpoint:       opts.APIEndpoint,
+			APIUploadEndpoint: opts.APIUploadEndpoint,
+			Tokens:            opts.Tokens,
+			TokenFile:         opts.TokenFile,
+			AppID:             opts.AppID,
+			InstallationID:    opts.InstallationID,
+			AppPrivateKeyPath: opts.AppPrivateKeyPath,
+			Token:             opts.Token,
+
+			RetryOnRateLimit: opts.RetryOnRateLimit,
+			MaxRateLimitWait: opts.MaxRateLimitWait,
+			RequestTimeout:   opts.RequestTimeout,
+			ProxyURL:         opts.ProxyURL,
+			ResponseCache:    responseCache,
+			ReportCache:      reportCache,
+			UseGraphQL:       opts.UseGraphQL,
+			SeparateSearches: opts.SeparateSearches,
+			AnonymizeAuthors: opts.AnonymizeAuthors,
+			AnonymizeSalt:    opts.AnonymizeSalt,
+			Log:              opts.Log,
+			Metrics:          metrics,
+			SearchLimiter:    ghra.NewSearchLimiter(opts.SearchRequestsPerMinute),
+
+			IncludePRDetails:    opts.IncludePRDetails,
+			IncludeReviewStatus: opts.IncludeReviewStatus,
+			Timezone:            opts.Timezone,
+			IncludeClosed:       opts.IncludeClosed,
+			ExcludeNotPlanned:   opts.ExcludeNotPlanned,
+			IncludeMerged:       opts.IncludeMerged,
+			IncludeReleases:     opts.IncludeReleases,
+			IncludeTags:         opts.IncludeTags,
+			IncludeRepoMeta:     opts.IncludeRepoMeta,
+			IncludeCommits:      opts.IncludeCommits,
+			MaxCommitPages:      opts.MaxCommitPages,
+			IncludeCIStats:      opts.IncludeCIStats,
+			IncludeDiscussions:  opts.IncludeDiscussions,
+			ExcludeBots:         opts.ExcludeBots,
+			ExcludeRepos:        opts.ExcludeRepos,
+			IgnoreItems:         opts.IgnoreItems,
+			IncludeArchived:     opts.IncludeArchived,
+			VerifyRepos:         opts.VerifyRepos,
+			StaleDays:           opts.StaleDays,
+			MaxItemsPerRepo:     opts.MaxItemsPerRepo,
+			MaxItems:            opts.MaxItems,
+			TriageUnlabeled:     opts.Triage,
+			TriageUnassigned:    opts.Triage,
+
+			IncludeSecurityAlerts: opts.IncludeSecurityAlerts,
+			MaxWorkflowRunPages:   opts.MaxWorkflowRunPages,
+
+			ProjectOwner:       opts.ProjectOwner,
+			ProjectNumber:      opts.ProjectNumber,
+			ProjectStatusField: opts.ProjectStatusField,
+
+			IncludeFirstResponse: opts.IncludeFirstResponse,
+			IncludeReviewerStats: opts.IncludeReviewerStats,
+			ResponseSLA:          opts.ResponseSLA,
+			CompareWithPrevious:  opts.CompareWithPrevious,
+
+			IncludeApprovedUnmerged: opts.IncludeApprovedUnmerged,
+			IncludeNeedsReview:      opts.IncludeNeedsReview,
 		},
-		logger: opts.Log,
+		metrics: metrics,
+		logger:  opts.Log,
 		httpServer: &http.Server{
 			Addr:    ":" + opts.Port,
 			Handler: router,
 		},
 	}
+	service, err := ghra.NewGitHubRepoActivityService(srv.options)
+	if err != nil {
+		return nil, err
+	}
+	srv.service = service
+	if len(split.GitLab) > 0 {
+		srv.gitlab = &ghra.GitLabRepoActivityOptions{
+			Projects:    split.GitLab,
+			APIEndpoint: opts.GitLabEndpoint,
+			Token:       opts.GitLabToken,
+		}
+		if _, err := ghra.NewGitLabRepoActivityService(srv.gitlab); err != nil {
+			return nil, err
+		}
+	}
+	if len(split.Gitea) > 0 {
+		srv.gitea = &ghra.GiteaRepoActivityOptions{
+			Repos:       split.Gitea,
+			APIEndpoint: opts.GiteaEndpoint,
+			Token:       opts.GiteaToken,
+		}
+		if _, err := ghra.NewGiteaRepoActivityService(srv.gitea); err != nil {
+			return nil, err
+		}
+	}
+
 	reportHandler := http.HandlerFunc(srv.Report)
 	router.HandleFunc("/", reportHandler)
+	router.HandleFunc("/stats", srv.Stats)
 
 	return srv, nil
 }
@@ -89,6 +431,21 @@ func (srv *server) Start() error {
 	return srv.httpServer.ListenAndServe()
 }
 
+// Preflight checks that GitHub accepts the configured credentials. Repos
+// that can't be found are logged as warnings rather than failing startup.
+func (srv *server) Preflight(ctx context.Context) error {
+	err := srv.service.Preflight(ctx)
+	var fetchErr *ghra.FetchError
+	if errors.As(err, &fetchErr) {
+		for repo, err := range fetchErr.Errors {
+			srv.logger.WithError(err).WithField("repo", repo).Warn("configured repo not found")
+		}
+		return nil
+	}
+
+	return err
+}
+
 // Shutdown gracefully shuts down the server.
 func (srv *server) Shutdown(ctx context.Context) error {
 	return srv.httpServer.Shutdown(ctx)
@@ -101,48 +458,367 @@ func (srv *server) Report(w http.ResponseWriter, r *http.Request) {
 		"path":   r.RequestURI,
 	}).Info("request received")
 
+	// Copy the options so concurrent requests can't race on per-request
+	// overrides such as the number of days.
+	options := *srv.options
+
 	query := r.URL.Query()
 	daysQuery := query.Get("days")
 	if daysQuery != "" {
 		days, err := strconv.Atoi(daysQuery)
 		if err == nil {
-			srv.options.DaysOld = days
+			options.DaysOld = days
+		}
+	}
+
+	loc := options.Timezone
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, param := range []struct {
+		name     string
+		value    *time.Time
+		endOfDay bool
+	}{
+		{"since", &options.Since, false},
+		{"until", &options.Until, true},
+	} {
+		t, err := parseDate(query.Get(param.name), loc, param.endOfDay)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid %s date, expected YYYY-MM-DD: %s", param.name, err), http.StatusBadRequest)
+			return
+		}
+		if !t.IsZero() {
+			*param.value = t
+		}
+	}
+
+	if v := query.Get("org"); v != "" {
+		options.Orgs = splitList(v)
+	}
+	if v := query.Get("q"); v != "" {
+		options.ExtraQuery = v
+	}
+	if v := query.Get("milestone"); v != "" {
+		options.Milestone = v
+	}
+	if v := query.Get("labels"); v != "" {
+		options.Labels = splitList(v)
+	}
+	if v := query.Get("exclude_labels"); v != "" {
+		options.ExcludeLabels = splitList(v)
+	}
+	if v := query.Get("author"); v != "" {
+		options.Authors = splitList(v)
+	}
+	if v := query.Get("exclude_author"); v != "" {
+		options.ExcludeAuthors = splitList(v)
+	}
+	if v := query.Get("exclude_org_members"); v != "" {
+		options.ExcludeOrgMembers = v
+	}
+	if v := query.Get("involves"); v != "" {
+		options.InvolvesUser = v
+	}
+	if v := query.Get("review_requested"); v != "" {
+		options.ReviewRequested = v
+	}
+	if v := query.Get("exclude_bots"); v != "" {
+		options.ExcludeBots = v != "0" && v != "false"
+	}
+	if v := query.Get("compare"); v != "" {
+		options.CompareWithPrevious = v != "0" && v != "false"
+	}
+
+	switch state := query.Get("state"); state {
+	case "", ghra.StateAll, ghra.StateOpen, ghra.StateClosed:
+		options.State = state
+	default:
+		http.Error(w, fmt.Sprintf("invalid state %q, must be %q, %q, or %q", state, ghra.StateAll, ghra.StateOpen, ghra.StateClosed), http.StatusBadRequest)
+		return
+	}
+
+	switch mode := query.Get("mode"); mode {
+	case ghra.ActivityCreated, ghra.ActivityUpdated:
+		options.ActivityMode = mode
+	}
+
+	// Unknown orderings are rejected by NewGitHubRepoActivityService.
+	options.SortBy = query.Get("sort")
+	options.SortOrder = query.Get("order")
+
+	tmpl := template.Must(template.New("page").Parse(page))
+
+	var builds []func(context.Context) (*ghra.ActivityReport, error)
+	if len(options.Repos) > 0 || len(options.Orgs) > 0 {
+		service, err := srv.service.WithOptions(&options)
+		if errors.Is(err, ghra.ErrInvalidWindow) || errors.Is(err, ghra.ErrInvalidOption) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
+
+		build := service.BuildReportContext
+		if query.Get("refresh") != "" {
+			build = service.RefreshReportContext
+		}
+		builds = append(builds, build)
+	}
+	if srv.gitlab != nil {
+		gitlabOptions := options.GitLabOptions(srv.gitlab.Projects)
+		gitlabOptions.APIEndpoint = srv.gitlab.APIEndpoint
+		gitlabOptions.Token = srv.gitlab.Token
+
+		service, err := ghra.NewGitLabRepoActivityService(gitlabOptions)
+		if errors.Is(err, ghra.ErrInvalidOption) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		builds = append(builds, service.BuildReportContext)
 	}
+	if srv.gitea != nil {
+		giteaOptions := options.GiteaOptions(srv.gitea.Repos)
+		giteaOptions.APIEndpoint = srv.gitea.APIEndpoint
+		giteaOptions.Token = srv.gitea.Token
 
-	funcMap := template.FuncMap{
-		"deref": deref,
+		service, err := ghra.NewGiteaRepoActivityService(giteaOptions)
+		if errors.Is(err, ghra.ErrInvalidOption) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		builds = append(builds, service.BuildReportContext)
 	}
-	tmpl := template.Must(template.New("page").Funcs(funcMap).Parse(page))
 
-	service := ghra.NewGitHubRepoActivityService(srv.options)
-	report, err := service.BuildReport()
+	report, err := buildReports(r.Context(), builds)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		srv.logger.WithError(err).Error("failed to build report")
+
+		var rateErr *ghra.ErrRateLimited
+		switch {
+		case errors.As(err, &rateErr):
+			// The reset can already have passed by the time the report
+			// fails, so always ask clients to wait at least a second.
+			retryAfter := int(time.Until(rateErr.ResetAt).Seconds()) + 1
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "GitHub API rate limit exceeded, try again later", http.StatusTooManyRequests)
+		case errors.Is(err, ghra.ErrUnauthorized):
+			http.Error(w, "GitHub rejected the configured API token", http.StatusBadGateway)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if report.RateLimit != nil {
+		srv.logger.WithFields(log.Fields{
+			"limit":     report.RateLimit.Limit,
+			"remaining": report.RateLimit.Remaining,
+			"reset":     report.RateLimit.Reset,
+		}).Info("report built")
 	}
 
 	data := pageData{
-		Days:              srv.options.DaysOld,
-		Repos:             srv.options.Repos,
+		Days:              options.DaysOld,
+		Period:            period(&options),
+		Activity:          "opened",
+		Updated:           options.ActivityMode == ghra.ActivityUpdated,
+		State:             options.State,
+		Milestone:         options.Milestone,
+		ExtraQuery:        options.ExtraQuery,
+		Org:               query.Get("org"),
+		StateNote:         stateNote(options.State),
+		Closed:            options.IncludeClosed,
+		Merged:            options.IncludeMerged,
+		Discussions:       options.IncludeDiscussions,
+		StaleDays:         options.StaleDays,
+		ApprovedUnmerged:  options.IncludeApprovedUnmerged,
+		Filters:           filters(query, options.DaysOld),
+		Labels:            query.Get("labels"),
+		ExcludeLabels:     query.Get("exclude_labels"),
+		Author:            query.Get("author"),
+		ExcludeAuthor:     query.Get("exclude_author"),
+		ExcludeBots:       options.ExcludeBots,
+		ExcludeOrgMembers: options.ExcludeOrgMembers,
+		HiddenOrgMembers:  report.HiddenOrgMembers,
+		Involves:          options.InvolvesUser,
+		ReviewRequested:   options.ReviewRequested,
+		ReviewQueue:       report.ReviewQueue,
+		SortBy:            options.SortBy,
+		SortOrder:         options.SortOrder,
+		PRDetails:         options.IncludePRDetails,
+		Project:           options.ProjectOwner != "" && options.ProjectNumber != 0,
+		Repos:             report.Repos(),
 		Report:            report.RepoActivityReports,
 		TotalIssues:       report.TotalIssues,
 		TotalPullRequests: report.TotalPullRequests,
+		TotalClosedIssues: report.TotalClosedIssues,
+		TotalMerged:       report.TotalMerged,
+		TotalDiscussions:  report.TotalDiscussions,
+		Contributors:      topContributors(report.AuthorStats),
+		Reviewers:         topReviewers(report.ReviewerStats),
+		Charts:            charts(report),
+		Compare:           options.CompareWithPrevious,
+		Deltas:            newDeltas(report.Deltas, report.PreviousTotals),
+		RepoDeltas:        repoDeltas(report),
+		Errors:            report.Errors,
+	}
+	if data.Updated {
+		data.Activity = "updated"
 	}
 
 	w.Header().Set("Cache-Control", "public, maxage=600")
 	tmpl.Execute(w, data)
 }
 
-func deref(s *string) string {
-	if s != nil {
-		return *s
+// buildReports runs each of builds in turn, merging their reports when there
+// is more than one.
+func buildReports(ctx context.Context, builds []func(context.Context) (*ghra.ActivityReport, error)) (*ghra.ActivityReport, error) {
+	reports := make([]*ghra.ActivityReport, 0, len(builds))
+	for _, build := range builds {
+		report, err := build(ctx)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	if len(reports) == 1 {
+		return reports[0], nil
+	}
+	return ghra.MergeReports(reports...), nil
+}
+
+// maxContributors is how many authors the contributor summary lists.
+const maxContributors = 10
+
+// topContributors returns the most active of stats, which are ordered most
+// active first.
+func topContributors(stats []ghra.AuthorStats) []ghra.AuthorStats {
+	if len(stats) > maxContributors {
+		return stats[:maxContributors]
+	}
+
+	return stats
+}
+
+// topReviewers returns the reviewers with the most reviews, most first.
+func topReviewers(stats map[string]int) []ghra.ReviewerCount {
+	top := ghra.TopReviewers(stats)
+	if len(top) > maxContributors {
+		return top[:maxContributors]
+	}
+
+	return top
+}
+
+// filters encodes the query parameters to keep when re-sorting the report,
+// everything except the sort order and refresh.
+func filters(query url.Values, days int) template.URL {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Del("sort")
+	q.Del("order")
+	q.Del("refresh")
+	q.Set("days", strconv.Itoa(days))
+
+	return template.URL(q.Encode())
+}
+
+// splitList splits a comma separated query parameter, dropping empty
+// entries.
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// parseDate parses a YYYY-MM-DD date in loc. With endOfDay it returns the
+// last second of the day, so the whole day is included in the window. An
+// empty value gives the zero time.
+func parseDate(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	return t, nil
+}
+
+// stateNote describes the State filter for headings.
+func stateNote(state string) string {
+	switch state {
+	case ghra.StateOpen:
+		return " and still open"
+	case ghra.StateClosed:
+		return " and since closed"
 	}
 
 	return ""
 }
 
+// period describes the report window for headings.
+func period(options *ghra.GitHubRepoActivityOptions) string {
+	switch {
+	case !options.Since.IsZero() && !options.Until.IsZero():
+		return fmt.Sprintf("between %s and %s", options.Since.Format("2006-01-02"), options.Until.Format("2006-01-02"))
+	case !options.Since.IsZero():
+		return fmt.Sprintf("since %s", options.Since.Format("2006-01-02"))
+	case !options.Until.IsZero():
+		return fmt.Sprintf("in the %d days up to %s", options.DaysOld, options.Until.Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("in the past %d days", options.DaysOld)
+}
+
+// Stats reports the GitHub API usage collected since the server started.
+func (srv *server) Stats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(srv.metrics.Snapshot())
+}
+
 const page = `{{ $days := .Days }}
+{{ $period := .Period }}
+{{ $verb := .Activity }}
+{{ $updated := .Updated }}
+{{ $stateNote := .StateNote }}
+{{ $closed := .Closed }}
+{{ $charts := .Charts }}
+{{ $repoDeltas := .RepoDeltas }}
+{{ $merged := .Merged }}
+{{ $discussions := .Discussions }}
+{{ $staleDays := .StaleDays }}
+{{ $approvedUnmerged := .ApprovedUnmerged }}
+{{ $filters := .Filters }}
+{{ $prDetails := .PRDetails }}
+{{ $project := .Project }}
+{{ $involves := .Involves }}
 {{ $report := .Report }}
+{{ $errors := .Errors }}
 <head>
   <meta charset="utf-8">
   <meta name="viewport" content="width=device-width, initial-scale=1">
@@ -183,6 +859,32 @@ const page = `{{ $days := .Days }}
     .content {
       display: inline-block;
     }
+
+    .tag.is-merged {
+      background-color: #8250df;
+      color: #fff;
+    }
+
+    .tag.is-not-planned {
+      background-color: #6e7781;
+      color: #fff;
+    }
+
+    .tag.is-size-S { background-color: #dafbe1; }
+    .tag.is-size-M { background-color: #fff8c5; }
+    .tag.is-size-L { background-color: #ffe2cc; }
+    .tag.is-size-XL { background-color: #ffd8d3; }
+
+    .tag.is-review-APPROVED { background-color: #48c78e; color: #fff; }
+    .tag.is-review-CHANGES_REQUESTED { background-color: #f14668; color: #fff; }
+    .tag.is-review-PENDING { background-color: #ffe08a; }
+
+    tr.is-unrouted {
+      background-color: #fffaeb;
+    }
+    tr.is-overdue {
+      background-color: #feecf0;
+    }
   </style>
 </head>
 
@@ -192,12 +894,31 @@ const page = `{{ $days := .Days }}
       <div class="columns is-vcentered">
         <div class="column is-8">
           <h1 class="title">GitHub Activity Report</h1>
-          <h3 class="subtitle"> {{ .TotalIssues }} total issues and {{ .TotalPullRequests }} total pull requests in the past {{ $days }} days.</h2>
+          {{ with .Involves }}<p class="subtitle">Activity involving <strong>{{ . }}</strong></p>{{ end }}
+          {{ with .Milestone }}<p class="subtitle">Milestone: <strong>{{ if eq . "none" }}no milestone{{ else if eq . "*" }}any milestone{{ else }}{{ . }}{{ end }}</strong></p>{{ end }}
+          <h3 class="subtitle"> {{ .TotalIssues }} total issues {{ .Deltas.Issues }} and {{ .TotalPullRequests }} total pull requests {{ .Deltas.PullRequests }}{{ if $closed }}, {{ .TotalClosedIssues }} issues closed{{ end }}{{ if $merged }}, {{ .TotalMerged }} PRs merged{{ end }}{{ if $discussions }}, {{ .TotalDiscussions }} new discussions{{ end }} {{ $period }}.</h2>
+          {{ with .ExcludeOrgMembers }}<p class="help">{{ $.HiddenOrgMembers }} items from {{ . }} members hidden</p>{{ end }}
         </div>
         <div class="column">
 
           <div class="control is-pulled-right">
             <form id="days-select" action="/" method='GET' onchange="daysSubmit()">
+              {{ with .SortBy }}<input type="hidden" name="sort" value="{{ . }}">{{ end }}
+              {{ with .SortOrder }}<input type="hidden" name="order" value="{{ . }}">{{ end }}
+              {{ if .Updated }}<input type="hidden" name="mode" value="updated">{{ end }}
+              {{ with .Org }}<input type="hidden" name="org" value="{{ . }}">{{ end }}
+              {{ with .ExtraQuery }}<input type="hidden" name="q" value="{{ . }}">{{ end }}
+              {{ with .Milestone }}<input type="hidden" name="milestone" value="{{ . }}">{{ end }}
+              {{ with .State }}<input type="hidden" name="state" value="{{ . }}">{{ end }}
+              {{ with .Labels }}<input type="hidden" name="labels" value="{{ . }}">{{ end }}
+              {{ with .ExcludeLabels }}<input type="hidden" name="exclude_labels" value="{{ . }}">{{ end }}
+              {{ with .Author }}<input type="hidden" name="author" value="{{ . }}">{{ end }}
+              {{ with .ExcludeAuthor }}<input type="hidden" name="exclude_author" value="{{ . }}">{{ end }}
+              {{ with .ExcludeOrgMembers }}<input type="hidden" name="exclude_org_members" value="{{ . }}">{{ end }}
+              {{ with .Involves }}<input type="hidden" name="involves" value="{{ . }}">{{ end }}
+              {{ with .ReviewRequested }}<input type="hidden" name="review_requested" value="{{ . }}">{{ end }}
+              <input type="hidden" name="exclude_bots" value="{{ if .ExcludeBots }}1{{ else }}0{{ end }}">
+              <input type="hidden" name="compare" value="{{ if .Compare }}1{{ else }}0{{ end }}">
               <div class="select">
                 <select name="days">
                   <option value="{{ $days }}">{{ $days }} Days</option>
@@ -223,7 +944,7 @@ const page = `{{ $days := .Days }}
         <div class="box">
           <ul class="menu-list">
             {{ range $repo := .Repos }}
-            <li ><a class="repo-selector" href="#{{ $repo }}">{{ $repo }}</a></li>
+            <li ><a class="repo-selector" href="#{{ $repo }}">{{ $repo }}{{ with index $.Report $repo }} <span class="has-text-grey" title="Issues / PRs">({{ .Counts.NewIssues }} / {{ .Counts.NewPRs }})</span>{{ end }}</a></li>
             {{ end }}
           </ul>
         </div>
@@ -231,112 +952,571 @@ const page = `{{ $days := .Days }}
     </div>
 
     <div class="column">
+      {{ with .ReviewRequested }}
+      <section class="section">
+        <div class="box" id="review-queue">
+          <h1 class="title">PRs awaiting review from {{ . }}</h1>
+          {{ with $.ReviewQueue }}
+          <table class="table is-hoverable">
+            <thead>
+              <tr>
+                <th>Repo</th>
+                <th>#</th>
+                <th>Waiting</th>
+                <th>Author</th>
+                <th>Title</th>
+              </tr>
+            </thead>
+            <tbody>
+              {{ range . }}
+              <tr>
+                <td>{{ .Repo }}</td>
+                <td><a href={{ .URL }}>{{ .Number }}</a></td>
+                <td title="Opened {{ .CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ .Age }}</td>
+                <td><a href={{ .Author.ProfileURL }}>{{ .Author.DisplayName }}</a></td>
+                <td><a href={{ .URL }}>{{ .Title }}</a>{{ if .Draft }} <span class="tag">draft</span>{{ end }}</td>
+              </tr>
+              {{ end }}
+            </tbody>
+          </table>
+          {{ else }}
+          <p>No open PRs are waiting for a review from {{ . }}.</p>
+          {{ end }}
+        </div>
+      </section>
+      {{ end }}
+      {{ with .Contributors }}
+      <section class="section">
+        <div class="box" id="contributors">
+          <h1 class="title">Top contributors {{ $period }}</h1>
+          <table class="table is-hoverable">
+            <thead>
+              <tr>
+                <th>Author</th>
+                <th>Issues</th>
+                <th>PRs</th>
+              </tr>
+            </thead>
+            <tbody>
+              {{ range . }}
+              <tr>
+                <td><a href={{ .Author.ProfileURL }}>{{ .Author.DisplayName }}</a></td>
+                <td>{{ .Issues }}</td>
+                <td>{{ .PullRequests }}</td>
+              </tr>
+              {{ end }}
+            </tbody>
+          </table>
+        </div>
+      </section>
+      {{ end }}
+      {{ with .Reviewers }}
+      <section class="section">
+        <div class="box" id="reviewers">
+          <h1 class="title">Top reviewers {{ $period }}</h1>
+          <table class="table is-hoverable">
+            <thead>
+              <tr>
+                <th>Reviewer</th>
+                <th>Reviews</th>
+              </tr>
+            </thead>
+            <tbody>
+              {{ range . }}
+              <tr>
+                <td>{{ .Login }}</td>
+                <td>{{ .Reviews }}</td>
+              </tr>
+              {{ end }}
+            </tbody>
+          </table>
+        </div>
+      </section>
+      {{ end }}
       {{ range $repo := .Repos }}
       <section class="section">
         <div class="box" id={{ $repo }}>
-          <h1 class="title"> Repo: <a href="https://github.com/{{ $repo }}">{{ $repo }}</a></h1>
+          {{ $url := printf "https://github.com/%s" $repo }}{{ with index $report $repo }}{{ with .URL }}{{ $url = . }}{{ end }}{{ end }}
+          <h1 class="title"> Repo: <a href="{{ $url }}">{{ $repo }}</a></h1>
+          {{ with index $report $repo }}{{ with .Meta }}
+          {{ with .Description }}<p class="subtitle">{{ . }}</p>{{ end }}
+          <div class="tags">
+            <span class="tag">★ {{ .Stars }} stars</span>
+            <span class="tag">{{ .Forks }} forks</span>
+            <span class="tag" title="GitHub counts open pull requests as open issues">{{ .OpenIssues }} open issues</span>
+            {{ with .Language }}<span class="tag">{{ . }}</span>{{ end }}
+            {{ if .Archived }}<span class="tag is-warning">archived</span>{{ end }}
+          </div>
+          {{ end }}{{ end }}
+          {{ with index $report $repo }}{{ with .SecurityAlerts }}
+          <div class="tags has-addons" title="Open Dependabot alerts">
+            <span class="tag is-dark">Dependabot alerts</span>
+            <span class="tag{{ if .Critical }} is-danger{{ end }}">{{ .Critical }} critical</span>
+            <span class="tag{{ if .High }} is-warning{{ end }}">{{ .High }} high</span>
+            <span class="tag{{ if .Medium }} is-warning is-light{{ end }}">{{ .Medium }} medium</span>
+            <span class="tag{{ if .Low }} is-info is-light{{ end }}">{{ .Low }} low</span>
+          </div>
+          {{ end }}{{ end }}
+          {{ with index $report $repo }}{{ with .CommitStats }}
+          <p class="subtitle" title="{{ range $i, $c := .TopCommitters }}{{ if $i }}, {{ end }}{{ $c.Author.DisplayName }} ({{ $c.Commits }}){{ end }}">{{ if .Truncated }}At least {{ end }}{{ .Commits }} commits by {{ .Authors }} authors</p>
+          {{ end }}{{ end }}
+          {{ with index $report $repo }}{{ with .CIStats }}
+          <p class="subtitle" title="{{ range $i, $w := .Workflows }}{{ if $i }}, {{ end }}{{ $w.Name }} ({{ $w.Failures }} / {{ $w.Runs }}){{ end }}">CI: {{ .GreenPercent }}% green ({{ .Failures }} failures / {{ if .Truncated }}at least {{ end }}{{ .Runs }} runs)</p>
+          {{ end }}{{ end }}
+          {{ with index $errors $repo }}
+          <div class="notification is-danger">Failed to fetch activity: {{ . }}</div>
+          {{ end }}
+          {{ with index $report $repo }}{{ if .Truncated }}
+          <div class="notification is-warning">GitHub's search result limit was reached, some items are missing.</div>
+          {{ end }}{{ end }}
+          {{ with index $report $repo }}{{ with .NeedsReview }}
+          <div id="{{ $repo }}-needs-review" class="notification is-danger is-light">
+            <h3 class="subtitle">👀 {{ len . }} PRs waiting for a first review</h3>
+            <ul>
+              {{ range . }}
+              <li><a href={{ .URL }}>#{{ .Number }}</a> {{ .Title }} by <a href={{ .Author.ProfileURL }}>{{ .Author.DisplayName }}</a>, opened <span title="{{ .CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ .Age }}</span> ago</li>
+              {{ end }}
+            </ul>
+          </div>
+          {{ end }}{{ end }}
+          {{ with index $charts $repo }}
           <div class="block">
-            {{ if not (index $report $repo) }}
-              <h3 class="subtitle">No issues opened in the past {{ $days }} days</h3>
+            {{ . }}
+            <p class="help"><span class="has-text-success">■</span> issues <span class="has-text-info">■</span> PRs opened per day</p>
+          </div>
+          {{ end }}
+          {{ if or $closed $merged }}{{ with index $report $repo }}
+          <nav class="level box">
+            {{ if $closed }}
+            <div class="level-item has-text-centered">
+              <div>
+                <p class="heading">Time to close (median / p90)</p>
+                <p class="title is-5">{{ with .Stats.TimeToClose }}{{ if .Count }}{{ .MedianAge }} / {{ .P90Age }}{{ else }}-{{ end }}{{ end }}</p>
+              </div>
             </div>
-            <div class="block">
-              <h3 class="subtitle">No PRs opened in the past {{ $days }} days</h3>
+            {{ end }}
+            {{ if $merged }}
+            <div class="level-item has-text-centered">
+              <div>
+                <p class="heading">Time to merge (median / p90)</p>
+                <p class="title is-5">{{ with .Stats.TimeToMerge }}{{ if .Count }}{{ .MedianAge }} / {{ .P90Age }}{{ else }}-{{ end }}{{ end }}</p>
+              </div>
             </div>
             {{ end }}
+          </nav>
+          {{ end }}{{ end }}
+          {{ with index $report $repo }}{{ with .NewContributors }}
+          <div class="notification is-success is-light">
+            <h3 class="subtitle">🎉 New contributors</h3>
+            <ul>
+              {{ range . }}
+              <li><a href={{ .Author.ProfileURL }}>{{ .Author.DisplayName }}</a> opened <a href={{ .URL }}>#{{ .Number }}</a> {{ .Title }}</li>
+              {{ end }}
+            </ul>
+          </div>
+          {{ end }}{{ end }}
+          <div class="block">
             {{ range $r, $activity := $report }}
               {{ if eq $repo $r }}
               {{if not $activity.Issues}}
-              <h3 class="subtitle">No issues opened in the past {{ $days }} days</h3>
+              <h3 class="subtitle">No issues {{ $verb }}{{ $stateNote }} {{ $period }} {{ (index $repoDeltas $r).Issues }}</h3>
               {{ else }}
               {{ $issueCount := len $activity.Issues }}
-              <h3 class="subtitle">{{ $issueCount }} new issues opened in the past {{ $days }} days</h3>
+              <h3 class="subtitle">{{ $issueCount }} {{ if not $updated }}new {{ end }}issues {{ $verb }}{{ $stateNote }} {{ $period }} {{ (index $repoDeltas $r).Issues }}</h3>
+              {{ with $activity.IssuesLimit }}<p class="help">Showing the first {{ .Shown }} of {{ if .AtLeast }}at least {{ end }}{{ .Total }} issues</p>{{ end }}
               <div id="{{ $r }}-issues" class="block">
                 <table class="table is-hoverable">
                   <thead>
                     <tr>
-                      <th>#</th>
+                      <th><a href="?{{ $filters }}&sort=number">#</a></th>
                       <th>Status</th>
-                      <th>Age</th>
+                      <th><a href="?{{ $filters }}&sort=created">Age</a></th>
                       <th>Author</th>
                       <th>Title</th>
+                      <th>Labels</th>
+                      <th>Assignees</th>
+                      <th>Milestone</th>
+                      <th><a href="?{{ $filters }}&sort=comments">Comments</a></th>
+                      <th><a href="?{{ $filters }}&sort=reactions">Reactions</a></th>
+                      {{ if $project }}<th>Project</th>{{ end }}
+                      {{ if $involves }}<th>Involvement</th>{{ end }}
                     </tr>
                   </thead>
                   {{ range  $i := $activity.Issues }}
                     <tbody>
-                      <tr>
+                      <tr{{ if $i.ResponseOverdue }} class="is-overdue" title="No response within the SLA"{{ end }}>
                         <td><a href={{ $i.URL }}>{{ $i.Number }}</a></td>
                         <td>
-                          {{ if eq ($i.Status | deref) "open" }}
+                          {{ if eq ($i.Status) "open" }}
                             <span class="tag is-success">
-                          {{ else if eq ($i.Status | deref) "closed" }}
+                          {{ else if eq ($i.StateReason) "not_planned" }}
+                            <span class="tag is-not-planned">
+                          {{ else if eq ($i.Status) "closed" }}
                             <span class="tag is-danger">
+                          {{ else if eq ($i.Status) "merged" }}
+                            <span class="tag is-merged">
                           {{ else }}
                             <span class="tag">
                           {{ end }}
-                          {{ $i.Status }}
+                          {{ $i.Status }}{{ with $i.StateReason }}{{ if eq . "not_planned" }} as not planned{{ else if eq . "completed" }} as completed{{ else if eq . "reopened" }}, reopened{{ end }}{{ end }}
                           </span>
                         </td>
-                        <td>{{ $i.Age }}</td>
-                        <td><a href={{ $i.Author.ProfileURL }}>{{ $i.Author.DisplayName }}</a></td>
-                        <td><a href={{ $i.URL }}>{{ $i.Title }}</a></td>
+                        <td title="Opened {{ $i.CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ $i.Age }}</td>
+                        <td><a href={{ $i.Author.ProfileURL }}>{{ $i.Author.DisplayName }}</a>{{ with $i.AuthorAssociation }} <span class="tag is-light">{{ . }}</span>{{ end }}{{ if $i.FirstTime }} <span class="tag is-success is-light" title="First contribution">🎉</span>{{ end }}</td>
+                        <td>
+                          <a href={{ $i.URL }}>{{ $i.Title }}</a>
+                          {{ if and $updated $i.New }}<span class="tag is-primary is-light">new</span>{{ end }}
+                          {{ if $i.HasLinkedPR }}<span class="tag is-info is-light">fix in flight</span>{{ end }}
+                          {{ with $i.BodyExcerpt }}<details><summary>Description</summary>{{ . }}</details>{{ end }}
+                        </td>
+                        <td>{{ range $i.Labels }}<span class="tag" style="background-color: #{{ .Color }}">{{ .Name }}</span> {{ end }}</td>
+                        <td>{{ range $i.Assignees }}<a href={{ .ProfileURL }}>{{ .DisplayName }}</a> {{ end }}</td>
+                        <td>{{ with $i.Milestone }}<a href="{{ .URL }}"{{ if not .DueOn.IsZero }} title="Due {{ .DueOn.Format "2006-01-02" }}"{{ end }}>{{ .Title }}</a>{{ else }}-{{ end }}</td>
+                        <td>{{ $i.Comments }}</td>
+                        <td title="{{ $i.Reactions.TotalCount }} reactions">👍 {{ $i.Reactions.PlusOne }}</td>
+                        {{ if $project }}<td>{{ or $i.ProjectStatus "—" }}</td>{{ end }}
+                        {{ if $involves }}<td>{{ range $n, $v := $i.Involvement }}{{ if $n }}, {{ end }}{{ $v }}{{ else }}—{{ end }}</td>{{ end }}
                       </tr>
                     </tbody>
                   {{ end }}
                 </table>
               </div>
+              {{ with $activity.TriageGaps }}
+              <div id="{{ $r }}-triage" class="notification is-danger is-light">
+                <h4 class="subtitle is-6">{{ len . }} open issues still need triage</h4>
+                <table class="table is-narrow">
+                  <thead>
+                    <tr>
+                      <th>#</th>
+                      <th>Age</th>
+                      <th>Title</th>
+                      <th>Missing</th>
+                    </tr>
+                  </thead>
+                  <tbody>
+                  {{ range $g := . }}
+                    <tr>
+                      <td><a href={{ $g.URL }}>{{ $g.Number }}</a></td>
+                      <td>{{ $g.Age }}</td>
+                      <td><a href={{ $g.URL }}>{{ $g.Title }}</a></td>
+                      <td>
+                        {{ if $g.Unlabeled }}<span class="tag is-danger">label</span>{{ end }}
+                        {{ if $g.Unassigned }}<span class="tag is-danger">assignee</span>{{ end }}
+                      </td>
+                    </tr>
+                  {{ end }}
+                  </tbody>
+                </table>
+              </div>
+              {{ end }}
             {{ end }}
-            </div>
             {{ end }}
           {{ end }}
+          </div>
 
           <div class="block">
           {{ range $r, $activity := $report }}
             {{ if eq $repo $r }}
             {{if not $activity.PullRequests}}
-            <h3 class="subtitle">No PRs opened in the past {{ $days }} days</h3>
-            <div class="block">
+            <h3 class="subtitle">No PRs {{ $verb }}{{ $stateNote }} {{ $period }} {{ (index $repoDeltas $r).PullRequests }}</h3>
             {{ else }}
             {{ $issueCount := len $activity.PullRequests }}
-            <h3 class="subtitle">{{ $issueCount }} new PRs opened in the past {{ $days }} days</h3>
-            <div class="block">
+            <h3 class="subtitle">{{ $issueCount }} {{ if not $updated }}new {{ end }}PRs {{ $verb }}{{ $stateNote }} {{ $period }} {{ (index $repoDeltas $r).PullRequests }}</h3>
+            {{ with $activity.PullRequestsLimit }}<p class="help">Showing the first {{ .Shown }} of {{ if .AtLeast }}at least {{ end }}{{ .Total }} PRs</p>{{ end }}
             <div id="{{ $r }}-prs" class="block">
               <table class="table is-hoverable">
                 <thead>
                   <tr>
-                    <th>#</th>
+                    <th><a href="?{{ $filters }}&sort=number">#</a></th>
                     <th>Status</th>
-                    <th>Age</th>
+                    <th><a href="?{{ $filters }}&sort=created">Age</a></th>
                     <th>Author</th>
                     <th>Title</th>
+                    <th>Labels</th>
+                    <th>Assignees</th>
+                    <th>Milestone</th>
+                    <th><a href="?{{ $filters }}&sort=comments">Comments</a></th>
+                    <th><a href="?{{ $filters }}&sort=reactions">Reactions</a></th>
+                    <th>Base</th>
+                    <th>Size</th>
+                    <th>CI</th>
+                    <th>Review</th>
+                    <th>Reviewers</th>
+                    {{ if $project }}<th>Project</th>{{ end }}
+                    {{ if $involves }}<th>Involvement</th>{{ end }}
                   </tr>
                 </thead>
                 {{ range  $pr := $activity.PullRequests }}
                   <tbody>
-                    <tr>
+                    <tr{{ if and $prDetails (not $pr.Draft) (not $pr.RequestedReviewers) }} class="is-unrouted" title="No reviewers requested"{{ end }}>
                       <td><a href={{ $pr.URL }}>{{ $pr.Number }}</a></td>
                       <td>
-                        {{ if eq ($pr.Status | deref) "open" }}
+                        {{ if eq ($pr.Status) "open" }}
                           <span class="tag is-success">
-                        {{ else if eq ($pr.Status | deref) "closed" }}
+                        {{ else if eq ($pr.Status) "closed" }}
                           <span class="tag is-danger">
+                        {{ else if eq ($pr.Status) "merged" }}
+                          <span class="tag is-merged">
                         {{ else }}
                           <span class="tag">
                         {{ end }}
                         {{ $pr.Status }}
                         </span>
                       </td>
-                      <td>{{ $pr.Age }}</td>
-                      <td><a href={{ $pr.Author.ProfileURL }}>{{ $pr.Author.DisplayName }}</a></td>
-                      <td><a href={{ $pr.URL }}>{{ $pr.Title }}</a></td>
+                      <td title="Opened {{ $pr.CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ $pr.Age }}</td>
+                      <td><a href={{ $pr.Author.ProfileURL }}>{{ $pr.Author.DisplayName }}</a>{{ with $pr.AuthorAssociation }} <span class="tag is-light">{{ . }}</span>{{ end }}{{ if $pr.FirstTime }} <span class="tag is-success is-light" title="First contribution">🎉</span>{{ end }}</td>
+                      <td>
+                        <a href={{ $pr.URL }}>{{ $pr.Title }}</a>
+                        {{ if and $updated $pr.New }}<span class="tag is-primary is-light">new</span>{{ end }}
+                        {{ range $pr.ClosesIssues }}<a class="tag is-info is-light" href="{{ .URL }}">fixes {{ if ne .Repo $pr.Repo }}{{ .Repo }}{{ end }}#{{ .Number }}</a> {{ end }}
+                        {{ with $pr.BodyExcerpt }}<details><summary>Description</summary>{{ . }}</details>{{ end }}
+                      </td>
+                      <td>{{ range $pr.Labels }}<span class="tag" style="background-color: #{{ .Color }}">{{ .Name }}</span> {{ end }}</td>
+                      <td>{{ range $pr.Assignees }}<a href={{ .ProfileURL }}>{{ .DisplayName }}</a> {{ end }}</td>
+                      <td>{{ with $pr.Milestone }}<a href="{{ .URL }}"{{ if not .DueOn.IsZero }} title="Due {{ .DueOn.Format "2006-01-02" }}"{{ end }}>{{ .Title }}</a>{{ else }}-{{ end }}</td>
+                      <td>{{ $pr.Comments }}</td>
+                      <td title="{{ $pr.Reactions.TotalCount }} reactions">👍 {{ $pr.Reactions.PlusOne }}</td>
+                      <td>{{ $pr.BaseRef }}</td>
+                      <td>{{ with $pr.Size }}+{{ .Additions }} −{{ .Deletions }} <span class="tag is-size-{{ .Bucket }}">{{ .Bucket }}</span>{{ end }}</td>
+                      <td title="Checks: {{ $pr.ChecksStatus }}">
+                        {{ if eq $pr.ChecksStatus "success" }}<span class="has-text-success">✓</span>
+                        {{ else if eq $pr.ChecksStatus "failure" }}<span class="has-text-danger">✗</span>
+                        {{ else if eq $pr.ChecksStatus "pending" }}<span class="has-text-warning">●</span>
+                        {{ end }}
+                      </td>
+                      <td>{{ with $pr.ReviewStatus }}<span class="tag is-review-{{ . }}">{{ . }}</span>{{ end }}</td>
+                      <td>{{ range $pr.RequestedReviewers }}<a href={{ .ProfileURL }}>{{ .DisplayName }}</a> {{ end }}</td>
+                      {{ if $project }}<td>{{ or $pr.ProjectStatus "—" }}</td>{{ end }}
+                      {{ if $involves }}<td>{{ range $n, $v := $pr.Involvement }}{{ if $n }}, {{ end }}{{ $v }}{{ else }}—{{ end }}</td>{{ end }}
                     </tr>
                   </tbody>
                 {{ end }}
               </table>
             </div>
             {{ end }}
+            {{ end }}
+          {{ end }}
+          </div>
+
+          {{ if $closed }}
+          <div class="block">
+          {{ range $r, $activity := $report }}
+            {{ if eq $repo $r }}
+            {{ if not $activity.ClosedIssues }}
+            <h3 class="subtitle">No issues closed {{ $period }}</h3>
+            {{ else }}
+            <h3 class="subtitle">{{ len $activity.ClosedIssues }} issues closed {{ $period }}</h3>
+            <div id="{{ $r }}-closed" class="block">
+              <table class="table is-hoverable">
+                <thead>
+                  <tr>
+                    <th>#</th>
+                    <th>Author</th>
+                    <th>Title</th>
+                    <th>Open for</th>
+                    <th>Closed</th>
+                  </tr>
+                </thead>
+                <tbody>
+                {{ range $i := $activity.ClosedIssues }}
+                  <tr>
+                    <td><a href={{ $i.URL }}>{{ $i.Number }}</a></td>
+                    <td><a href={{ $i.Author.ProfileURL }}>{{ $i.Author.DisplayName }}</a></td>
+                    <td><a href={{ $i.URL }}>{{ $i.Title }}</a></td>
+                    <td title="Opened {{ $i.CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ $i.Age }}</td>
+                    <td>{{ $i.ClosedAt.Format "2006-01-02" }}{{ if eq $i.StateReason "not_planned" }} <span class="tag is-not-planned">not planned</span>{{ end }}</td>
+                  </tr>
+                {{ end }}
+                </tbody>
+              </table>
+            </div>
+            {{ end }}
+            {{ end }}
+          {{ end }}
+          </div>
+          {{ end }}
+
+          {{ if $merged }}
+          <div class="block">
+          {{ range $r, $activity := $report }}
+            {{ if eq $repo $r }}
+            {{ if not $activity.MergedPullRequests }}
+            <h3 class="subtitle">No PRs merged {{ $period }}</h3>
+            {{ else }}
+            <h3 class="subtitle">{{ len $activity.MergedPullRequests }} PRs merged {{ $period }}</h3>
+            <div id="{{ $r }}-merged" class="block">
+              <table class="table is-hoverable">
+                <thead>
+                  <tr>
+                    <th>#</th>
+                    <th>Author</th>
+                    <th>Title</th>
+                    <th>Open for</th>
+                    <th>Merged</th>
+                    <th>Merged by</th>
+                  </tr>
+                </thead>
+                <tbody>
+                {{ range $pr := $activity.MergedPullRequests }}
+                  <tr>
+                    <td><a href={{ $pr.URL }}>{{ $pr.Number }}</a></td>
+                    <td><a href={{ $pr.Author.ProfileURL }}>{{ $pr.Author.DisplayName }}</a></td>
+                    <td><a href={{ $pr.URL }}>{{ $pr.Title }}</a></td>
+                    <td title="Opened {{ $pr.CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ $pr.Age }}</td>
+                    <td>{{ $pr.MergedAt.Format "2006-01-02" }}</td>
+                    <td>{{ with $pr.MergedBy }}<a href={{ .ProfileURL }}>{{ .DisplayName }}</a>{{ else }}-{{ end }}</td>
+                  </tr>
+                {{ end }}
+                </tbody>
+              </table>
+            </div>
+            {{ end }}
+            {{ end }}
+          {{ end }}
+          </div>
+          {{ end }}
+
+          {{ if $discussions }}
+          <div class="block">
+          {{ with index $report $repo }}
+            {{ if not .Discussions }}
+            <h3 class="subtitle">No new discussions {{ $period }}</h3>
+            {{ else }}
+            <h3 class="subtitle">{{ len .Discussions }} new discussions {{ $period }}</h3>
+            <div id="{{ $repo }}-discussions" class="block">
+              <table class="table is-hoverable">
+                <thead>
+                  <tr>
+                    <th>#</th>
+                    <th>Author</th>
+                    <th>Title</th>
+                    <th>Category</th>
+                    <th>Age</th>
+                    <th>Comments</th>
+                  </tr>
+                </thead>
+                <tbody>
+                {{ range $d := .Discussions }}
+                  <tr>
+                    <td><a href={{ $d.URL }}>{{ $d.Number }}</a></td>
+                    <td><a href={{ $d.Author.ProfileURL }}>{{ $d.Author.DisplayName }}</a></td>
+                    <td><a href={{ $d.URL }}>{{ $d.Title }}</a></td>
+                    <td>{{ range $d.Labels }}<span class="tag">{{ .Name }}</span>{{ end }}</td>
+                    <td title="Opened {{ $d.CreatedAt.Format "2006-01-02 15:04 MST" }}">{{ $d.Age }}</td>
+                    <td>{{ $d.Comments }}</td>
+                  </tr>
+                {{ end }}
+                </tbody>
+              </table>
             </div>
             {{ end }}
           {{ end }}
+          </div>
+          {{ end }}
+
+          {{ with index $report $repo }}{{ with .Releases }}
+          <div class="block">
+            <h3 class="subtitle">{{ len . }} releases published {{ $period }}</h3>
+            <div id="{{ $repo }}-releases" class="block">
+              <table class="table is-hoverable">
+                <thead>
+                  <tr>
+                    <th>Tag</th>
+                    <th>Name</th>
+                    <th>Published</th>
+                    <th>Author</th>
+                  </tr>
+                </thead>
+                <tbody>
+                {{ range $rel := . }}
+                  <tr>
+                    <td><a href={{ $rel.URL }}>{{ $rel.TagName }}</a>{{ if $rel.Prerelease }} <span class="tag is-warning">pre-release</span>{{ end }}</td>
+                    <td>{{ if $rel.TagOnly }}<span class="tag">tag only</span>{{ else }}<a href={{ $rel.URL }}>{{ $rel.Name }}</a>{{ end }}</td>
+                    <td>{{ $rel.PublishedAt.Format "2006-01-02" }}</td>
+                    <td><a href={{ $rel.Author.ProfileURL }}>{{ $rel.Author.DisplayName }}</a></td>
+                  </tr>
+                {{ end }}
+                </tbody>
+              </table>
+            </div>
+          </div>
+          {{ end }}{{ end }}
+
+          {{ if $approvedUnmerged }}
+          <div class="block">
+          {{ with index $report $repo }}
+            {{ if not .ApprovedUnmerged }}
+            <h3 class="subtitle">No approved PRs waiting to merge</h3>
+            {{ else }}
+            <details id="{{ $repo }}-approved" class="notification is-info is-light">
+              <summary class="subtitle">{{ len .ApprovedUnmerged }} approved PRs waiting to merge</summary>
+              <table class="table is-hoverable">
+                <thead>
+                  <tr>
+                    <th>#</th>
+                    <th>Author</th>
+                    <th>Title</th>
+                    <th>Approved for</th>
+                    <th>Assignees</th>
+                  </tr>
+                </thead>
+                <tbody>
+                {{ range $pr := .ApprovedUnmerged }}
+                  <tr>
+                    <td><a href={{ $pr.URL }}>{{ $pr.Number }}</a></td>
+                    <td><a href={{ $pr.Author.ProfileURL }}>{{ $pr.Author.DisplayName }}</a></td>
+                    <td><a href={{ $pr.URL }}>{{ $pr.Title }}</a></td>
+                    <td{{ with $pr.ApprovedAt }} title="Approved {{ .Format "2006-01-02 15:04 MST" }}"{{ end }}>{{ $pr.Age }}</td>
+                    <td>{{ range $pr.Assignees }}<a href={{ .ProfileURL }}>{{ .DisplayName }}</a> {{ else }}-{{ end }}</td>
+                  </tr>
+                {{ end }}
+                </tbody>
+              </table>
+            </details>
+            {{ end }}
+          {{ end }}
+          </div>
+          {{ end }}
+
+          {{ if $staleDays }}
+          <div class="block">
+          {{ range $r, $activity := $report }}
+            {{ if eq $repo $r }}
+            {{ if not $activity.Stale }}
+            <h3 class="subtitle">No stale issues or PRs</h3>
+            {{ else }}
+            <details id="{{ $r }}-stale" class="notification is-warning is-light">
+              <summary class="subtitle">{{ len $activity.Stale }} stale issues and PRs not updated in {{ $staleDays }} days</summary>
+              <table class="table is-hoverable">
+                <thead>
+                  <tr>
+                    <th>#</th>
+                    <th>Status</th>
+                    <th>Author</th>
+                    <th>Title</th>
+                    <th>Idle for</th>
+                    <th>Assignees</th>
+                  </tr>
+                </thead>
+                <tbody>
+                {{ range $i := $activity.Stale }}
+                  <tr>
+                    <td><a href={{ $i.URL }}>{{ $i.Number }}</a></td>
+                    <td>{{ if $i.Draft }}<span class="tag">draft</span>{{ else }}<span class="tag is-success">{{ $i.Status }}</span>{{ end }}</td>
+                    <td><a href={{ $i.Author.ProfileURL }}>{{ $i.Author.DisplayName }}</a></td>
+                    <td><a href={{ $i.URL }}>{{ $i.Title }}</a></td>
+                    <td title="Last updated {{ $i.UpdatedAt.Format "2006-01-02 15:04 MST" }}">{{ $i.Age }}</td>
+                    <td>{{ range $i.Assignees }}<a href={{ .ProfileURL }}>{{ .DisplayName }}</a> {{ else }}-{{ end }}</td>
+                  </tr>
+                {{ end }}
+                </tbody>
+              </table>
+            </details>
+            {{ end }}
+            {{ end }}
+          {{ end }}
+          </div>
+          {{ end }}
         </div>
       </section>
       {{ end }}
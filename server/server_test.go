@@ -0,0 +1,72 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestReportKeepsExhaustedTokenParked(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	// Token "a" uses up its search limit with its first request.
+	var (
+		mu   sync.Mutex
+		auth []string
+	)
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Reset", reset)
+		if r.Header.Get("Authorization") == "token a" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+		} else {
+			w.Header().Set("X-RateLimit-Remaining", "29")
+		}
+		w.Write([]byte(`{"total_count": 0, "items": []}`))
+	}))
+	defer github.Close()
+
+	logger := log.New()
+	logger.Out = io.Discard
+	srv, err := NewServer(Options{
+		Log:                     logger,
+		Repos:                   []string{"acme/core"},
+		APIEndpoint:             github.URL + "/",
+		Token:                   "a",
+		Tokens:                  []string{"b"},
+		CacheTTL:                -1,
+		SearchRequestsPerMinute: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		srv.Report(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("report %d: status %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+
+	// Every report after the first skips token "a" until its limit resets.
+	want := []string{"token a", "token b", "token b"}
+	if len(auth) != len(want) {
+		t.Fatalf("Authorization headers = %q, want %q", auth, want)
+	}
+	for i := range auth {
+		if auth[i] != want[i] {
+			t.Errorf("report %d used %q, want %q", i+1, auth[i], want[i])
+		}
+	}
+}